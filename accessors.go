@@ -0,0 +1,152 @@
+package caller
+
+import "strings"
+
+// The functions below mirror the Caller accessors, but tolerate a nil
+// Caller, including a non-nil interface holding a nil pointer, and
+// return zero values for it. They spare code that receives a Caller
+// from elsewhere a defensive nil check before every access:
+//
+//	log.Printf("failed at %s", caller.Location(err.Caller))
+
+// Valid reports whether c is non-nil and usable.
+func Valid(c Caller) bool {
+	return !isNil(c) && c.Valid()
+}
+
+// File returns the file name of c, or an empty string if c is nil.
+func File(c Caller) string {
+	if isNil(c) {
+		return ""
+	}
+	return c.File()
+}
+
+// Line returns the line number of c, or 0 if c is nil.
+func Line(c Caller) int {
+	if isNil(c) {
+		return 0
+	}
+	return c.Line()
+}
+
+// Location returns the file:line of c, or an empty string if c is nil.
+func Location(c Caller) string {
+	if isNil(c) {
+		return ""
+	}
+	return c.Location()
+}
+
+// ShortLocation returns the filename:line of c, or an empty string if
+// c is nil.
+func ShortLocation(c Caller) string {
+	if isNil(c) {
+		return ""
+	}
+	return c.ShortLocation()
+}
+
+// Function returns the function or method name of c without package
+// prefix, or an empty string if c is nil.
+func Function(c Caller) string {
+	if isNil(c) {
+		return ""
+	}
+	return c.Function()
+}
+
+// FullFunction returns the full function name of c including package,
+// or an empty string if c is nil.
+func FullFunction(c Caller) string {
+	if isNil(c) {
+		return ""
+	}
+	return c.FullFunction()
+}
+
+// QualifiedFunction returns the function or method name of c qualified
+// by the name of its package rather than its import path, such as
+// "app.(*Server).Run" for "example.com/app.(*Server).Run", or an empty
+// string if c is nil. It is the form most log lines want, between
+// Function and FullFunction.
+func QualifiedFunction(c Caller) string {
+	if isNil(c) {
+		return ""
+	}
+	fn := c.Function()
+	if name := c.PackageName(); name != "" && fn != "" {
+		return name + "." + fn
+	}
+	return fn
+}
+
+// ShortFunction returns the function or method name of c without package
+// prefix, receiver decoration or closure suffixes, for compact displays,
+// or an empty string if c is nil:
+//
+//	example.com/app.(*Server).Run.func2.1  Server.Run
+//	example.com/app.Handler.ServeHTTP-fm   Handler.ServeHTTP
+func ShortFunction(c Caller) string {
+	if isNil(c) {
+		return ""
+	}
+	return shortFunction(c.Function())
+}
+
+// receiverReplacer strips the parentheses and pointer marker around the
+// receiver type in a method name.
+var receiverReplacer = strings.NewReplacer("(*", "", "(", "", ")", "")
+
+// shortFunction strips the receiver decoration and closure suffixes from
+// fn, a function name without package prefix.
+func shortFunction(fn string) string {
+	fn = strings.TrimSuffix(fn, "-fm")
+	for {
+		i := strings.LastIndexByte(fn, '.')
+		if i < 0 || !isClosureSuffix(fn[i+1:]) {
+			break
+		}
+		fn = fn[:i]
+	}
+	return receiverReplacer.Replace(fn)
+}
+
+// isClosureSuffix reports whether s is a name the compiler gives to a
+// function literal or wrapper, such as "func2", "1" or "gowrap1", or the
+// empty element of a package-level closure such as "glob..func1".
+func isClosureSuffix(s string) bool {
+	for _, prefix := range [...]string{"func", "gowrap", "deferwrap"} {
+		if rest, ok := strings.CutPrefix(s, prefix); ok && rest != "" {
+			s = rest
+			break
+		}
+	}
+	return strings.Trim(s, "0123456789") == ""
+}
+
+// Package returns the full import path of the package of c, or an empty
+// string if c is nil.
+func Package(c Caller) string {
+	if isNil(c) {
+		return ""
+	}
+	return c.Package()
+}
+
+// PackageName returns the name of the package of c without the
+// directory, or an empty string if c is nil.
+func PackageName(c Caller) string {
+	if isNil(c) {
+		return ""
+	}
+	return c.PackageName()
+}
+
+// String returns the string form of c, or an empty string if c is nil.
+func String(c Caller) string {
+	if isNil(c) {
+		return ""
+	}
+	return c.String()
+}
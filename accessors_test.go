@@ -0,0 +1,107 @@
+package caller
+
+import "testing"
+
+// TestAccessors tests that the package-level accessors match the Caller
+// methods and tolerate nil and typed-nil callers.
+func TestAccessors(t *testing.T) {
+	t.Parallel()
+
+	c := &callerInfo{file: "/src/app/main.go", line: 42, fn: "example.com/app.Run", dotIdx: 15}
+	var typedNil *mockCaller
+
+	tests := []struct {
+		name string
+		c    Caller
+		want []any
+	}{
+		{
+			name: "valid",
+			c:    c,
+			want: []any{true, c.File(), c.Line(), c.Location(), c.ShortLocation(), c.Function(), c.FullFunction(), c.Package(), c.PackageName(), c.String()},
+		},
+		{"nil", nil, []any{false, "", 0, "", "", "", "", "", "", ""}},
+		{"typed nil", typedNil, []any{false, "", 0, "", "", "", "", "", "", ""}},
+		{"nil callerInfo", (*callerInfo)(nil), []any{false, "", 0, "", "", "", "", "", "", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := []any{
+				Valid(tt.c), File(tt.c), Line(tt.c), Location(tt.c), ShortLocation(tt.c),
+				Function(tt.c), FullFunction(tt.c), Package(tt.c), PackageName(tt.c), String(tt.c),
+			}
+			names := []string{"Valid", "File", "Line", "Location", "ShortLocation", "Function", "FullFunction", "Package", "PackageName", "String"}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("%s() = %v, want %v", names[i], got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestShortFunction tests stripping receivers and closure suffixes from
+// function names.
+func TestShortFunction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		fn   string
+		want string
+	}{
+		{"example.com/app.Run", "Run"},
+		{"example.com/app.(*Server).Run", "Server.Run"},
+		{"example.com/app.Server.Run", "Server.Run"},
+		{"example.com/app.(*Server).Run.func2.1", "Server.Run"},
+		{"example.com/app.Run.func1", "Run"},
+		{"example.com/app.Run.gowrap1", "Run"},
+		{"example.com/app.Run.deferwrap2", "Run"},
+		{"example.com/app.Handler.ServeHTTP-fm", "Handler.ServeHTTP"},
+		{"example.com/app.(*List[...]).Push", "List[...].Push"},
+		{"example.com/app.glob..func1", "glob"},
+		{"example.com/app.function", "function"},
+		{"example.com/app.Run.funcs", "Run.funcs"},
+		{"main.main", "main"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fn, func(t *testing.T) {
+			t.Parallel()
+			c := &callerInfo{file: "/src/app/main.go", line: 1, fn: tt.fn, dotIdx: functionNameIndex(tt.fn)}
+			if got := ShortFunction(c); got != tt.want {
+				t.Errorf("ShortFunction() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := ShortFunction((*callerInfo)(nil)); got != "" {
+		t.Errorf("ShortFunction(typed nil) = %q, want empty", got)
+	}
+}
+
+// TestQualifiedFunction tests qualifying function names by package name.
+func TestQualifiedFunction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		fn   string
+		want string
+	}{
+		{"example.com/app.Run", "app.Run"},
+		{"example.com/app.(*Server).Run.func1", "app.(*Server).Run.func1"},
+		{"example.com/app/v2.Run", "v2.Run"},
+		{"main.main", "main.main"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fn, func(t *testing.T) {
+			t.Parallel()
+			c := &callerInfo{file: "/src/app/main.go", line: 1, fn: tt.fn, dotIdx: functionNameIndex(tt.fn)}
+			if got := QualifiedFunction(c); got != tt.want {
+				t.Errorf("QualifiedFunction() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := QualifiedFunction(nil); got != "" {
+		t.Errorf("QualifiedFunction(nil) = %q, want empty", got)
+	}
+}
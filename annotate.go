@@ -0,0 +1,69 @@
+package caller
+
+import "runtime"
+
+// AnnotatedError is an error wrapped with a message and the location it
+// was wrapped at, created by Annotate.
+type AnnotatedError struct {
+	Msg    string // Message added by Annotate
+	Caller Caller // Location of the call to Annotate; nil if unknown
+	Err    error  // Wrapped error
+}
+
+// Annotate wraps err with msg and the location of the call to Annotate,
+// as a lightweight alternative to capturing a full stack when wrapping an
+// error a level or two up:
+//
+//	if err := db.Ping(); err != nil {
+//		return caller.Annotate(err, "connect") // connect (store.go:42): dial tcp: ...
+//	}
+//
+// It returns nil if err is nil. The result is an *AnnotatedError that
+// unwraps to err.
+func Annotate(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return &AnnotatedError{Msg: msg, Caller: callSite(0), Err: err}
+}
+
+// Error implements the error interface, formatting the error as
+// "msg (file.go:42): original".
+func (e *AnnotatedError) Error() string {
+	msg := e.Msg
+	if loc := ShortLocation(e.Caller); loc != "" {
+		if msg != "" {
+			msg += " "
+		}
+		msg += "(" + loc + ")"
+	}
+	if e.Err == nil {
+		return msg
+	}
+	if msg == "" {
+		return e.Err.Error()
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *AnnotatedError) Unwrap() error {
+	return e.Err
+}
+
+// callSite returns the location of the call to the function calling
+// callSite, skip frames further up: with 0, it is the line that called
+// that function. It feeds the Recorder, under ErrorLabel, and capture
+// hooks like New, and returns nil if the location cannot be determined.
+func callSite(skip int) Caller {
+	// Skip callSite and the function calling it
+	pc, file, line, ok := runtime.Caller(skip + 2)
+	if !ok {
+		return nil
+	}
+	var fullFunc string
+	if f := runtime.FuncForPC(pc); f != nil {
+		fullFunc = f.Name()
+	}
+	return captured(newCallerInfo(file, line, fullFunc), ErrorLabel)
+}
@@ -0,0 +1,52 @@
+package caller
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+// TestAnnotate tests the message, location and unwrapping of annotated
+// errors.
+func TestAnnotate(t *testing.T) {
+	t.Parallel()
+
+	err := Annotate(io.EOF, "read header")
+	_, _, line, _ := runtime.Caller(0)
+
+	var ae *AnnotatedError
+	if !errors.As(err, &ae) || !errors.Is(err, io.EOF) {
+		t.Fatalf("Annotate() = %v, want an *AnnotatedError wrapping io.EOF", err)
+	}
+	if ae.Caller.Function() != "TestAnnotate" || ae.Caller.Line() != line-1 {
+		t.Errorf("Caller = %v, want TestAnnotate line %d", ae.Caller, line-1)
+	}
+	if got, want := err.Error(), "read header (annotate_test.go:"+strconv.Itoa(line-1)+"): EOF"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if Annotate(nil, "msg") != nil {
+		t.Error("Annotate(nil) should return nil")
+	}
+
+	c := &callerInfo{file: "/src/a.go", line: 3}
+	tests := []struct {
+		name string
+		err  *AnnotatedError
+		want string
+	}{
+		{"no message", &AnnotatedError{Caller: c, Err: io.EOF}, "(a.go:3): EOF"},
+		{"no caller", &AnnotatedError{Msg: "read", Err: io.EOF}, "read: EOF"},
+		{"no error", &AnnotatedError{Msg: "read", Caller: c}, "read (a.go:3)"},
+		{"error only", &AnnotatedError{Err: io.EOF}, "EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package caller
+
+import (
+	"math"
+	"sync"
+)
+
+// arenaChunkSize is the number of frames in each backing slice of a
+// FrameArena. Frames are never moved once stored, so growing the arena
+// does not copy the frames already in it.
+const arenaChunkSize = 4096
+
+// maxArenaFrames is the number of frames a FrameArena can hold between
+// resets, bounded by the size of FrameHandle.
+const maxArenaFrames = math.MaxUint32
+
+// FrameArena stores captured frames in large contiguous backing slices
+// and hands out FrameHandle values in place of individually allocated
+// callers, for error-heavy services in which millions of small caller
+// allocations show up in garbage collection profiles. Frames are freed
+// all at once with Reset, for example at the end of each batch or
+// request, rather than one by one.
+//
+//	var arena caller.FrameArena
+//	h, _ := arena.Capture(0)
+//	...
+//	if info, ok := arena.Info(h); ok {
+//		fmt.Println(info.ShortLocation())
+//	}
+//	arena.Reset()
+//
+// The zero FrameArena is empty and ready to use. It is safe for
+// concurrent use.
+type FrameArena struct {
+	mu     sync.Mutex
+	chunks [][]callerInfo
+	n      int    // Number of frames stored
+	gen    uint32 // Incremented by Reset to invalidate outstanding handles
+}
+
+// FrameHandle refers to a frame stored in a FrameArena. It is a small
+// value, free to copy and compare, that stays valid until the arena is
+// reset. The zero FrameHandle is invalid.
+type FrameHandle struct {
+	ref uint32 // Index of the frame plus one, or 0 for the zero handle
+	gen uint32 // Generation of the arena the frame was stored in
+}
+
+// Capture stores the caller in a, with the same skip semantics as New,
+// and returns its handle. It reports false if the skip is invalid, the
+// caller cannot be determined or the arena is full. Like the function
+// Capture, it does not allocate, except when a new backing slice is
+// needed or in the cases Capture describes.
+func (a *FrameArena) Capture(skip int) (FrameHandle, bool) {
+	if skip < 0 {
+		return FrameHandle{}, false
+	}
+	info, ok := Capture(skip + 1)
+	if !ok {
+		return FrameHandle{}, false
+	}
+	return a.store(info.c)
+}
+
+// Add stores a copy of c in a and returns its handle. It reports false
+// if c is nil or the arena is full.
+func (a *FrameArena) Add(c Caller) (FrameHandle, bool) {
+	if isNil(c) {
+		return FrameHandle{}, false
+	}
+	if ci, ok := c.(*callerInfo); ok {
+		return a.store(*ci)
+	}
+	fn := c.FullFunction()
+	return a.store(callerInfo{file: c.File(), line: c.Line(), fn: fn, dotIdx: functionNameIndex(fn)})
+}
+
+// Info returns the frame referred to by h as an Info, without
+// allocating. It reports false if h is the zero handle or was
+// invalidated by Reset. Handles are only meaningful to the arena that
+// returned them.
+func (a *FrameArena) Info(h FrameHandle) (Info, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if h.ref == 0 || h.gen != a.gen || int(h.ref) > a.n {
+		return Info{}, false
+	}
+	i := int(h.ref) - 1
+	return Info{c: a.chunks[i/arenaChunkSize][i%arenaChunkSize]}, true
+}
+
+// Caller returns the frame referred to by h as a Caller, or nil if h is
+// not valid for a, as for Info. Each call allocates.
+func (a *FrameArena) Caller(h FrameHandle) Caller {
+	info, ok := a.Info(h)
+	if !ok {
+		return nil
+	}
+	return info.Caller()
+}
+
+// Len returns the number of frames stored in a since it was last reset.
+func (a *FrameArena) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.n
+}
+
+// Reset frees every frame of a at once and invalidates all outstanding
+// handles. The first backing slice is kept for reuse; the others are
+// released to the garbage collector.
+func (a *FrameArena) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.chunks) > 0 {
+		clear(a.chunks[0])
+		clear(a.chunks[1:])
+		a.chunks = a.chunks[:1]
+	}
+	a.n = 0
+	a.gen++
+}
+
+// store appends c to a and returns its handle, or reports false if a
+// is full.
+func (a *FrameArena) store(c callerInfo) (FrameHandle, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	i := a.n
+	if i >= maxArenaFrames {
+		return FrameHandle{}, false
+	}
+	if i/arenaChunkSize == len(a.chunks) {
+		a.chunks = append(a.chunks, make([]callerInfo, arenaChunkSize))
+	}
+	a.chunks[i/arenaChunkSize][i%arenaChunkSize] = c
+	a.n++
+	return FrameHandle{ref: uint32(i + 1), gen: a.gen}, true //nolint:gosec // i is below maxArenaFrames
+}
@@ -0,0 +1,90 @@
+package caller
+
+import (
+	"runtime"
+	"testing"
+)
+
+// arenaHelper stores its caller in a, as New(0) would capture it.
+func arenaHelper(a *FrameArena) (FrameHandle, bool) {
+	return a.Capture(0)
+}
+
+// TestFrameArena tests storing, reading and bulk-freeing frames.
+func TestFrameArena(t *testing.T) {
+	t.Parallel()
+
+	var a FrameArena
+	h, ok := arenaHelper(&a)
+	_, file, line, _ := runtime.Caller(0)
+	if !ok {
+		t.Fatal("Capture(0) reported false")
+	}
+	info, ok := a.Info(h)
+	if !ok || info.File() != file || info.Line() != line-1 || info.Function() != "TestFrameArena" {
+		t.Errorf("Info() = %v, %v, want TestFrameArena at %s:%d", info, ok, file, line-1)
+	}
+	if got := a.Caller(h); got == nil || !got.Equal(info.Caller()) {
+		t.Errorf("Caller() = %v, want %v", got, info)
+	}
+
+	m, ok := a.Add(&mockCaller{file: "/src/x.go", line: 3, fn: "F", fullFn: "example.com/x.F"})
+	if got, _ := a.Info(m); !ok || got.Location() != "/src/x.go:3" || got.Package() != "example.com/x" {
+		t.Errorf("Add(mockCaller) stored %v, want /src/x.go:3 in example.com/x", got)
+	}
+	if _, ok := a.Add(nil); ok {
+		t.Error("Add(nil) reported true")
+	}
+	if _, ok := a.Capture(-1); ok {
+		t.Error("Capture(-1) reported true")
+	}
+
+	// Fill more than one backing slice
+	for range arenaChunkSize {
+		if _, ok := a.Add(info.Caller()); !ok {
+			t.Fatal("Add() reported false")
+		}
+	}
+	last, _ := a.Add(info.Caller())
+	if got, want := a.Len(), arenaChunkSize+3; got != want {
+		t.Errorf("Len() = %d, want %d", got, want)
+	}
+	if got, ok := a.Info(last); !ok || !got.Caller().Equal(info.Caller()) {
+		t.Errorf("Info() of the last frame = %v, %v, want %v", got, ok, info)
+	}
+
+	if got, want := a.SizeBytes(), 2*arenaChunkSize*callerInfoSize; got < want {
+		t.Errorf("SizeBytes() = %d, want at least %d", got, want)
+	}
+
+	a.Reset()
+	if a.Len() != 0 || len(a.chunks) != 1 {
+		t.Errorf("Reset() left %d frames in %d slices, want none in 1", a.Len(), len(a.chunks))
+	}
+	for name, h := range map[string]FrameHandle{"zero": {}, "before reset": h, "beyond the end": last} {
+		if _, ok := a.Info(h); ok {
+			t.Errorf("Info(%s handle) reported true", name)
+		}
+		if got := a.Caller(h); got != nil {
+			t.Errorf("Caller(%s handle) = %v, want nil", name, got)
+		}
+	}
+	if h, ok := a.Add(info.Caller()); !ok || h.ref != 1 {
+		t.Errorf("Add() after Reset() = %+v, %v, want the first slot", h, ok)
+	}
+}
+
+// TestFrameArena_Allocs tests that capturing into an arena with room
+// does not allocate.
+func TestFrameArena_Allocs(t *testing.T) {
+	var a FrameArena
+	a.Capture(0)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, ok := a.Capture(0); !ok {
+			t.Fatal("Capture(0) reported false")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("FrameArena.Capture(0) allocated %v times per run, want 0", allocs)
+	}
+}
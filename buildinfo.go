@@ -0,0 +1,48 @@
+package caller
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"testing"
+)
+
+// BuildInfo describes the toolchain, platform and main module of the
+// executable a Stack was captured in, so that a stored stack remains
+// self-describing when it is analyzed later.
+type BuildInfo struct {
+	GoVersion     string `json:"go_version,omitempty"`     // Toolchain version, as reported by runtime.Version
+	GOOS          string `json:"goos,omitempty"`           // Target operating system
+	GOARCH        string `json:"goarch,omitempty"`         // Target architecture
+	Module        string `json:"module,omitempty"`         // Main module path, if built in module mode
+	ModuleVersion string `json:"module_version,omitempty"` // Main module version, such as "v1.2.3" or "(devel)"
+	Test          bool   `json:"test,omitempty"`           // Whether the executable is a test binary built by go test
+}
+
+// currentBuild returns the BuildInfo of the running executable, which is
+// read once and shared by every captured Stack.
+var currentBuild = sync.OnceValue(func() *BuildInfo {
+	b := &BuildInfo{
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+		Test:      InTestBinary(),
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		b.Module = bi.Main.Path
+		b.ModuleVersion = bi.Main.Version
+	}
+	return b
+})
+
+// CurrentBuild returns the BuildInfo of the running executable.
+func CurrentBuild() BuildInfo {
+	return *currentBuild()
+}
+
+// InTestBinary reports whether the running executable is a test binary
+// built by go test, so that shared code can adjust caller formatting or
+// verbosity under tests. Stacks record it in their build metadata.
+func InTestBinary() bool {
+	return testing.Testing()
+}
@@ -0,0 +1,34 @@
+package caller
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestCurrentBuild tests that the build metadata describes the running
+// executable and is attached to captured stacks only.
+func TestCurrentBuild(t *testing.T) {
+	t.Parallel()
+
+	b := CurrentBuild()
+	if b.GoVersion != runtime.Version() || b.GOOS != runtime.GOOS || b.GOARCH != runtime.GOARCH {
+		t.Errorf("CurrentBuild() = %+v, want %s %s/%s", b, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	}
+
+	if !InTestBinary() || !b.Test {
+		t.Errorf("InTestBinary() = %v, CurrentBuild().Test = %v, want true under go test", InTestBinary(), b.Test)
+	}
+	if data := mustMarshal(t, stackHelper(0)); !strings.Contains(data, `"test":true`) {
+		t.Errorf("MarshalJSON() = %.300s, want the build tagged as a test", data)
+	}
+	if got, ok := stackHelper(0).Build(); !ok || got != b {
+		t.Errorf("NewStack(0).Build() = %+v, %v, want %+v", got, ok, b)
+	}
+	if _, ok := (&Stack{}).Build(); ok {
+		t.Error("Build() of an empty Stack reported true")
+	}
+	if _, ok := (*Stack)(nil).Build(); ok {
+		t.Error("Build() of a nil Stack reported true")
+	}
+}
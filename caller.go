@@ -77,10 +77,12 @@ type Caller interface {
 // callerInfo represents source information about the caller.
 // It implements the Caller interface.
 type callerInfo struct {
-	file   string // File name
-	line   int    // Line number
-	fn     string // Function name
-	dotIdx int    // Index of the function name dot separator within the full name
+	file   string       // File name
+	line   int          // Line number
+	fn     string       // Function name
+	dotIdx int32        // Index of the function name dot separator within the full name
+	flags  frameFlags   // Classification set by WithInAppRules and decorators
+	extra  *frameExtras // Optional capture data, or nil if there is none
 }
 
 // caller implements the Caller interface.
@@ -112,12 +114,7 @@ func New(skip int) Caller {
 		fullFunc = f.Name()
 	}
 
-	return &callerInfo{
-		file:   file,
-		line:   line,
-		fn:     fullFunc,
-		dotIdx: functionNameIndex(fullFunc),
-	}
+	return captured(newCallerInfo(file, line, fullFunc), "")
 }
 
 // NewEmpty returns a Caller with no information populated, suitable as a
@@ -157,12 +154,53 @@ func NewFromPC(pc uintptr) Caller {
 	// Get the full function name, file, and line
 	fullFunc := f.Name()
 	file, line := f.FileLine(pc)
-	return &callerInfo{
-		file:   file,
+	return captured(newCallerInfo(file, line, fullFunc), "")
+}
+
+// newCallerInfo builds a callerInfo from freshly captured runtime data.
+// Every constructor that captures a live frame goes through here, so
+// package-wide capture settings are applied in exactly one place.
+func newCallerInfo(file string, line int, fullFunc string) *callerInfo {
+	c := makeCallerInfo(file, line, fullFunc)
+	return &c
+}
+
+// makeCallerInfo is like newCallerInfo, but returns the callerInfo by
+// value for captures that must not allocate.
+func makeCallerInfo(file string, line int, fullFunc string) callerInfo {
+	c := callerInfo{
+		file:   mapFile(file),
 		line:   line,
 		fn:     fullFunc,
 		dotIdx: functionNameIndex(fullFunc),
 	}
+	c.setFlag(flagOwnsFile, c.file != file)
+	return c
+}
+
+// frameCallerInfo builds a callerInfo from a frame returned by
+// runtime.CallersFrames.
+func frameCallerInfo(frame runtime.Frame) *callerInfo {
+	return newCallerInfo(frame.File, frame.Line, frame.Function)
+}
+
+// maxStackDepth bounds the number of program counters captured in one
+// walk of the stack, so that runaway recursion cannot make a capture
+// arbitrarily expensive.
+const maxStackDepth = 1024
+
+// callers returns the program counters of the stack above its caller,
+// as runtime.Callers would with the same skip, growing its buffer until
+// the whole stack fits or maxStackDepth is reached.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, 32)
+	for {
+		n := runtime.Callers(skip+2, pcs)
+		if n < len(pcs) || len(pcs) >= maxStackDepth {
+			return pcs[:n]
+		}
+		pcs = make([]uintptr, min(len(pcs)*2, maxStackDepth))
+	}
 }
 
 // Valid returns true if the caller is usable.
@@ -203,26 +241,18 @@ func (c *callerInfo) Location() string {
 }
 
 // ShortLocation returns a formatted string with just filename:line.
+// Use ShortLocationDepth to keep more trailing path segments.
 func (c *callerInfo) ShortLocation() string {
-	if c == nil || c.file == "" {
+	if c == nil {
 		return ""
 	}
-	shortFile := filepath.Base(c.file)
-	if c.line <= 0 {
-		return shortFile
-	}
-
-	var sb strings.Builder
-	sb.WriteString(shortFile)
-	sb.WriteByte(':')
-	sb.WriteString(strconv.Itoa(c.Line()))
-	return sb.String()
+	return shortLocation(c.file, c.line, 1)
 }
 
 // Function returns just the function or method name
 // without package prefix.
 func (c *callerInfo) Function() string {
-	if c == nil || c.fn == "" || c.dotIdx < 0 || c.dotIdx >= len(c.fn)-1 {
+	if c == nil || c.fn == "" || c.dotIdx < 0 || int(c.dotIdx) >= len(c.fn)-1 {
 		return ""
 	}
 	return c.fn[c.dotIdx+1:]
@@ -290,19 +320,29 @@ func (c *callerInfo) Equal(other Caller) bool {
 
 // MarshalJSON implements the json.Marshaler interface.
 func (c *callerInfo) MarshalJSON() ([]byte, error) {
+	return c.marshalJSON(0)
+}
+
+// marshalJSON encodes c with schema version v, or without a version field
+// if v is 0.
+func (c *callerInfo) marshalJSON(v int) ([]byte, error) {
 	if c == nil {
 		return []byte("null"), nil
 	}
 	b, err := json.Marshal(struct {
+		V        int    `json:"v,omitempty"`
 		File     string `json:"file,omitempty"`
 		Line     int    `json:"line,omitempty"`
 		Function string `json:"function,omitempty"`
 		Package  string `json:"package,omitempty"`
+		InApp    bool   `json:"in_app,omitempty"`
 	}{
+		V:        v,
 		File:     c.file,
 		Line:     c.line,
 		Function: c.Function(),
 		Package:  c.Package(),
+		InApp:    c.has(flagInApp),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("JSON marshal: %w", err)
@@ -311,41 +351,56 @@ func (c *callerInfo) MarshalJSON() ([]byte, error) {
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
+// It accepts both plain and versioned payloads; see JSONSchemaVersion for
+// the decoding rules.
 func (c *callerInfo) UnmarshalJSON(data []byte) error {
 	var aux struct {
+		V        int    `json:"v"`
 		File     string `json:"file"`
 		Line     int    `json:"line"`
 		Function string `json:"function"`
 		Package  string `json:"package"`
+		InApp    bool   `json:"in_app"`
 	}
 
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return fmt.Errorf("JSON unmarshal: %w", err)
 	}
+	return c.setDecoded(aux.V, aux.File, aux.Line, aux.Function, aux.Package, aux.InApp)
+}
 
-	c.file = aux.File
+// setDecoded validates the fields of a decoded caller payload and stores
+// them in c.
+func (c *callerInfo) setDecoded(v int, file string, line int, function, pkg string, inApp bool) error {
+	if err := checkSchemaVersion(v); err != nil {
+		return err
+	}
+
+	c.file = file
+	c.setFlag(flagInApp, inApp)
+	c.flags |= flagOwnsStrings
 
 	// Validate and set line
-	if aux.Line < 0 {
-		return fmt.Errorf("invalid line number: %d", aux.Line)
+	if line < 0 {
+		return fmt.Errorf("invalid line number: %d", line)
 	}
-	c.line = aux.Line
+	c.line = line
 
 	// Early return if Function is empty
-	if aux.Function == "" {
+	if function == "" {
 		c.fn = ""
 		c.dotIdx = -1
 		return nil
 	}
 
 	// If package is empty, use only function name
-	if aux.Package == "" {
-		c.fn = aux.Function
+	if pkg == "" {
+		c.fn = function
 		c.dotIdx = -1
 		return nil
 	}
 
-	c.fn = aux.Package + "." + aux.Function
+	c.fn = pkg + "." + function
 	c.dotIdx = functionNameIndex(c.fn)
 	return nil
 }
@@ -354,6 +409,7 @@ func (c *callerInfo) UnmarshalJSON(data []byte) error {
 // It includes attributes such as the file name, line number, function name,
 // and package if they are available.
 // For an invalid or nil caller, it returns an empty slog.Value.
+// LogValueAs renders a single string instead.
 func (c *callerInfo) LogValue() slog.Value {
 	if !c.Valid() {
 		return slog.Value{}
@@ -372,6 +428,9 @@ func (c *callerInfo) LogValue() slog.Value {
 	if pkg := c.Package(); pkg != "" {
 		attrs = append(attrs, slog.String("package", pkg))
 	}
+	if c.has(flagInApp) {
+		attrs = append(attrs, slog.Bool("in_app", true))
+	}
 
 	return slog.GroupValue(attrs...)
 }
@@ -382,7 +441,7 @@ func (c *callerInfo) LogValue() slog.Value {
 // For example, if the function name is
 // "path/to/package.function", the result is
 // the index of the dot (e.g. 17 in this case).
-func functionNameIndex(name string) int {
+func functionNameIndex(name string) int32 {
 	if name == "" {
 		return -1
 	}
@@ -396,7 +455,7 @@ func functionNameIndex(name string) int {
 
 	// Find the first dot in the base name
 	if firstDot := strings.IndexByte(base, '.'); firstDot != -1 {
-		return lastSlash + firstDot
+		return int32(lastSlash + firstDot) //nolint:gosec // Function names are far shorter than 2 GiB
 	}
 
 	return -1
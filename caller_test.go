@@ -21,6 +21,21 @@ func nestedTestFunc(skip int) Caller {
 	return New(skip)
 }
 
+// global is a package-wide setting held in an atomic value.
+type global[T any] interface {
+	Load() T
+	Store(v T)
+}
+
+// restoreGlobal saves the current value of g and restores it once t and
+// its subtests complete, so that a test changing a package-wide setting
+// leaves it as it found it.
+func restoreGlobal[T any](t *testing.T, g global[T]) {
+	t.Helper()
+	old := g.Load()
+	t.Cleanup(func() { g.Store(old) })
+}
+
 // TestNew tests the New function and verifies that it correctly
 // captures the caller information from the specified stack frame.
 // It tests both immediate callers and callers at an arbitrary
@@ -766,7 +781,7 @@ func TestFunctionNameIndex(t *testing.T) {
 	tests := []struct {
 		name string
 		fn   string
-		want int
+		want int32
 	}{
 		{"empty", "", -1},
 		{"no package", "main", -1},
@@ -831,6 +846,16 @@ func BenchmarkCallerOverhead(b *testing.B) {
 		// Use the results to avoid optimization
 		globalCaller, globalFile, globalLine, globalFn = c, file, line, fn
 	})
+
+	b.Run("without function", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			c = NewWith(0, WithoutFunction())
+			file, line = c.File(), c.Line()
+		}
+		// Use the results to avoid optimization
+		globalCaller, globalFile, globalLine = c, file, line
+	})
 }
 
 // BenchmarkStringOperations benchmarks different approaches to building a string in the
@@ -0,0 +1,122 @@
+/*
+Package callergit attributes callers to the commits that last changed
+their lines, by running git blame, so that error dashboards can show who
+last touched a failing call site.
+
+It is a package of its own so that programs importing package caller do
+not link os/exec unless they use it.
+*/
+package callergit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	caller "github.com/balinomad/go-caller/v2"
+)
+
+// ErrNoBlame is returned by Blame when a caller cannot be attributed to a
+// line of the repository.
+var ErrNoBlame = errors.New("no blame information")
+
+// BlameInfo describes the commit that last changed a line, as reported by
+// git blame.
+type BlameInfo struct {
+	Commit     string    // Full commit hash; all zeros if the line is not committed yet
+	Author     string    // Author name
+	AuthorMail string    // Author email address, without angle brackets
+	AuthorTime time.Time // Author date of the commit
+}
+
+// Committed reports whether the line is part of a commit, rather than an
+// uncommitted change in the working tree.
+func (b BlameInfo) Committed() bool {
+	return strings.Trim(b.Commit, "0") != ""
+}
+
+// Age returns the time elapsed since the line was last changed.
+func (b BlameInfo) Age() time.Duration {
+	return time.Since(b.AuthorTime)
+}
+
+// Blame runs git blame in the repository at repoRoot for the file and
+// line of c and reports who last changed that line, so error dashboards
+// can show who last touched a failing call site:
+//
+//	b, err := callergit.Blame(ctx, "/src/app", c)
+//	if err == nil {
+//		fmt.Printf("%s, %s ago (%.8s)\n", b.Author, b.Age().Round(time.Hour), b.Commit)
+//	}
+//
+// An absolute file path must lie within repoRoot; a relative one, such as
+// a path rewritten by a FileMapper, is taken relative to it. It requires
+// the git executable and returns an error wrapping ErrNoBlame if c has no
+// file or line, or its file lies outside the repository.
+func Blame(ctx context.Context, repoRoot string, c caller.Caller) (BlameInfo, error) {
+	file, line := caller.File(c), caller.Line(c)
+	if file == "" || line <= 0 {
+		return BlameInfo{}, fmt.Errorf("%w: caller has no file and line", ErrNoBlame)
+	}
+	if filepath.IsAbs(file) {
+		rel, err := filepath.Rel(repoRoot, file)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return BlameInfo{}, fmt.Errorf("%w: %s is outside %s", ErrNoBlame, file, repoRoot)
+		}
+		file = rel
+	}
+
+	lines := strconv.Itoa(line) + "," + strconv.Itoa(line)
+	cmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "blame", "--porcelain", "-L", lines, "--", file) //nolint:gosec // arguments go to git directly, not through a shell
+	out, err := cmd.Output()
+	if err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) && len(ee.Stderr) > 0 {
+			return BlameInfo{}, fmt.Errorf("git blame %s:%d: %w: %s", file, line, err, bytes.TrimSpace(ee.Stderr))
+		}
+		return BlameInfo{}, fmt.Errorf("git blame %s:%d: %w", file, line, err)
+	}
+	return parseBlame(out)
+}
+
+// parseBlame parses the porcelain output of git blame for a single line.
+func parseBlame(out []byte) (BlameInfo, error) {
+	var b BlameInfo
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	if !sc.Scan() {
+		return b, fmt.Errorf("%w: empty git blame output", ErrNoBlame)
+	}
+	b.Commit, _, _ = strings.Cut(sc.Text(), " ")
+
+	// Header lines follow until the line content, which starts with a tab
+	for sc.Scan() {
+		key, value, _ := strings.Cut(sc.Text(), " ")
+		switch key {
+		case "author":
+			b.Author = value
+		case "author-mail":
+			b.AuthorMail = strings.TrimSuffix(strings.TrimPrefix(value, "<"), ">")
+		case "author-time":
+			sec, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return BlameInfo{}, fmt.Errorf("%w: invalid author time %q", ErrNoBlame, value)
+			}
+			b.AuthorTime = time.Unix(sec, 0)
+		}
+		if strings.HasPrefix(key, "\t") {
+			break
+		}
+	}
+	// Commit hashes are 40 hex digits, or 64 in SHA-256 repositories
+	if len(b.Commit) < 40 {
+		return BlameInfo{}, fmt.Errorf("%w: unexpected git blame output", ErrNoBlame)
+	}
+	return b, nil
+}
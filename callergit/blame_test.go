@@ -0,0 +1,117 @@
+package callergit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	caller "github.com/balinomad/go-caller/v2"
+)
+
+// callerAt returns a Caller at file:line.
+func callerAt(t *testing.T, file string, line int) caller.Caller {
+	t.Helper()
+	c := caller.NewEmpty()
+	data, err := json.Marshal(map[string]any{"file": file, "line": line})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// gitRepo creates a repository holding a committed two-line file and
+// returns its root and the path of the file.
+func gitRepo(t *testing.T) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	file := filepath.Join(root, "pkg", "a.go")
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("package pkg\n\nfunc A() {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Ada", "GIT_AUTHOR_EMAIL=ada@example.com", "GIT_AUTHOR_DATE=2024-01-02T03:04:05Z",
+			"GIT_COMMITTER_NAME=Ada", "GIT_COMMITTER_EMAIL=ada@example.com", "GIT_CONFIG_GLOBAL=/dev/null",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	// An uncommitted line below the committed ones
+	if err := os.WriteFile(file, []byte("package pkg\n\nfunc A() {}\nfunc B() {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return root, file
+}
+
+// TestBlame tests blaming committed and uncommitted lines, and callers
+// that cannot be attributed to the repository.
+func TestBlame(t *testing.T) {
+	t.Parallel()
+
+	root, file := gitRepo(t)
+	ctx := context.Background()
+
+	b, err := Blame(ctx, root, callerAt(t, file, 3))
+	if err != nil {
+		t.Fatalf("Blame() error = %v", err)
+	}
+	wantTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if !b.Committed() || b.Author != "Ada" || b.AuthorMail != "ada@example.com" || !b.AuthorTime.Equal(wantTime) {
+		t.Errorf("Blame() = %+v, want a commit by Ada at %v", b, wantTime)
+	}
+	if b.Age() < time.Since(wantTime)-time.Minute {
+		t.Errorf("Age() = %v, want at least %v", b.Age(), time.Since(wantTime))
+	}
+
+	rel, err := Blame(ctx, root, callerAt(t, "pkg/a.go", 3))
+	if err != nil || rel != b {
+		t.Errorf("Blame() of a relative path = %+v, %v, want %+v", rel, err, b)
+	}
+
+	uncommitted, err := Blame(ctx, root, callerAt(t, file, 4))
+	if err != nil || uncommitted.Committed() {
+		t.Errorf("Blame() of an uncommitted line = %+v, %v, want uncommitted", uncommitted, err)
+	}
+
+	tests := []struct {
+		name      string
+		c         caller.Caller
+		wantBlame bool
+	}{
+		{"nil", nil, true},
+		{"no line", callerAt(t, file, 0), true},
+		{"outside repository", callerAt(t, filepath.Join(filepath.Dir(root), "x.go"), 1), true},
+		{"line out of range", callerAt(t, file, 100), false},
+		{"unknown file", callerAt(t, "missing.go", 1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Blame(ctx, root, tt.c)
+			if err == nil || errors.Is(err, ErrNoBlame) != tt.wantBlame {
+				t.Errorf("Blame() error = %v, want ErrNoBlame: %v", err, tt.wantBlame)
+			}
+		})
+	}
+}
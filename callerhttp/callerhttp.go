@@ -0,0 +1,149 @@
+/*
+Package callerhttp serves the captures held by a caller.Recorder over
+HTTP, for quick debugging of a running process in the manner of expvar:
+
+	caller.SetRecorder(caller.NewRecorder(0))
+	http.Handle("/debug/callers", callerhttp.Handler(nil))
+
+It is a package of its own so that programs importing package caller do
+not link net/http and html/template unless they serve the summary.
+*/
+package callerhttp
+
+import (
+	"cmp"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	caller "github.com/balinomad/go-caller/v2"
+)
+
+// recent is the number of most recent entries a Handler lists.
+const recent = 50
+
+// entry is a recorded capture as served by a Handler.
+type entry struct {
+	Time     time.Time `json:"time"`
+	Label    string    `json:"label,omitempty"`
+	Location string    `json:"location"`
+	Function string    `json:"function,omitempty"`
+}
+
+// site is a call site and the number of its recorded captures, as served
+// by a Handler.
+type site struct {
+	Location string    `json:"location"`
+	Function string    `json:"function,omitempty"`
+	Count    int       `json:"count"`
+	Last     time.Time `json:"last"`
+}
+
+// summary is the summary of a Recorder served by a Handler.
+type summary struct {
+	Entries  int     `json:"entries"`
+	Capacity int     `json:"capacity"`
+	Recent   []entry `json:"recent"`
+	Sites    []site  `json:"sites"`
+	Errors   []entry `json:"errors"`
+}
+
+// Handler returns an http.Handler serving a summary of the entries of r:
+// the most recent captures, the number of captures per call site, and
+// the latest location of each error recorded with caller.ErrorLabel. It
+// serves an HTML table, or JSON if the request has format=json in its
+// query or accepts only application/json.
+//
+// A nil r selects the package-wide Recorder at each request. As the
+// summary reveals source paths and function names, the handler belongs
+// behind the same access control as net/http/pprof.
+func Handler(r *caller.Recorder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rec := r
+		if rec == nil {
+			rec = caller.CurrentRecorder()
+		}
+		sum := summarize(rec)
+
+		if req.URL.Query().Get("format") == "json" || req.Header.Get("Accept") == "application/json" {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			// The client has gone if the response cannot be written
+			_ = enc.Encode(sum)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = page().Execute(w, sum)
+	})
+}
+
+// summarize returns the summary of the entries of r, which may be nil.
+func summarize(r *caller.Recorder) summary {
+	sum := summary{Recent: []entry{}, Sites: []site{}, Errors: []entry{}}
+	if r == nil {
+		return sum
+	}
+	entries := r.Snapshot()
+	sum.Entries, sum.Capacity = len(entries), r.Cap()
+
+	sites := make(map[caller.Key]*site)
+	seenErrors := make(map[caller.Key]bool)
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		de := entry{Time: e.Time, Label: e.Label, Location: caller.Location(e.Caller), Function: caller.FullFunction(e.Caller)}
+		if len(sum.Recent) < recent {
+			sum.Recent = append(sum.Recent, de)
+		}
+
+		k := caller.KeyOf(e.Caller)
+		if s, ok := sites[k]; ok {
+			s.Count++
+		} else {
+			sites[k] = &site{Location: de.Location, Function: de.Function, Count: 1, Last: e.Time}
+		}
+		if e.Label == caller.ErrorLabel && !seenErrors[k] {
+			seenErrors[k] = true
+			sum.Errors = append(sum.Errors, de)
+		}
+	}
+
+	for _, s := range sites {
+		sum.Sites = append(sum.Sites, *s)
+	}
+	slices.SortFunc(sum.Sites, func(a, b site) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), b.Last.Compare(a.Last), strings.Compare(a.Location, b.Location))
+	})
+	return sum
+}
+
+// page renders a summary as HTML. It is parsed on first use.
+var page = sync.OnceValue(func() *template.Template {
+	return template.Must(template.New("callers").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Call sites</title></head>
+<body>
+<p>{{.Entries}} of {{.Capacity}} entries recorded.</p>
+<h2>Call sites</h2>
+<table>
+<tr><th>Count</th><th>Last</th><th>Location</th><th>Function</th></tr>
+{{range .Sites}}<tr><td>{{.Count}}</td><td>{{.Last.Format "2006-01-02T15:04:05.000Z07:00"}}</td><td>{{.Location}}</td><td>{{.Function}}</td></tr>
+{{end}}</table>
+<h2>Errors</h2>
+<table>
+<tr><th>Time</th><th>Location</th><th>Function</th></tr>
+{{range .Errors}}<tr><td>{{.Time.Format "2006-01-02T15:04:05.000Z07:00"}}</td><td>{{.Location}}</td><td>{{.Function}}</td></tr>
+{{end}}</table>
+<h2>Recent</h2>
+<table>
+<tr><th>Time</th><th>Label</th><th>Location</th><th>Function</th></tr>
+{{range .Recent}}<tr><td>{{.Time.Format "2006-01-02T15:04:05.000Z07:00"}}</td><td>{{.Label}}</td><td>{{.Location}}</td><td>{{.Function}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+})
@@ -0,0 +1,86 @@
+package callerhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	caller "github.com/balinomad/go-caller/v2"
+)
+
+// callerAt returns a Caller for function fn of package pkg at file:line.
+func callerAt(t *testing.T, file string, line int, pkg, fn string) caller.Caller {
+	t.Helper()
+	c := caller.NewEmpty()
+	data, err := json.Marshal(map[string]any{"file": file, "line": line, "package": pkg, "function": fn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// TestHandler tests the JSON and HTML summaries of a Recorder.
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	r := caller.NewRecorder(10)
+	a := callerAt(t, "/src/app/a.go", 1, "example.com/app", "A")
+	b := callerAt(t, "/src/app/<b>.go", 2, "example.com/app", "B")
+	r.Record(a, "")
+	r.Record(b, caller.ErrorLabel)
+	r.Record(a, "boot")
+	r.Record(b, caller.ErrorLabel)
+	h := Handler(r)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?format=json", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q, want JSON", ct)
+	}
+	var got summary
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got.Entries != 4 || got.Capacity != 10 || len(got.Recent) != 4 || got.Recent[0].Location != b.Location() {
+		t.Errorf("summary = %+v, want 4 of 10 entries, newest first", got)
+	}
+	if len(got.Sites) != 2 || got.Sites[0].Count != 2 || got.Sites[1].Count != 2 || got.Sites[0].Location != b.Location() {
+		t.Errorf("Sites = %+v, want a and b twice each, latest first", got.Sites)
+	}
+	if len(got.Errors) != 1 || got.Errors[0].Location != b.Location() || got.Errors[0].Function != "example.com/app.B" {
+		t.Errorf("Errors = %+v, want the location of b once", got.Errors)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/json")
+	h.ServeHTTP(rec, req)
+	if !json.Valid(rec.Body.Bytes()) {
+		t.Errorf("body = %q for Accept: application/json, want JSON", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Content-Type = %q, want HTML", ct)
+	}
+	if !strings.Contains(body, "/src/app/a.go:1") || !strings.Contains(body, "&lt;b&gt;.go:2") || strings.Contains(body, "<b>") {
+		t.Errorf("body = %q, want both sites, escaped", body)
+	}
+}
+
+// TestHandler_Empty tests serving a summary without a Recorder.
+func TestHandler_Empty(t *testing.T) {
+	t.Parallel()
+
+	got := summarize(nil)
+	if got.Entries != 0 || got.Recent == nil || got.Sites == nil || got.Errors == nil {
+		t.Errorf("summarize(nil) = %+v, want empty lists", got)
+	}
+}
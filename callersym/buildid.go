@@ -0,0 +1,136 @@
+package callersym
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// buildIDPrefix and buildIDSuffix delimit the build ID the go command
+// embeds near the start of the text of non-ELF executables.
+var (
+	buildIDPrefix = []byte("\xff Go build ID: \"")
+	buildIDSuffix = []byte("\"\n \xff")
+)
+
+// buildIDReadSize is how much of a non-ELF executable is searched for
+// the build ID, matching the go command's own limit.
+const buildIDReadSize = 32 * 1024
+
+// ErrNoBuildID is returned by ReadBuildID for a file without a Go
+// build ID.
+var ErrNoBuildID = errors.New("no Go build ID found")
+
+// buildID caches the result of BuildID.
+var buildID = sync.OnceValue(func() string {
+	exe, err := executable()
+	if err != nil {
+		return ""
+	}
+	id, err := ReadBuildID(exe)
+	if err != nil {
+		return ""
+	}
+	return id
+})
+
+// BuildID returns the build ID of the running executable, as printed by
+// `go tool buildid`, or an empty string if it cannot be determined, as
+// on js/wasm and wasip1, where a module cannot read itself.
+// It is read from the executable once and cached.
+//
+// Stacks captured with caller.WithBuildID carry it in their JSON and
+// wire encodings, so that out-of-process symbolization can check that it
+// resolves program counters against the matching executable:
+//
+//	s := caller.NewStack(0, caller.WithBuildID(callersym.BuildID()))
+func BuildID() string {
+	return buildID()
+}
+
+// ReadBuildID reads the build ID of the Go executable at name, for
+// collectors that symbolize stacks out of process and must check that
+// an executable matches the one the stack was captured from:
+//
+//	id, err := callersym.ReadBuildID("bin/server")
+//	if err != nil || id != ws.BuildID {
+//		// wrong executable, do not symbolize
+//	}
+//
+// It returns ErrNoBuildID if the file carries no build ID.
+func ReadBuildID(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("open executable: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // read-only, nothing to flush
+
+	// ELF executables carry the build ID in a note section
+	if ef, err := elf.NewFile(f); err == nil {
+		sec := ef.Section(".note.go.buildid")
+		if sec == nil {
+			return "", ErrNoBuildID
+		}
+		note, err := sec.Data()
+		if err != nil {
+			return "", fmt.Errorf("read build ID note: %w", err)
+		}
+		return nonEmptyBuildID(parseBuildIDNote(note, ef.ByteOrder))
+	}
+
+	// Other formats carry it at the start of the text segment
+	data := make([]byte, buildIDReadSize)
+	n, err := f.ReadAt(data, 0)
+	if err != nil && n == 0 {
+		return "", fmt.Errorf("read executable: %w", err)
+	}
+	return nonEmptyBuildID(findBuildID(data[:n]))
+}
+
+// nonEmptyBuildID returns id, or ErrNoBuildID if it is empty.
+func nonEmptyBuildID(id string) (string, error) {
+	if id == "" {
+		return "", ErrNoBuildID
+	}
+	return id, nil
+}
+
+// parseBuildIDNote extracts the build ID from the contents of an ELF
+// .note.go.buildid section, or returns an empty string if it is not a
+// Go build ID note.
+func parseBuildIDNote(note []byte, order binary.ByteOrder) string {
+	const (
+		headerSize = 12 // namesz, descsz and type, four bytes each
+		noteType   = 4  // Go build ID note type
+	)
+	name := []byte("Go\x00\x00")
+	if len(note) < headerSize+len(name) ||
+		order.Uint32(note) != uint32(len(name)) ||
+		order.Uint32(note[8:]) != noteType ||
+		!bytes.Equal(note[headerSize:headerSize+len(name)], name) {
+		return ""
+	}
+	desc := note[headerSize+len(name):]
+	if n := order.Uint32(note[4:]); uint64(n) <= uint64(len(desc)) {
+		return string(desc[:n])
+	}
+	return ""
+}
+
+// findBuildID extracts the build ID from the start of an executable, or
+// returns an empty string if there is none.
+func findBuildID(data []byte) string {
+	_, rest, ok := bytes.Cut(data, buildIDPrefix)
+	if !ok {
+		return ""
+	}
+	id, _, ok := bytes.Cut(rest, buildIDSuffix)
+	if !ok {
+		return ""
+	}
+	return string(id)
+}
@@ -0,0 +1,98 @@
+package callersym
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestFindBuildID tests extracting the build ID from executable data.
+func TestFindBuildID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"found", "\x7fELF...\xff Go build ID: \"abc/def\"\n \xff...", "abc/def"},
+		{"no prefix", "\x7fELF...", ""},
+		{"no suffix", "\xff Go build ID: \"abc/def", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := findBuildID([]byte(tt.data)); got != tt.want {
+				t.Errorf("findBuildID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBuildID tests reading the build ID of the test binary and of
+// files that are not Go executables.
+func TestBuildID(t *testing.T) {
+	t.Parallel()
+
+	if _, err := executable(); errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("the executable cannot be read on " + runtime.GOOS)
+	}
+	if BuildID() == "" {
+		t.Fatal("BuildID() is empty for a binary built by the go command")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable() error = %v", err)
+	}
+	if id, err := ReadBuildID(exe); err != nil || id != BuildID() {
+		t.Errorf("ReadBuildID(executable) = %q, %v, want %q", id, err, BuildID())
+	}
+
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain")
+	if err := os.WriteFile(plain, []byte("not an executable"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadBuildID(plain); !errors.Is(err, ErrNoBuildID) {
+		t.Errorf("ReadBuildID(plain file) error = %v, want %v", err, ErrNoBuildID)
+	}
+	if _, err := ReadBuildID(filepath.Join(dir, "missing")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadBuildID(missing file) error = %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+// TestParseBuildIDNote tests decoding ELF build ID notes.
+func TestParseBuildIDNote(t *testing.T) {
+	t.Parallel()
+
+	note := func(namesz, descsz, typ uint32, name, desc string) []byte {
+		b := binary.LittleEndian.AppendUint32(nil, namesz)
+		b = binary.LittleEndian.AppendUint32(b, descsz)
+		b = binary.LittleEndian.AppendUint32(b, typ)
+		return append(append(b, name...), desc...)
+	}
+	tests := []struct {
+		name string
+		note []byte
+		want string
+	}{
+		{"valid", note(4, 7, 4, "Go\x00\x00", "abc/def"), "abc/def"},
+		{"padded", note(4, 3, 4, "Go\x00\x00", "abc\x00"), "abc"},
+		{"wrong type", note(4, 7, 3, "Go\x00\x00", "abc/def"), ""},
+		{"wrong name", note(4, 7, 4, "GNU\x00", "abc/def"), ""},
+		{"desc too long", note(4, 70, 4, "Go\x00\x00", "abc/def"), ""},
+		{"short", []byte{4, 0, 0, 0}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := parseBuildIDNote(tt.note, binary.LittleEndian); got != tt.want {
+				t.Errorf("parseBuildIDNote() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+/*
+Package callersym reads what package caller leaves out of the captures it
+takes from the running executable: its build ID, which BuildID reports
+for recording in stacks with caller.WithBuildID, its debug information,
+from which Signature reports the parameter and result types of a
+caller's function, and its build time, against which CheckSource and
+SourceManifest tell whether a caller's source file has changed since.
+FuncAt works the other way, from a bare location pasted from logs to the
+name of the function at it, by parsing the source file.
+
+It is a package of its own so that programs importing package caller do
+not link the debug/elf, debug/macho, debug/pe and debug/dwarf readers
+or the Go parser unless they use them.
+*/
+package callersym
@@ -0,0 +1,167 @@
+package callersym
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	caller "github.com/balinomad/go-caller/v2"
+)
+
+var (
+	// ErrSourceDrift is returned by CheckSource and SourceManifest.Check
+	// when the source file of a caller no longer matches the code the
+	// program was built from.
+	ErrSourceDrift = errors.New("source file changed")
+
+	// ErrNoSource is returned by CheckSource and SourceManifest.Check when
+	// the source file of a caller cannot be read or compared.
+	ErrNoSource = errors.New("source not available")
+)
+
+// buildTime returns the time the running executable was built, which is
+// read once: the modification time of the executable or, where it cannot
+// be read, the commit time recorded in its build information.
+var buildTime = sync.OnceValues(func() (time.Time, error) {
+	exe, err := executable()
+	if err == nil {
+		var fi os.FileInfo
+		if fi, err = os.Stat(exe); err == nil {
+			return fi.ModTime(), nil
+		}
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			if s.Key != "vcs.time" {
+				continue
+			}
+			if t, perr := time.Parse(time.RFC3339, s.Value); perr == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, err
+})
+
+// CheckSource reports whether the source file of c is still the one the
+// program was built from, before rendering snippets or links that would
+// otherwise mislead in long-lived deployments. The file is assumed to
+// have changed if it was modified after the executable was built; use a
+// SourceManifest to compare contents instead. The file is only looked at
+// when CheckSource is called, never when callers are captured.
+//
+// It returns nil if the file is unchanged, an error matching
+// ErrSourceDrift if it changed, and one matching ErrNoSource if c has no
+// file, or the file or the build time cannot be read.
+func CheckSource(c caller.Caller) error {
+	file := caller.File(c)
+	if file == "" {
+		return fmt.Errorf("%w: caller has no file", ErrNoSource)
+	}
+	src, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNoSource, err)
+	}
+	built, err := buildTime()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNoSource, err)
+	}
+	if src.ModTime().After(built) {
+		return fmt.Errorf("%w: %s was modified after the executable was built", ErrSourceDrift, file)
+	}
+	return nil
+}
+
+// SourceManifest holds the SHA-256 hashes of source files taken at build
+// time, for checking that the files on disk are still the ones the
+// program was built from. Generate it in the output format of sha256sum
+// and embed it in the executable:
+//
+//	//go:generate sh -c "sha256sum $(git ls-files '*.go') > sources.sum"
+//	//go:embed sources.sum
+//	var sourcesSum []byte
+//
+// Its paths may be relative: a caller's file matches the longest path in
+// the manifest that it ends with at a path element boundary.
+type SourceManifest struct {
+	hashes map[string][sha256.Size]byte
+}
+
+// ParseSourceManifest parses a manifest in the output format of sha256sum,
+// one hash and path per line. It returns an error if a line is malformed.
+func ParseSourceManifest(data []byte) (SourceManifest, error) {
+	m := SourceManifest{hashes: make(map[string][sha256.Size]byte)}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		sum, file, ok := strings.Cut(line, " ")
+		file = strings.TrimPrefix(file, " ")
+		file = strings.TrimPrefix(file, "*") // Binary mode marker
+		var hash [sha256.Size]byte
+		if !ok || file == "" || hex.DecodedLen(len(sum)) != sha256.Size {
+			return SourceManifest{}, fmt.Errorf("source manifest line %d: malformed entry %q", n, line)
+		}
+		if _, err := hex.Decode(hash[:], []byte(sum)); err != nil {
+			return SourceManifest{}, fmt.Errorf("source manifest line %d: %w", n, err)
+		}
+		m.hashes[path.Clean(file)] = hash
+	}
+	if err := sc.Err(); err != nil {
+		return SourceManifest{}, fmt.Errorf("source manifest: %w", err)
+	}
+	return m, nil
+}
+
+// Check reports whether the source file of c still has the content
+// recorded in m, reading and hashing it. It returns nil if it does, an
+// error matching ErrSourceDrift if it does not, and one matching
+// ErrNoSource if c has no file, the file is not in m, or it cannot be
+// read.
+func (m SourceManifest) Check(c caller.Caller) error {
+	file := caller.File(c)
+	if file == "" {
+		return fmt.Errorf("%w: caller has no file", ErrNoSource)
+	}
+	want, ok := m.lookup(file)
+	if !ok {
+		return fmt.Errorf("%w: %s is not in the manifest", ErrNoSource, file)
+	}
+	data, err := os.ReadFile(file) //nolint:gosec // Reading the program's own source files is the purpose
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNoSource, err)
+	}
+	if sha256.Sum256(data) != want {
+		return fmt.Errorf("%w: %s differs from the build", ErrSourceDrift, file)
+	}
+	return nil
+}
+
+// lookup returns the hash recorded in m for file, matching it exactly or
+// by the longest path it ends with at a path element boundary.
+func (m SourceManifest) lookup(file string) ([sha256.Size]byte, bool) {
+	if hash, ok := m.hashes[file]; ok {
+		return hash, true
+	}
+	var (
+		best  string
+		found [sha256.Size]byte
+	)
+	for p, hash := range m.hashes {
+		if len(p) > len(best) && strings.HasSuffix(file, "/"+p) {
+			best, found = p, hash
+		}
+	}
+	return found, best != ""
+}
@@ -0,0 +1,137 @@
+package callersym
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	caller "github.com/balinomad/go-caller/v2"
+)
+
+// TestCheckSource tests comparing the modification time of a source file
+// with the build time of the executable.
+func TestCheckSource(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := callerAt(t, file, 1, "main", "main")
+	err := CheckSource(c)
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("build time not available on this platform")
+	}
+	if !errors.Is(err, ErrSourceDrift) {
+		t.Errorf("CheckSource() of a file newer than the executable error = %v, want %v", err, ErrSourceDrift)
+	}
+
+	old := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(file, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckSource(c); err != nil {
+		t.Errorf("CheckSource() of a file older than the executable error = %v, want nil", err)
+	}
+
+	for _, c := range []caller.Caller{nil, callerAt(t, "", 1, "", ""), callerAt(t, file+".missing", 1, "", "")} {
+		if err := CheckSource(c); !errors.Is(err, ErrNoSource) {
+			t.Errorf("CheckSource(%v) error = %v, want %v", c, err, ErrNoSource)
+		}
+	}
+}
+
+// TestParseSourceManifest tests parsing manifests in the output format of
+// sha256sum.
+func TestParseSourceManifest(t *testing.T) {
+	t.Parallel()
+
+	sum := hex.EncodeToString(make([]byte, sha256.Size))
+	tests := []struct {
+		name    string
+		data    string
+		want    []string
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"text mode", sum + "  main.go\n", []string{"main.go"}, false},
+		{"binary mode", sum + " *./cmd/app/main.go\n", []string{"cmd/app/main.go"}, false},
+		{"blank lines", "\n" + sum + "  a.go\n\n" + sum + "  b.go", []string{"a.go", "b.go"}, false},
+		{"missing path", sum + "\n", nil, true},
+		{"short hash", "abcd  main.go\n", nil, true},
+		{"invalid hex", sum[:62] + "zz  main.go\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := ParseSourceManifest([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSourceManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(m.hashes) != len(tt.want) {
+				t.Errorf("ParseSourceManifest() has %d entries, want %d", len(m.hashes), len(tt.want))
+			}
+			for _, file := range tt.want {
+				if _, ok := m.hashes[file]; !ok {
+					t.Errorf("ParseSourceManifest() has no entry for %q", file)
+				}
+			}
+		})
+	}
+}
+
+// TestSourceManifest_Check tests comparing source files with the hashes
+// recorded in a manifest.
+func TestSourceManifest_Check(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "cmd", "app")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "main.go")
+	content := []byte("package main\n")
+	if err := os.WriteFile(file, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	other := hex.EncodeToString(make([]byte, sha256.Size))
+	m, err := ParseSourceManifest([]byte(
+		hex.EncodeToString(sum[:]) + "  cmd/app/main.go\n" +
+			other + "  main.go\n" + // Shorter suffix, not preferred
+			other + "  pp/main.go\n", // Not at a path element boundary
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := callerAt(t, filepath.ToSlash(file), 1, "main", "main")
+
+	if err := m.Check(c); err != nil {
+		t.Errorf("Check() of an unchanged file error = %v, want nil", err)
+	}
+	if err := os.WriteFile(file, []byte("package main\n\nfunc main() {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Check(c); !errors.Is(err, ErrSourceDrift) {
+		t.Errorf("Check() of a changed file error = %v, want %v", err, ErrSourceDrift)
+	}
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Check(c); !errors.Is(err, ErrNoSource) || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Check() of a removed file error = %v, want %v", err, ErrNoSource)
+	}
+
+	for _, c := range []caller.Caller{nil, callerAt(t, "", 1, "", ""), callerAt(t, "/src/other.go", 1, "", "")} {
+		if err := m.Check(c); !errors.Is(err, ErrNoSource) {
+			t.Errorf("Check(%v) error = %v, want %v", c, err, ErrNoSource)
+		}
+	}
+	if err := (SourceManifest{}).Check(c); !errors.Is(err, ErrNoSource) {
+		t.Errorf("Check() with an empty manifest error = %v, want %v", err, ErrNoSource)
+	}
+}
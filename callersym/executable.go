@@ -0,0 +1,18 @@
+//go:build !js && !wasip1
+
+package callersym
+
+import (
+	"fmt"
+	"os"
+)
+
+// executable returns the path of the running executable, from which its
+// build ID, debug information and build time are read.
+func executable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("locate executable: %w", err)
+	}
+	return exe, nil
+}
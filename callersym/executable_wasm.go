@@ -0,0 +1,18 @@
+//go:build js || wasip1
+
+package callersym
+
+import (
+	"errors"
+	"fmt"
+)
+
+// executable reports that the running executable cannot be read: a
+// WebAssembly module is loaded by its host and has no path of its own,
+// so BuildID is empty, Signature reports ErrNoDebugInfo and CheckSource
+// falls back to the commit time recorded in the build. ReadBuildID still
+// reads the build ID of a module file, for example on the host that
+// serves it.
+func executable() (string, error) {
+	return "", fmt.Errorf("locate executable: %w", errors.ErrUnsupported)
+}
@@ -0,0 +1,26 @@
+//go:build js || wasip1
+
+package callersym
+
+import (
+	"errors"
+	"testing"
+
+	caller "github.com/balinomad/go-caller/v2"
+)
+
+// TestWasmExecutable tests that what needs the executable reports it
+// unavailable on WebAssembly targets.
+func TestWasmExecutable(t *testing.T) {
+	t.Parallel()
+
+	if _, err := executable(); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("executable() error = %v, want %v", err, errors.ErrUnsupported)
+	}
+	if id := BuildID(); id != "" {
+		t.Errorf("BuildID() = %q, want empty", id)
+	}
+	if _, err := Signature(caller.Immediate()); !errors.Is(err, ErrNoDebugInfo) {
+		t.Errorf("Signature() error = %v, want %v", err, ErrNoDebugInfo)
+	}
+}
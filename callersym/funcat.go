@@ -0,0 +1,159 @@
+package callersym
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+)
+
+// ErrNoFunction is returned by FuncAt when no function contains the
+// requested line.
+var ErrNoFunction = errors.New("no function at location")
+
+// FuncAt returns the name of the function that contains the given line of
+// the Go source file, so that tools can enrich bare file:line locations,
+// such as those pasted from logs, with function context. The name is
+// spelled as the runtime spells it, qualified by the package name from
+// the package clause rather than the import path, which a single file
+// does not record:
+//
+//	server.(*Handler).ServeHTTP
+//	server.Run.func2
+//	server.Run.func2.1
+//
+// Function literals are numbered in source order within their enclosing
+// function, as the compiler numbers them; a literal that the compiler
+// inlines into its caller, such as one called where it is defined, gets
+// a longer name at run time. Those in package-level variable
+// initializers belong to the package's init function; as the numbering
+// of those spans the whole package, it is only exact for packages with a
+// single such file. It returns an error wrapping ErrNoFunction if the
+// line lies outside every function, or the error from reading or parsing
+// the file.
+func FuncAt(file string, line int) (string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return "", fmt.Errorf("parse source: %w", err)
+	}
+
+	l := funcLocator{fset: fset, line: line}
+	inits := 0
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			name := funcDeclName(d)
+			if name == "init" {
+				// The compiler numbers init functions in source order
+				name += "." + strconv.Itoa(inits)
+				inits++
+			}
+			if l.contains(d) {
+				return f.Name.Name + "." + l.closure(name, d.Body, false), nil
+			}
+		case *ast.GenDecl:
+			if name, ok := l.initializer(d); ok {
+				return f.Name.Name + "." + name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w: %s:%d", ErrNoFunction, file, line)
+}
+
+// funcLocator finds the function containing a line of a parsed file.
+type funcLocator struct {
+	fset  *token.FileSet
+	line  int
+	inits int // Function literals seen so far in package-level initializers
+}
+
+// contains reports whether n spans the line being located.
+func (l *funcLocator) contains(n ast.Node) bool {
+	return l.fset.Position(n.Pos()).Line <= l.line && l.line <= l.fset.Position(n.End()).Line
+}
+
+// closure returns the name of the innermost function literal within body
+// that contains the line, named after the function called name that
+// encloses body, or name itself if there is none. The compiler numbers
+// literals as name.funcN within a declared function, and as name.N
+// within another literal, which inLit reports.
+func (l *funcLocator) closure(name string, body ast.Node, inLit bool) string {
+	if body == nil {
+		return name
+	}
+	n, found, sep := 0, name, ".func"
+	if inLit {
+		sep = "."
+	}
+	ast.Inspect(body, func(node ast.Node) bool {
+		lit, ok := node.(*ast.FuncLit)
+		if !ok {
+			return true
+		}
+		// Nested literals are numbered within this one, so do not descend
+		n++
+		if l.contains(lit) {
+			found = l.closure(name+sep+strconv.Itoa(n), lit.Body, true)
+		}
+		return false
+	})
+	return found
+}
+
+// initializer returns the name of the function literal in the
+// package-level declaration d that contains the line, counting literals
+// across the declarations of the file. It reports false if there is none.
+func (l *funcLocator) initializer(d *ast.GenDecl) (string, bool) {
+	found := ""
+	ast.Inspect(d, func(node ast.Node) bool {
+		lit, ok := node.(*ast.FuncLit)
+		if !ok {
+			return true
+		}
+		l.inits++
+		if l.contains(lit) {
+			found = l.closure("init.func"+strconv.Itoa(l.inits), lit.Body, true)
+		}
+		return false
+	})
+	return found, found != ""
+}
+
+// funcDeclName returns the name of the function or method declared by d
+// as the runtime spells it, without the package.
+func funcDeclName(d *ast.FuncDecl) string {
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		if d.Type.TypeParams != nil {
+			return d.Name.Name + "[...]"
+		}
+		return d.Name.Name
+	}
+
+	typ, ptr := d.Recv.List[0].Type, false
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ, ptr = star.X, true
+	}
+	generic := false
+	switch t := typ.(type) {
+	case *ast.IndexExpr:
+		typ, generic = t.X, true
+	case *ast.IndexListExpr:
+		typ, generic = t.X, true
+	}
+	id, ok := typ.(*ast.Ident)
+	if !ok {
+		return d.Name.Name
+	}
+
+	recv := id.Name
+	if generic {
+		recv += "[...]"
+	}
+	if ptr {
+		return "(*" + recv + ")." + d.Name.Name
+	}
+	return recv + "." + d.Name.Name
+}
@@ -0,0 +1,137 @@
+package callersym
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	caller "github.com/balinomad/go-caller/v2"
+)
+
+// funcAtType has methods whose captures FuncAt is checked against.
+type funcAtType struct{}
+
+// value captures its own location in a method with a value receiver.
+func (funcAtType) value() caller.Caller { return caller.Immediate() }
+
+// pointer captures its own location in a method with a pointer receiver.
+func (*funcAtType) pointer() caller.Caller { return caller.Immediate() }
+
+// funcAtGeneric captures its own location in a closure of a generic
+// function.
+func funcAtGeneric[T any]() caller.Caller {
+	return func() caller.Caller { return caller.Immediate() }()
+}
+
+// TestFuncAt tests that FuncAt names functions as the runtime does.
+func TestFuncAt(t *testing.T) {
+	t.Parallel()
+
+	var nested caller.Caller
+	funcAtCall(func() {
+		funcAtCall(func() {
+			nested = caller.Immediate()
+		})
+	})
+	second := func() caller.Caller { return caller.Immediate() }
+
+	for name, c := range map[string]caller.Caller{
+		"function":         caller.Immediate(),
+		"value method":     funcAtType{}.value(),
+		"pointer method":   (&funcAtType{}).pointer(),
+		"generic closure":  funcAtGeneric[int](),
+		"nested closure":   nested,
+		"second closure":   second(),
+		"deferred closure": funcAtDeferred(),
+	} {
+		// The runtime qualifies names by import path, FuncAt by package name
+		want := "callersym." + strings.TrimPrefix(c.FullFunction(), c.Package()+".")
+		if got, err := FuncAt(c.File(), c.Line()); err != nil || got != want {
+			t.Errorf("%s: FuncAt(%s) = %q, %v, want %q", name, c.ShortLocation(), got, err, want)
+		}
+	}
+}
+
+// funcAtCall calls f. As it is not inlined, neither is f, which keeps
+// the name the compiler gives to f in source.
+//
+//go:noinline
+func funcAtCall(f func()) {
+	f()
+}
+
+// funcAtDeferred captures its own location in a deferred closure that
+// follows another closure.
+func funcAtDeferred() caller.Caller {
+	var c caller.Caller
+	_ = func() {}
+	funcAtCall(func() {
+		defer func() { c = caller.Immediate() }()
+	})
+	return c
+}
+
+// TestFuncAt_Source tests naming functions in source files.
+func TestFuncAt_Source(t *testing.T) {
+	t.Parallel()
+
+	const src = `package demo
+
+var handler = func() {
+	_ = func() {}
+}
+
+var other = func() {}
+
+func init() {}
+
+func init() {
+	_ = func() {}
+}
+
+type List[T any] struct{}
+
+func (l *List[T]) Push(v T) {}
+
+func (List[T]) Len() int { return 0 }
+
+func Map[K comparable, V any]() {}
+`
+	file := filepath.Join(t.TempDir(), "demo.go")
+	if err := os.WriteFile(file, []byte(src), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		line int
+		want string
+	}{
+		{3, "demo.init.func1"},
+		{4, "demo.init.func1.1"},
+		{7, "demo.init.func2"},
+		{9, "demo.init.0"},
+		{12, "demo.init.1.func1"},
+		{17, "demo.(*List[...]).Push"},
+		{19, "demo.List[...].Len"},
+		{21, "demo.Map[...]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+			if got, err := FuncAt(file, tt.line); err != nil || got != tt.want {
+				t.Errorf("FuncAt(%d) = %q, %v, want %q", tt.line, got, err, tt.want)
+			}
+		})
+	}
+
+	for _, line := range []int{1, 15, 100} {
+		if _, err := FuncAt(file, line); !errors.Is(err, ErrNoFunction) {
+			t.Errorf("FuncAt(%d) error = %v, want ErrNoFunction", line, err)
+		}
+	}
+	if _, err := FuncAt(filepath.Join(t.TempDir(), "missing.go"), 1); err == nil || errors.Is(err, ErrNoFunction) {
+		t.Errorf("FuncAt(missing file) error = %v, want a read error", err)
+	}
+}
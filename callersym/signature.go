@@ -0,0 +1,244 @@
+package callersym
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	caller "github.com/balinomad/go-caller/v2"
+)
+
+var (
+	// ErrNoDebugInfo is returned by Signature when the running executable
+	// carries no DWARF debug information, for example because it was
+	// linked with -ldflags=-w.
+	ErrNoDebugInfo = errors.New("no DWARF debug information")
+
+	// ErrNoSignature is returned by Signature when the debug information
+	// does not describe the caller's function.
+	ErrNoSignature = errors.New("function signature not found")
+)
+
+// debugInfo is the DWARF data of the running executable, with an index
+// of its functions by full name.
+type debugInfo struct {
+	data  *dwarf.Data
+	funcs map[string]dwarf.Offset
+}
+
+// param is a function parameter or result read from debug information.
+type param struct {
+	name string // Empty if unnamed
+	typ  string
+}
+
+// loadDebugInfo reads and indexes the debug information of the running
+// executable once.
+var loadDebugInfo = sync.OnceValues(func() (*debugInfo, error) {
+	exe, err := executable()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoDebugInfo, err)
+	}
+	data, err := openDWARF(exe)
+	if err != nil {
+		return nil, err
+	}
+	return indexDWARF(data)
+})
+
+// Signature returns the parameter and result types of the function of c,
+// read from the DWARF debug information of the running executable, in
+// Go syntax with fully qualified type names:
+//
+//	func(ctx context.Context, id int) (*example.com/app.User, error)
+//
+// Methods report their receiver as the first parameter, and variadic
+// parameters are reported as slices. It lets rich error contexts tell
+// apart functions of the same name in generated or generic-heavy code.
+//
+// The debug information is read and indexed on the first call, which
+// can take a noticeable time for large executables. Signature returns
+// ErrNoDebugInfo if the executable carries no debug information or
+// cannot be read, as on js/wasm and wasip1, and
+// ErrNoSignature if it does not describe the function of c.
+func Signature(c caller.Caller) (string, error) {
+	fn := caller.FullFunction(c)
+	if fn == "" {
+		return "", ErrNoSignature
+	}
+	info, err := loadDebugInfo()
+	if err != nil {
+		return "", err
+	}
+	off, ok := info.funcs[fn]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNoSignature, fn)
+	}
+	params, results, err := info.params(off)
+	if err != nil {
+		return "", err
+	}
+	return formatSignature(params, results), nil
+}
+
+// openDWARF reads the DWARF data of the executable at name, whichever
+// object format it uses.
+func openDWARF(name string) (*dwarf.Data, error) {
+	type dwarfFile interface {
+		DWARF() (*dwarf.Data, error)
+		Close() error
+	}
+
+	var f dwarfFile
+	if ef, err := elf.Open(name); err == nil {
+		f = ef
+	} else if mf, err := macho.Open(name); err == nil {
+		f = mf
+	} else if pf, err := pe.Open(name); err == nil {
+		f = pf
+	} else {
+		return nil, fmt.Errorf("%w: unsupported executable format", ErrNoDebugInfo)
+	}
+	defer f.Close() //nolint:errcheck // read-only, nothing to flush
+
+	data, err := f.DWARF()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoDebugInfo, err)
+	}
+	return data, nil
+}
+
+// indexDWARF indexes the functions described by data by full name.
+func indexDWARF(data *dwarf.Data) (*debugInfo, error) {
+	info := &debugInfo{data: data, funcs: make(map[string]dwarf.Offset)}
+	r := data.Reader()
+	for {
+		e, err := r.Next()
+		if err != nil {
+			return nil, fmt.Errorf("read DWARF: %w", err)
+		}
+		if e == nil {
+			return info, nil
+		}
+
+		switch e.Tag { //nolint:exhaustive // only compile units and functions matter
+		case dwarf.TagCompileUnit:
+			continue // Descend into the unit's children
+		case dwarf.TagSubprogram:
+			// Inlined functions are described once by name, and their
+			// out-of-line copies refer back to that description
+			if name, ok := e.Val(dwarf.AttrName).(string); ok {
+				if _, dup := info.funcs[name]; !dup {
+					info.funcs[name] = e.Offset
+				}
+			}
+		}
+		if e.Children {
+			r.SkipChildren()
+		}
+	}
+}
+
+// params reads the parameters and results of the function described at
+// off, in declaration order.
+func (info *debugInfo) params(off dwarf.Offset) ([]param, []param, error) {
+	r := info.data.Reader()
+	r.Seek(off)
+	e, err := r.Next()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read DWARF: %w", err)
+	}
+	if e == nil {
+		return nil, nil, ErrNoSignature
+	}
+	if !e.Children {
+		return nil, nil, nil
+	}
+
+	var params, results []param
+	for {
+		e, err := r.Next()
+		if err != nil {
+			return nil, nil, fmt.Errorf("read DWARF: %w", err)
+		}
+		if e == nil || e.Tag == 0 {
+			return params, results, nil
+		}
+		if e.Tag == dwarf.TagFormalParameter {
+			p := param{typ: info.typeName(e)}
+			if name, ok := e.Val(dwarf.AttrName).(string); ok && !strings.HasPrefix(name, "~") {
+				p.name = name
+			}
+			// Go marks results as variable parameters
+			if isResult, ok := e.Val(dwarf.AttrVarParam).(bool); ok && isResult {
+				results = append(results, p)
+			} else {
+				params = append(params, p)
+			}
+		}
+		if e.Children {
+			r.SkipChildren()
+		}
+	}
+}
+
+// typeName returns the Go name of the type of the entry e, or "?" if it
+// cannot be determined.
+func (info *debugInfo) typeName(e *dwarf.Entry) string {
+	off, ok := e.Val(dwarf.AttrType).(dwarf.Offset)
+	if !ok {
+		return "?"
+	}
+	r := info.data.Reader()
+	r.Seek(off)
+	t, err := r.Next()
+	if err != nil || t == nil {
+		return "?"
+	}
+	if name, ok := t.Val(dwarf.AttrName).(string); ok {
+		return name
+	}
+	return "?"
+}
+
+// formatSignature renders a function signature in Go syntax.
+func formatSignature(params, results []param) string {
+	var sb strings.Builder
+	sb.WriteString("func")
+	writeParams(&sb, params)
+	switch {
+	case len(results) == 1 && results[0].name == "":
+		sb.WriteByte(' ')
+		sb.WriteString(results[0].typ)
+	case len(results) > 0:
+		sb.WriteByte(' ')
+		writeParams(&sb, results)
+	}
+	return sb.String()
+}
+
+// writeParams writes a parenthesized parameter list to sb, with names
+// only if every parameter is named.
+func writeParams(sb *strings.Builder, params []param) {
+	named := true
+	for _, p := range params {
+		named = named && p.name != ""
+	}
+	sb.WriteByte('(')
+	for i, p := range params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if named {
+			sb.WriteString(p.name)
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(p.typ)
+	}
+	sb.WriteByte(')')
+}
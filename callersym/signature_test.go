@@ -0,0 +1,94 @@
+package callersym
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	caller "github.com/balinomad/go-caller/v2"
+)
+
+// callerAt returns a Caller for function fn of package pkg at file:line.
+func callerAt(t *testing.T, file string, line int, pkg, fn string) caller.Caller {
+	t.Helper()
+	c := caller.NewEmpty()
+	data, err := json.Marshal(map[string]any{"file": file, "line": line, "package": pkg, "function": fn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// signatureTarget captures its own caller for TestSignature.
+//
+//go:noinline
+func signatureTarget(n int, names []string) (caller.Caller, error) {
+	_, _ = n, names
+	return caller.Immediate(), nil
+}
+
+// signatureMethodTarget has a receiver and named results for TestSignature.
+type signatureMethodTarget struct{}
+
+// capture captures its own caller for TestSignature.
+//
+//go:noinline
+func (*signatureMethodTarget) capture(_ ...string) (c caller.Caller, ok bool) {
+	return caller.Immediate(), true
+}
+
+// TestSignature tests reading function signatures from debug information.
+func TestSignature(t *testing.T) {
+	t.Parallel()
+
+	c, _ := signatureTarget(1, nil)
+	got, err := Signature(c)
+	if errors.Is(err, ErrNoDebugInfo) {
+		t.Skip("test binary has no debug information")
+	}
+	if want := "func(n int, names []string) (github.com/balinomad/go-caller/v2.Caller, error)"; err != nil || got != want {
+		t.Errorf("Signature() = %q, %v, want %q", got, err, want)
+	}
+
+	var m signatureMethodTarget
+	c, _ = m.capture()
+	want := "func(*github.com/balinomad/go-caller/v2/callersym.signatureMethodTarget, []string) (c github.com/balinomad/go-caller/v2.Caller, ok bool)"
+	if got, err := Signature(c); err != nil || got != want {
+		t.Errorf("Signature() = %q, %v, want %q", got, err, want)
+	}
+
+	for _, c := range []caller.Caller{nil, callerAt(t, "/src/missing.go", 1, "example.com/missing", "F")} {
+		if _, err := Signature(c); !errors.Is(err, ErrNoSignature) {
+			t.Errorf("Signature(%v) error = %v, want %v", c, err, ErrNoSignature)
+		}
+	}
+}
+
+// TestFormatSignature tests rendering parameter and result lists.
+func TestFormatSignature(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		params, results []param
+		want            string
+	}{
+		{"empty", nil, nil, "func()"},
+		{"named params", []param{{"a", "int"}, {"b", "string"}}, nil, "func(a int, b string)"},
+		{"partly named params", []param{{"a", "int"}, {"", "string"}}, nil, "func(int, string)"},
+		{"single result", nil, []param{{"", "error"}}, "func() error"},
+		{"named result", nil, []param{{"err", "error"}}, "func() (err error)"},
+		{"two results", []param{{"", "int"}}, []param{{"", "int"}, {"", "bool"}}, "func(int) (int, bool)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := formatSignature(tt.params, tt.results); got != tt.want {
+				t.Errorf("formatSignature() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
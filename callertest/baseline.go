@@ -0,0 +1,136 @@
+package callertest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	caller "github.com/balinomad/go-caller/v2"
+)
+
+// UpdateEnv is the environment variable that, set to a non-empty value,
+// makes ApproveSites write the call sites it records to the baseline file
+// instead of comparing them with it:
+//
+//	CALLERTEST_UPDATE=1 go test ./...
+const UpdateEnv = "CALLERTEST_UPDATE"
+
+// baselineHeader starts every baseline file written by ApproveSites.
+const baselineHeader = "# Call sites captured by the test, as recorded by callertest.ApproveSites.\n" +
+	"# Regenerate with " + UpdateEnv + "=1.\n"
+
+// ApproveSites records the call sites captured by package caller, through
+// its capture hooks, for the rest of the test, and when the test ends
+// compares them with the baseline file at path, failing the test if sites
+// appeared or disappeared. It gives approval-style tests of the
+// instrumentation of error paths:
+//
+//	func TestErrorPaths(t *testing.T) {
+//		callertest.ApproveSites(t, "testdata/error_sites.txt")
+//		runAllErrorScenarios(t)
+//	}
+//
+// Sites are recorded by caller.FunctionKey, so the baseline holds one line
+// per function that captured, independent of the checkout location and of
+// line shifts. Run the test with UpdateEnv set to create or update the
+// baseline, and commit it. Captures from every goroutine are recorded, so
+// the test must not run in parallel with others that capture callers.
+func ApproveSites(tb testing.TB, path string) {
+	tb.Helper()
+
+	var mu sync.Mutex
+	sites := make(map[string]struct{})
+	remove := caller.OnCapture(func(c caller.Caller) {
+		if key := caller.FunctionKey(c); key != "" {
+			mu.Lock()
+			sites[key] = struct{}{}
+			mu.Unlock()
+		}
+	})
+
+	tb.Cleanup(func() {
+		remove()
+		mu.Lock()
+		got := slices.Sorted(maps.Keys(sites))
+		mu.Unlock()
+
+		if os.Getenv(UpdateEnv) != "" {
+			if err := writeBaseline(path, got); err != nil {
+				tb.Errorf("update call-site baseline: %v", err)
+			}
+			return
+		}
+		want, err := readBaseline(path)
+		if err != nil {
+			tb.Errorf("read call-site baseline: %v; run with %s=1 to create it", err, UpdateEnv)
+			return
+		}
+		if diff := diffSites(want, got); diff != "" {
+			tb.Errorf("call sites differ from baseline %s (-baseline +captured); run with %s=1 to approve:\n%s", path, UpdateEnv, diff)
+		}
+	})
+}
+
+// readBaseline returns the sites listed in the baseline file at path,
+// sorted, ignoring blank lines and comments.
+func readBaseline(path string) ([]string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // The baseline path is chosen by the test
+	if err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+	var sites []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			sites = append(sites, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+	slices.Sort(sites)
+	return slices.Compact(sites), nil
+}
+
+// writeBaseline writes sites to the baseline file at path, creating its
+// directory if needed.
+func writeBaseline(path string, sites []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create baseline directory: %w", err)
+	}
+	var b strings.Builder
+	b.WriteString(baselineHeader)
+	for _, s := range sites {
+		b.WriteString(s)
+		b.WriteByte('\n')
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("write baseline: %w", err)
+	}
+	return nil
+}
+
+// diffSites returns the sites of want missing from got, prefixed by "-",
+// and those of got missing from want, prefixed by "+", one per line, or an
+// empty string if both sorted lists hold the same sites.
+func diffSites(want, got []string) string {
+	var b strings.Builder
+	for _, s := range want {
+		if _, ok := slices.BinarySearch(got, s); !ok {
+			b.WriteString("-" + s + "\n")
+		}
+	}
+	for _, s := range got {
+		if _, ok := slices.BinarySearch(want, s); !ok {
+			b.WriteString("+" + s + "\n")
+		}
+	}
+	return b.String()
+}
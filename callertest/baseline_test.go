@@ -0,0 +1,104 @@
+package callertest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	caller "github.com/balinomad/go-caller/v2"
+)
+
+// recordingTB is a testing.TB that records errors and cleanups instead of
+// acting on them.
+type recordingTB struct {
+	testing.TB
+	errs     []string
+	cleanups []func()
+}
+
+func (r *recordingTB) Helper()                      {}
+func (r *recordingTB) Cleanup(fn func())            { r.cleanups = append(r.cleanups, fn) }
+func (r *recordingTB) Errorf(f string, args ...any) { r.errs = append(r.errs, fmt.Sprintf(f, args...)) }
+
+// finish runs the recorded cleanups, as the end of a test would.
+func (r *recordingTB) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+// siteA captures the site calling it, and siteB its own site.
+func siteA() caller.Caller { return caller.New(0) }
+func siteB() caller.Caller { return caller.Immediate() }
+
+// approve runs ApproveSites around the captures of fns and returns the
+// errors it reports.
+func approve(path string, fns ...func() caller.Caller) []string {
+	tb := &recordingTB{}
+	ApproveSites(tb, path)
+	for _, fn := range fns {
+		fn()
+	}
+	tb.finish()
+	return tb.errs
+}
+
+// TestApproveSites tests creating, matching and diffing a baseline.
+func TestApproveSites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "sites.txt")
+
+	if errs := approve(path, siteA); len(errs) != 1 || !strings.Contains(errs[0], UpdateEnv) {
+		t.Errorf("ApproveSites() without a baseline errors = %q, want a hint to create it", errs)
+	}
+
+	t.Setenv(UpdateEnv, "1")
+	if errs := approve(path, siteA, siteB, siteB); len(errs) != 0 {
+		t.Fatalf("ApproveSites() update errors = %q, want none", errs)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(data), "\n"); !strings.HasPrefix(string(data), "#") || got != 4 {
+		t.Errorf("baseline = %q, want a header and one line per site", data)
+	}
+
+	t.Setenv(UpdateEnv, "")
+	if errs := approve(path, siteB, siteA); len(errs) != 0 {
+		t.Errorf("ApproveSites() with matching sites errors = %q, want none", errs)
+	}
+	errs := approve(path, siteA)
+	if len(errs) != 1 || !strings.Contains(errs[0], "\n-") || !strings.Contains(errs[0], "callertest.siteB") {
+		t.Errorf("ApproveSites() with a missing site errors = %q, want the site listed", errs)
+	}
+	errs = approve(path, siteA, siteB, func() caller.Caller { return caller.New(1) })
+	if len(errs) != 1 || !strings.Contains(errs[0], "\n+") {
+		t.Errorf("ApproveSites() with a new site errors = %q, want the site listed", errs)
+	}
+}
+
+// TestDiffSites tests listing the sites missing from either side.
+func TestDiffSites(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		want, got []string
+		diff      string
+	}{
+		{"same", []string{"a", "b"}, []string{"a", "b"}, ""},
+		{"added", []string{"a"}, []string{"a", "b"}, "+b\n"},
+		{"removed", []string{"a", "b"}, []string{"b"}, "-a\n"},
+		{"both", []string{"a"}, []string{"b"}, "-a\n+b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := diffSites(tt.want, tt.got); got != tt.diff {
+				t.Errorf("diffSites() = %q, want %q", got, tt.diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Package callertest provides helpers for tests of code that captures
+callers and stacks with package caller.
+
+Its comparison functions have the signatures expected by go-cmp's
+cmp.Comparer, so that table tests can diff structures containing callers
+and stacks, whose implementations have unexported fields, without
+depending on those fields:
+
+	opts := cmp.Options{
+		cmp.Comparer(callertest.EqualWithinLines(2)),
+		cmp.Comparer(callertest.EqualStacks),
+	}
+	if diff := cmp.Diff(want, got, opts); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+They compare the semantic content of callers, the file, line and full
+function name, and are symmetric and deterministic as cmp.Comparer
+requires. The package itself does not depend on go-cmp.
+
+ApproveSites compares the call sites captured during a test with a
+committed baseline file, for approval tests of instrumentation.
+*/
+package callertest
+
+import caller "github.com/balinomad/go-caller/v2"
+
+// Equal reports whether a and b have the same file, line and full
+// function name. Unlike Caller.Equal, it treats two nil callers as equal,
+// as cmp.Comparer requires.
+func Equal(a, b caller.Caller) bool {
+	return caller.EqualCallers(a, b)
+}
+
+// EqualWithinLines returns a comparison function that reports whether a
+// and b have the same file and full function name, and lines at most n
+// apart, for tests that should not break when code shifts by a few lines.
+// Two nil callers are equal. A negative n is treated as 0.
+func EqualWithinLines(n int) func(a, b caller.Caller) bool {
+	n = max(n, 0)
+	return func(a, b caller.Caller) bool {
+		ka, kb := caller.KeyOf(a), caller.KeyOf(b)
+		d := ka.Line - kb.Line
+		return ka.File == kb.File && ka.Function == kb.Function && -n <= d && d <= n
+	}
+}
+
+// EqualStacks reports whether a and b have equal frames, as compared by
+// Equal, ignoring the program counters, goroutine and build metadata the
+// stacks carry. A nil stack equals an empty one.
+func EqualStacks(a, b *caller.Stack) bool {
+	return equalFrames(a, b, Equal)
+}
+
+// EqualStacksWithinLines returns a comparison function that reports
+// whether a and b have frames equal as compared by EqualWithinLines(n),
+// ignoring the same metadata as EqualStacks.
+func EqualStacksWithinLines(n int) func(a, b *caller.Stack) bool {
+	eq := EqualWithinLines(n)
+	return func(a, b *caller.Stack) bool {
+		return equalFrames(a, b, eq)
+	}
+}
+
+// equalFrames reports whether a and b have the same number of frames,
+// pairwise equal by eq.
+func equalFrames(a, b *caller.Stack, eq func(a, b caller.Caller) bool) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+	for i := range a.Len() {
+		if !eq(a.Frame(i), b.Frame(i)) {
+			return false
+		}
+	}
+	return true
+}
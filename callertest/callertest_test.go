@@ -0,0 +1,86 @@
+package callertest
+
+import (
+	"testing"
+
+	caller "github.com/balinomad/go-caller/v2"
+)
+
+// capture returns the caller of capture, so that tests can take two
+// captures on different lines of the same function.
+func capture() caller.Caller {
+	return caller.New(0)
+}
+
+// stack returns the stack starting at the caller of stack.
+func stack() *caller.Stack {
+	return caller.NewStack(0)
+}
+
+// TestEqual tests comparing callers exactly and within a line tolerance.
+func TestEqual(t *testing.T) {
+	t.Parallel()
+
+	a := capture()
+	b := capture()
+	c := capture()
+	other := func() caller.Caller { return capture() }()
+
+	tests := []struct {
+		name string
+		eq   func(a, b caller.Caller) bool
+		a, b caller.Caller
+		want bool
+	}{
+		{"same", Equal, a, a, true},
+		{"next line", Equal, a, b, false},
+		{"both nil", Equal, nil, nil, true},
+		{"one nil", Equal, a, nil, false},
+		{"within one line", EqualWithinLines(1), a, b, true},
+		{"within one line, reversed", EqualWithinLines(1), b, a, true},
+		{"two lines apart", EqualWithinLines(1), a, c, false},
+		{"within two lines", EqualWithinLines(2), c, a, true},
+		{"negative tolerance", EqualWithinLines(-1), a, a, true},
+		{"other function", EqualWithinLines(100), a, other, false},
+		{"nil within lines", EqualWithinLines(1), nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.eq(tt.a, tt.b); got != tt.want {
+				t.Errorf("eq(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEqualStacks tests comparing stacks frame by frame.
+func TestEqualStacks(t *testing.T) {
+	t.Parallel()
+
+	a := stack()
+	b := stack()
+	short := func() *caller.Stack { return stack() }()
+
+	tests := []struct {
+		name string
+		eq   func(a, b *caller.Stack) bool
+		a, b *caller.Stack
+		want bool
+	}{
+		{"same", EqualStacks, a, a, true},
+		{"next line", EqualStacks, a, b, false},
+		{"within one line", EqualStacksWithinLines(1), a, b, true},
+		{"different depth", EqualStacksWithinLines(100), a, short, false},
+		{"nil and empty", EqualStacks, nil, &caller.Stack{}, true},
+		{"nil and captured", EqualStacks, nil, a, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.eq(tt.a, tt.b); got != tt.want {
+				t.Errorf("eq() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package caller
+
+// CallSite records where a value was constructed. Embed it in long-lived
+// types such as servers, clients and pools, and set it in their
+// constructors, so that a misconfigured value can later say where it came
+// from:
+//
+//	type Client struct {
+//		caller.CallSite
+//		addr string
+//	}
+//
+//	func NewClient(addr string) *Client {
+//		return &Client{CallSite: caller.CaptureSite(0), addr: addr}
+//	}
+//
+//	func (c *Client) Dial() error {
+//		if c.addr == "" {
+//			return fmt.Errorf("client created at %v has no address", c.ConstructionSite())
+//		}
+//		...
+//	}
+//
+// CallSite has no exported fields and no methods besides
+// ConstructionSite, so embedding it changes neither how the embedding
+// type is formatted, logged or encoded, nor its comparability. The zero
+// CallSite records no site.
+type CallSite struct {
+	site Caller
+}
+
+// CaptureSite returns a CallSite for the caller of the function calling
+// CaptureSite, typically the code calling a constructor. The skip
+// parameter counts further frames to skip, as for New, for constructors
+// called through helpers. If the caller cannot be determined, the
+// CallSite records what New returns in that case.
+func CaptureSite(skip int) CallSite {
+	if skip < 0 {
+		return CallSite{}
+	}
+	// Skip CaptureSite as well as the constructor calling it
+	return CallSite{site: New(skip + 1)}
+}
+
+// ConstructionSite returns the caller recorded by CaptureSite, or nil if
+// none was recorded.
+func (s CallSite) ConstructionSite() Caller {
+	return s.site
+}
@@ -0,0 +1,77 @@
+package caller
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// siteClient is a user type recording its construction site.
+type siteClient struct {
+	CallSite
+	Addr string
+}
+
+// newSiteClient is a constructor recording the site calling it.
+//
+//go:noinline
+func newSiteClient(addr string, skip int) *siteClient {
+	return &siteClient{CallSite: CaptureSite(skip), Addr: addr}
+}
+
+// newSiteClientVia calls newSiteClient through a helper frame.
+//
+//go:noinline
+func newSiteClientVia(addr string) *siteClient {
+	return newSiteClient(addr, 1)
+}
+
+// TestCaptureSite tests recording the caller of a constructor.
+func TestCaptureSite(t *testing.T) {
+	t.Parallel()
+
+	direct, line := newSiteClient("a", 0), Immediate().Line()
+	via, viaLine := newSiteClientVia("b"), Immediate().Line()
+
+	tests := []struct {
+		name string
+		c    *siteClient
+		line int
+	}{
+		{"direct", direct, line},
+		{"through helper", via, viaLine},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			site := tt.c.ConstructionSite()
+			if site == nil || site.Function() != "TestCaptureSite" || site.Line() != tt.line {
+				t.Errorf("ConstructionSite() = %v, want TestCaptureSite at line %d", site, tt.line)
+			}
+		})
+	}
+
+	if got := newSiteClient("c", -1).ConstructionSite(); got != nil {
+		t.Errorf("CaptureSite(-1) site = %v, want nil", got)
+	}
+	if got := (&siteClient{}).ConstructionSite(); got != nil {
+		t.Errorf("zero CallSite site = %v, want nil", got)
+	}
+}
+
+// TestCallSite_Embedded tests that embedding CallSite leaves the
+// formatting and encoding of the embedding type unchanged.
+func TestCallSite_Embedded(t *testing.T) {
+	t.Parallel()
+
+	c := newSiteClient("addr", 0)
+	if got := mustMarshal(t, c); got != `{"Addr":"addr"}` {
+		t.Errorf("json.Marshal() = %s, want only the embedding type's fields", got)
+	}
+	if got := fmt.Sprintf("%v", *c); !strings.Contains(got, "addr") || strings.Contains(got, "callsite_test.go") {
+		t.Errorf("Sprintf() = %q, want the embedding type's default format", got)
+	}
+	if _, ok := any(c).(fmt.Stringer); ok {
+		t.Error("embedding CallSite made the type a fmt.Stringer")
+	}
+}
@@ -0,0 +1,66 @@
+package caller
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// CompareCallers orders callers by file, then line, then full function
+// name, returning a negative number, zero or a positive number as a
+// sorts before, equal to or after b. It is consistent with EqualCallers,
+// and sorts a nil caller like the zero Key, before any other.
+func CompareCallers(a, b Caller) int {
+	return compareKeys(KeyOf(a), KeyOf(b))
+}
+
+// compareKeys orders keys as CompareCallers orders their callers.
+func compareKeys(a, b Key) int {
+	return cmp.Or(
+		strings.Compare(a.File, b.File),
+		cmp.Compare(a.Line, b.Line),
+		strings.Compare(a.Function, b.Function),
+	)
+}
+
+// Compact sorts cs with CompareCallers and removes nil callers and all
+// but the first of each run of callers equal by EqualCallers, for
+// aggregation pipelines that collect callers from many errors before
+// reporting the distinct call sites. Like slices.Compact, it modifies cs
+// in place, zeroes the elements between the new length and the original
+// length, and returns the shortened slice.
+func Compact(cs []Caller) []Caller {
+	cs = slices.DeleteFunc(cs, isNil)
+	slices.SortFunc(cs, CompareCallers)
+	return slices.CompactFunc(cs, EqualCallers)
+}
+
+// Unique returns an iterator over the callers of seq, leaving out nil
+// callers and any caller equal by EqualCallers to one already yielded,
+// so that the first occurrence of each call site is kept in its original
+// order:
+//
+//	for c := range caller.Unique(slices.Values(callers)) {
+//		...
+//	}
+//
+// The iterator remembers every distinct caller it yields.
+func Unique(seq iter.Seq[Caller]) iter.Seq[Caller] {
+	return func(yield func(Caller) bool) {
+		seen := make(map[Key]struct{})
+		for c := range seq {
+			if isNil(c) {
+				continue
+			}
+			k := KeyOf(c)
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
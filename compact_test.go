@@ -0,0 +1,93 @@
+package caller
+
+import (
+	"slices"
+	"testing"
+)
+
+// compactFixture holds callers for compaction tests.
+type compactFixture struct {
+	a1, a2, b, c Caller   // a1 and a2 are equal callers of different types
+	all          []Caller // Every caller out of order, with duplicates and nil values
+}
+
+// newCompactFixture returns a fresh compactFixture.
+func newCompactFixture() compactFixture {
+	f := compactFixture{
+		a1: &callerInfo{file: "/src/a.go", line: 1, fn: "app.A", dotIdx: 3},
+		a2: &mockCaller{file: "/src/a.go", line: 1, fn: "A", fullFn: "app.A"},
+		b:  &callerInfo{file: "/src/a.go", line: 2, fn: "app.B", dotIdx: 3},
+		c:  &callerInfo{file: "/src/b.go", line: 1, fn: "app.C", dotIdx: 3},
+	}
+	f.all = []Caller{f.c, nil, f.a1, f.b, (*callerInfo)(nil), f.a2, f.c}
+	return f
+}
+
+// TestCompareCallers tests ordering by file, line and function.
+func TestCompareCallers(t *testing.T) {
+	t.Parallel()
+
+	f := newCompactFixture()
+	a1, a2, b, c := f.a1, f.a2, f.b, f.c
+	tests := []struct {
+		name string
+		a, b Caller
+		want int
+	}{
+		{"equal across implementations", a1, a2, 0},
+		{"by line", a1, b, -1},
+		{"by file", c, b, 1},
+		{"by function", a1, &callerInfo{file: "/src/a.go", line: 1, fn: "app.Z", dotIdx: 3}, -1},
+		{"nil first", nil, a1, -1},
+		{"both nil", nil, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := CompareCallers(tt.a, tt.b); got != tt.want {
+				t.Errorf("CompareCallers() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCompact tests sorting and deduplicating in place.
+func TestCompact(t *testing.T) {
+	t.Parallel()
+
+	f := newCompactFixture()
+	a1, b, c, all := f.a1, f.b, f.c, f.all
+	got := Compact(all)
+	if want := []Caller{a1, b, c}; !slices.EqualFunc(got, want, EqualCallers) {
+		t.Errorf("Compact() = %v, want %v", got, want)
+	}
+	for i, x := range all[len(got):] {
+		if x != nil {
+			t.Errorf("Compact() left element %d = %v, want it zeroed", len(got)+i, x)
+		}
+	}
+	if got := Compact(nil); len(got) != 0 {
+		t.Errorf("Compact(nil) = %v, want empty", got)
+	}
+}
+
+// TestUnique tests keeping first occurrences in order.
+func TestUnique(t *testing.T) {
+	t.Parallel()
+
+	f := newCompactFixture()
+	a1, b, c, all := f.a1, f.b, f.c, f.all
+	got := slices.Collect(Unique(slices.Values(all)))
+	if want := []Caller{c, a1, b}; !slices.Equal(got, want) {
+		t.Errorf("Unique() = %v, want %v", got, want)
+	}
+
+	var first []Caller
+	for x := range Unique(slices.Values(all)) {
+		first = append(first, x)
+		break
+	}
+	if len(first) != 1 || first[0] != c {
+		t.Errorf("Unique() with early break = %v, want [%v]", first, c)
+	}
+}
@@ -0,0 +1,177 @@
+package caller
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// CompareOption configures how Equivalent and Normalize treat callers.
+type CompareOption func(*compareConfig)
+
+// compareConfig holds the settings applied by CompareOption values.
+type compareConfig struct {
+	portable bool // Compare module-relative paths instead of absolute ones
+	foldCase bool // Compare file paths case-insensitively, with forward slashes
+	symlinks bool // Compare file paths with symbolic links resolved
+}
+
+// IgnoreMachinePaths makes comparisons use PortableFile instead of the
+// captured file path, so that callers captured on hosts with different
+// checkout locations, GOPATHs or build sandboxes still match.
+func IgnoreMachinePaths() CompareOption {
+	return func(cfg *compareConfig) {
+		cfg.portable = true
+	}
+}
+
+// IgnoreFileCase makes comparisons treat file paths case-insensitively
+// and with backslashes read as forward slashes, as paths captured on
+// Windows and on default macOS filesystems refer to the same file
+// regardless of case. Normalize lowercases the file path accordingly, so
+// that KeyOf and HashCaller of normalized callers fingerprint them
+// consistently across filesystems.
+func IgnoreFileCase() CompareOption {
+	return func(cfg *compareConfig) {
+		cfg.foldCase = true
+	}
+}
+
+// Equal reports whether a and b are the same caller. Unlike the Equal
+// method of Caller, it can be called with nil callers, with these
+// semantics:
+//
+//	a        b        Equal(a, b)
+//	nil      nil      true
+//	nil      non-nil  false
+//	invalid  any      false
+//	non-nil  non-nil  same file, line and full function name
+//
+// An invalid caller, one that is not nil but not Valid, such as Invalid(),
+// is equal to nothing, including itself. A typed nil, such as a nil *T
+// stored in a Caller, counts as nil. By contrast, the Equal method is
+// false whenever either side is nil, EqualCallers also equates a nil
+// caller with one that has no file, line or function, and Equivalent is
+// false for any nil or invalid caller.
+func Equal(a, b Caller) bool {
+	if aNil, bNil := isNil(a), isNil(b); aNil || bNil {
+		return aNil == bNil
+	}
+	if !a.Valid() || !b.Valid() {
+		return false
+	}
+	return KeyOf(a) == KeyOf(b)
+}
+
+// Equivalent reports whether a and b refer to the same call site, comparing
+// file, line and full function name as adjusted by opts.
+// With no options it agrees with Equal on non-nil callers, invalid ones
+// included, which are equivalent to nothing. Unlike Equal, a nil Caller
+// is never equivalent to anything either, including another nil Caller.
+func Equivalent(a, b Caller, opts ...CompareOption) bool {
+	if isNil(a) || isNil(b) || !a.Valid() || !b.Valid() {
+		return false
+	}
+	cfg := newCompareConfig(opts)
+	return cfg.file(a) == cfg.file(b) &&
+		a.Line() == b.Line() &&
+		a.FullFunction() == b.FullFunction()
+}
+
+// Normalize returns a copy of c with its file path rewritten as described
+// by opts, suitable for storing or exchanging across machines.
+// It returns nil if c is nil.
+func Normalize(c Caller, opts ...CompareOption) Caller {
+	if isNil(c) {
+		return nil
+	}
+	cfg := newCompareConfig(opts)
+	fn := c.FullFunction()
+	n := &callerInfo{
+		file:   cfg.file(c),
+		line:   c.Line(),
+		fn:     fn,
+		dotIdx: functionNameIndex(fn),
+	}
+	n.setFlag(flagInApp, InApp(c))
+	n.setFlag(flagOwnsFile, n.file != c.File())
+	return n
+}
+
+// PortableFile returns the file path of c relative to its module root,
+// spelled as the package import path followed by the file name
+// (for example "github.com/user/repo/pkg/file.go"). Unlike File, it does
+// not depend on where the source tree was located on the build machine.
+// If the package cannot be determined, it returns just the file name.
+//
+// Every main package has the import path "main", so files of package
+// main are placed under the path of the main module of the running
+// executable instead. Several main packages of one module, such as the
+// commands under its cmd directory, still share that path.
+func PortableFile(c Caller) string {
+	if isNil(c) || c.File() == "" {
+		return ""
+	}
+	base := filepath.Base(c.File())
+	if pkg := c.Package(); pkg != "" {
+		return resolveMain(pkg) + "/" + base
+	}
+	return base
+}
+
+// FunctionKey returns a stable grouping key for the function of c, made of
+// its PortableFile and full function name but deliberately not its line,
+// for metrics and alert grouping that must survive unrelated line shifts
+// between releases:
+//
+//	github.com/user/repo/pkg/file.go github.com/user/repo/pkg.(*Server).Handle
+//
+// It returns an empty string if c is nil or carries neither a file nor
+// a function.
+func FunctionKey(c Caller) string {
+	file, fn := PortableFile(c), FullFunction(c)
+	switch {
+	case file == "":
+		return fn
+	case fn == "":
+		return file
+	default:
+		return file + " " + fn
+	}
+}
+
+// newCompareConfig applies opts to a fresh compareConfig.
+func newCompareConfig(opts []CompareOption) compareConfig {
+	var cfg compareConfig
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	return cfg
+}
+
+// file returns the file path of c as it should be compared.
+func (cfg compareConfig) file(c Caller) string {
+	file := c.File()
+	if cfg.symlinks {
+		file = resolveFile(file)
+	}
+	if cfg.portable {
+		file = PortableFile(c)
+	}
+	if cfg.foldCase {
+		file = strings.ToLower(strings.ReplaceAll(file, `\`, "/"))
+	}
+	return file
+}
+
+// isNil reports whether c is a nil interface or an interface holding a
+// nil pointer, which other implementations may not handle gracefully.
+func isNil(c Caller) bool {
+	if c == nil {
+		return true
+	}
+	v := reflect.ValueOf(c)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
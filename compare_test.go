@@ -0,0 +1,176 @@
+package caller
+
+import "testing"
+
+// TestEquivalent tests Equivalent with and without IgnoreMachinePaths,
+// including nil interfaces and typed nil values.
+func TestEquivalent(t *testing.T) {
+	t.Parallel()
+
+	local := &callerInfo{file: "/home/dev/repo/pkg/x.go", line: 7, fn: "example.com/repo/pkg.F", dotIdx: functionNameIndex("example.com/repo/pkg.F")}
+	remote := &callerInfo{file: "/build/src/pkg/x.go", line: 7, fn: "example.com/repo/pkg.F", dotIdx: functionNameIndex("example.com/repo/pkg.F")}
+	trimmed := &callerInfo{file: "example.com/repo/pkg/x.go", line: 7, fn: "example.com/repo/pkg.F", dotIdx: functionNameIndex("example.com/repo/pkg.F")}
+	otherLine := &callerInfo{file: "/build/src/pkg/x.go", line: 8, fn: "example.com/repo/pkg.F", dotIdx: functionNameIndex("example.com/repo/pkg.F")}
+	otherFile := &callerInfo{file: "/build/src/pkg/y.go", line: 7, fn: "example.com/repo/pkg.F", dotIdx: functionNameIndex("example.com/repo/pkg.F")}
+	windows := &callerInfo{file: `C:\Users\dev\repo\x.go`, line: 3, fn: "main.main", dotIdx: 4}
+	windowsUpper := &callerInfo{file: `c:\USERS\Dev\Repo\X.GO`, line: 3, fn: "main.main", dotIdx: 4}
+	windowsSlashes := &callerInfo{file: "C:/Users/dev/repo/x.go", line: 3, fn: "main.main", dotIdx: 4}
+	windowsLine := &callerInfo{file: "c:/users/dev/repo/x.go", line: 4, fn: "main.main", dotIdx: 4}
+
+	tests := []struct {
+		name string
+		a, b Caller
+		opts []CompareOption
+		want bool
+	}{
+		{"nil interfaces", nil, nil, nil, false},
+		{"typed nil", (*callerInfo)(nil), local, nil, false},
+		{"invalid callers", Invalid(), Invalid(), nil, false},
+		{"invalid callers, portable", Invalid(), Invalid(), []CompareOption{IgnoreMachinePaths()}, false},
+		{"typed nil mock", (*mockCaller)(nil), local, nil, false},
+		{"same", local, local, nil, true},
+		{"different hosts, exact", local, remote, nil, false},
+		{"different hosts, portable", local, remote, []CompareOption{IgnoreMachinePaths()}, true},
+		{"trimpath build, portable", local, trimmed, []CompareOption{IgnoreMachinePaths()}, true},
+		{"different line, portable", local, otherLine, []CompareOption{IgnoreMachinePaths()}, false},
+		{"different file, portable", local, otherFile, []CompareOption{IgnoreMachinePaths()}, false},
+		{"nil option", local, local, []CompareOption{nil}, true},
+		{"different case, exact", windows, windowsUpper, nil, false},
+		{"different case, folded", windows, windowsUpper, []CompareOption{IgnoreFileCase()}, true},
+		{"separators, folded", windows, windowsSlashes, []CompareOption{IgnoreFileCase()}, true},
+		{"different line, folded", windows, windowsLine, []CompareOption{IgnoreFileCase()}, false},
+		{"different hosts and case", local, &callerInfo{file: "/BUILD/Src/PKG/X.go", line: 7, fn: "example.com/repo/pkg.F", dotIdx: functionNameIndex("example.com/repo/pkg.F")}, []CompareOption{IgnoreMachinePaths(), IgnoreFileCase()}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Equivalent(tt.a, tt.b, tt.opts...); got != tt.want {
+				t.Errorf("Equivalent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEqual tests Equal with nil interfaces, typed nil values and
+// different implementations of Caller.
+func TestEqual(t *testing.T) {
+	t.Parallel()
+
+	info := &callerInfo{file: "file.go", line: 10, fn: "pkg.Func", dotIdx: 3}
+	mock := &mockCaller{file: "file.go", line: 10, fn: "Func", fullFn: "pkg.Func"}
+	otherLine := &callerInfo{file: "file.go", line: 11, fn: "pkg.Func", dotIdx: 3}
+
+	tests := []struct {
+		name string
+		a, b Caller
+		want bool
+	}{
+		{"nil interfaces", nil, nil, true},
+		{"typed nil and nil", (*callerInfo)(nil), nil, true},
+		{"typed nils of different types", (*callerInfo)(nil), (*mockCaller)(nil), true},
+		{"nil and non-nil", nil, info, false},
+		{"typed nil and non-nil", (*mockCaller)(nil), info, false},
+		{"same", info, info, true},
+		{"different implementations", info, mock, true},
+		{"different line", info, otherLine, false},
+		{"invalid callers", Invalid(), Invalid(), false},
+		{"invalid and nil", Invalid(), nil, false},
+		{"invalid and valid", Invalid(), info, false},
+		{"empty callers", &callerInfo{}, &callerInfo{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Equal(tt.a, tt.b); got != tt.want {
+				t.Errorf("Equal(a, b) = %v, want %v", got, tt.want)
+			}
+			if got := Equal(tt.b, tt.a); got != tt.want {
+				t.Errorf("Equal(b, a) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNormalize tests that Normalize rewrites the file and keeps the rest.
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	c := &callerInfo{file: "/home/dev/repo/pkg/x.go", line: 7, fn: "example.com/repo/pkg.F", dotIdx: functionNameIndex("example.com/repo/pkg.F")}
+	n := Normalize(c, IgnoreMachinePaths())
+	if got, want := n.File(), "example.com/repo/pkg/x.go"; got != want {
+		t.Errorf("File() = %q, want %q", got, want)
+	}
+	if got, want := n.Function(), "F"; got != want {
+		t.Errorf("Function() = %q, want %q", got, want)
+	}
+	if got, want := n.Line(), 7; got != want {
+		t.Errorf("Line() = %d, want %d", got, want)
+	}
+	folded := Normalize(&callerInfo{file: `C:\Repo\Pkg\X.go`, line: 7, fn: "main.main", dotIdx: 4}, IgnoreFileCase())
+	if got, want := folded.File(), "c:/repo/pkg/x.go"; got != want {
+		t.Errorf("Normalize(IgnoreFileCase()).File() = %q, want %q", got, want)
+	}
+	if !Normalize(c).Equal(c) {
+		t.Error("Normalize() without options should return an equal caller")
+	}
+	if Normalize(nil) != nil {
+		t.Error("Normalize(nil) should return nil")
+	}
+}
+
+// TestPortableFile tests PortableFile across package layouts.
+func TestPortableFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		c    Caller
+		want string
+	}{
+		{"nil", nil, ""},
+		{"no file", &callerInfo{fn: "pkg.F", dotIdx: 3}, ""},
+		{"no package", &callerInfo{file: "/a/b/main.go", fn: "main", dotIdx: -1}, "main.go"},
+		{"main package", &callerInfo{file: "/a/b/main.go", fn: "main.main", dotIdx: 4}, thisPackage + "/main.go"},
+		{"module package", &callerInfo{file: "/a/b/x.go", fn: "example.com/m/b.F", dotIdx: functionNameIndex("example.com/m/b.F")}, "example.com/m/b/x.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := PortableFile(tt.c); got != tt.want {
+				t.Errorf("PortableFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFunctionKey tests that the key ignores lines and machine paths.
+func TestFunctionKey(t *testing.T) {
+	t.Parallel()
+
+	fn := "example.com/m/b.(*S).F"
+	dot := functionNameIndex(fn)
+	tests := []struct {
+		name string
+		c    Caller
+		want string
+	}{
+		{"nil", nil, ""},
+		{"function only", &callerInfo{fn: fn, dotIdx: dot}, fn},
+		{"file only", &callerInfo{file: "/a/b/main.go", dotIdx: -1}, "main.go"},
+		{"both", &callerInfo{file: "/a/b/x.go", line: 7, fn: fn, dotIdx: dot}, "example.com/m/b/x.go " + fn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := FunctionKey(tt.c); got != tt.want {
+				t.Errorf("FunctionKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	a := &callerInfo{file: "/home/a/src/x.go", line: 7, fn: fn, dotIdx: dot}
+	b := &callerInfo{file: "/ci/work/src/x.go", line: 93, fn: fn, dotIdx: dot}
+	if FunctionKey(a) != FunctionKey(b) {
+		t.Errorf("FunctionKey() = %q and %q, want equal across lines and hosts", FunctionKey(a), FunctionKey(b))
+	}
+}
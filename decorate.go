@@ -0,0 +1,87 @@
+package caller
+
+import "slices"
+
+// FrameInfo is the information of a captured frame passed to decorators
+// given with WithDecorator, which may modify it.
+type FrameInfo struct {
+	File     string   // File path, after any FileMapper
+	Line     int      // Line number
+	Function string   // Full function name including package
+	Labels   []string // Labels attached to the frame, read back with Labels
+	Hidden   bool     // Whether to leave the frame out of the capture
+	InApp    bool     // Whether the frame is application code, as classified by WithInAppRules
+}
+
+// WithDecorator makes a capture run fn on every frame it resolves, after
+// any FileMapper and before the capture is returned, so that an
+// application can rewrite paths, hide internal frames or attach labels
+// at a single point rather than wherever callers are rendered:
+//
+//	var hideLog = caller.WithDecorator(func(f *caller.FrameInfo) {
+//		if strings.HasPrefix(f.Function, "example.com/app/internal/log.") {
+//			f.Hidden = true
+//		}
+//	})
+//
+//	c := caller.NewWith(0, hideLog)
+//
+// Hidden frames are left out of stacks, and NewWith moves on to the next
+// frame. Decorators run in the order given on the capturing goroutine.
+// Only the captures that take options run them: NewWith, NewStack,
+// CaptureStack, RawStack.Stack and the call sites found by a Reporter.
+// New, Immediate, NewFromPC, Callers and the other captures without
+// options are never decorated.
+func WithDecorator(fn func(*FrameInfo)) Option {
+	return func(cfg *captureConfig) {
+		if fn != nil {
+			cfg.decorators = append(cfg.decorators, fn)
+		}
+	}
+}
+
+// decorate classifies c by the in-app rules of cfg and runs its
+// decorators on c.
+func (cfg captureConfig) decorate(c *callerInfo) {
+	if cfg.inApp != nil {
+		c.setFlag(flagInApp, cfg.inApp.match(c.file, c.fn))
+	}
+	if len(cfg.decorators) == 0 {
+		return
+	}
+	f := FrameInfo{File: c.file, Line: c.line, Function: c.fn, InApp: c.has(flagInApp)}
+	for _, fn := range cfg.decorators {
+		fn(&f)
+	}
+	if f.File != c.file {
+		c.file = f.File
+		c.flags |= flagOwnsFile
+	}
+	c.line = f.Line
+	c.setFlag(flagInApp, f.InApp)
+	if f.Function != c.fn {
+		c.fn = f.Function
+		c.dotIdx = functionNameIndex(f.Function)
+		c.flags |= flagOwnsFunc
+	}
+	if len(f.Labels) > 0 {
+		c.extras().labels = slices.Clip(f.Labels)
+	}
+	c.setFlag(flagHidden, f.Hidden)
+}
+
+// hidden reports whether a decorator hid c.
+func (c *callerInfo) hidden() bool {
+	return c.has(flagHidden)
+}
+
+// Labels returns the labels attached to c by decorators, or nil if there
+// are none or c is nil. The returned slice is a copy and may be modified
+// freely.
+func Labels(c Caller) []string {
+	ci, ok := c.(*callerInfo)
+	if !ok || ci == nil || ci.extra == nil {
+		return nil
+	}
+	return slices.Clone(ci.extra.labels)
+}
@@ -0,0 +1,68 @@
+package caller
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// decoratedNewWith captures its caller with NewWith and opts.
+func decoratedNewWith(opts ...Option) Caller {
+	return NewWith(0, opts...)
+}
+
+// decoratedStack captures a stack whose first frame is its caller.
+func decoratedStack(opts ...Option) *Stack {
+	return NewStack(0, append(opts, KeepAllFrames())...)
+}
+
+// TestWithDecorator tests that decorators rewrite, label and hide frames
+// of the captures they are given to, in order, and of no others.
+func TestWithDecorator(t *testing.T) {
+	t.Parallel()
+
+	hide := WithDecorator(func(f *FrameInfo) {
+		if strings.HasSuffix(f.Function, ".TestWithDecorator") {
+			f.Hidden = true
+		}
+	})
+	label := WithDecorator(func(f *FrameInfo) {
+		f.File = "rewritten/" + f.File
+		f.Labels = append(f.Labels, "seen")
+		if f.Hidden {
+			f.Labels = append(f.Labels, "hidden")
+		}
+	})
+
+	c := decoratedNewWith(label, WithDecorator(nil))
+	if c.Function() != "TestWithDecorator" || !strings.HasPrefix(c.File(), "rewritten/") {
+		t.Errorf("NewWith(0) = %v, want TestWithDecorator with a rewritten path", c)
+	}
+	if got, want := Labels(c), []string{"seen"}; !slices.Equal(got, want) {
+		t.Errorf("Labels() = %v, want %v", got, want)
+	}
+	if got := Labels(decoratedNewWith(label, WithRecapture())); !slices.Equal(got, []string{"seen"}) {
+		t.Errorf("Labels() with WithRecapture = %v, want [seen]", got)
+	}
+
+	if got, want := decoratedNewWith(hide, label).FullFunction(), "testing.tRunner"; got != want {
+		t.Errorf("NewWith(0).FullFunction() = %q, want %q past the hidden frame", got, want)
+	}
+	if got, want := decoratedStack(hide).Caller0().FullFunction(), "testing.tRunner"; got != want {
+		t.Errorf("NewStack(0).Caller0().FullFunction() = %q, want %q past the hidden frame", got, want)
+	}
+
+	// Captures without the option are left alone
+	if got := Labels(decoratedNewWith()); got != nil {
+		t.Errorf("Labels() without decorators = %v, want nil", got)
+	}
+	if got := decoratedNewWith().Function(); got != "TestWithDecorator" {
+		t.Errorf("NewWith(0).Function() without decorators = %q, want TestWithDecorator", got)
+	}
+	if got := Labels(Immediate()); got != nil {
+		t.Errorf("Labels(Immediate()) = %v, want nil", got)
+	}
+	if got := Labels(nil); got != nil {
+		t.Errorf("Labels(nil) = %v, want nil", got)
+	}
+}
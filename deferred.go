@@ -0,0 +1,87 @@
+package caller
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// Deferred records the program counter of the caller, with the same skip
+// semantics as New, and returns a function that resolves it into a
+// Caller only when first called, for error paths that hand a
+// capture-if-needed thunk to lower layers which decide whether the cost
+// of resolving is warranted:
+//
+//	site := caller.Deferred(0)
+//	if err := store.Save(ctx, v); err != nil {
+//		return wrap(err, site) // calls site() only if it reports the error
+//	}
+//
+// Recording costs one stack walk of a single frame; resolving the file,
+// line and function name is left to the returned function, which returns
+// the same Caller on every call and is safe for concurrent use. Capture
+// hooks and the Recorder see the Caller when it is resolved. The function
+// returns nil if the skip is invalid or the caller cannot be determined.
+func Deferred(skip int) func() Caller {
+	if skip < 0 {
+		return noCaller
+	}
+	// Skip runtime.Callers, Deferred, and the function calling Deferred
+	var pcs [1]uintptr
+	if runtime.Callers(skip+skipAdjust+1, pcs[:]) == 0 {
+		return noCaller
+	}
+	d := &deferredCaller{pc: pcs[0] - 1}
+	return d.resolve
+}
+
+// noCaller is the function returned by Deferred when the caller cannot
+// be determined.
+func noCaller() Caller { return nil }
+
+// deferredCaller is a caller recorded by Deferred and resolved on first
+// use.
+type deferredCaller struct {
+	pc   uintptr // Call-site program counter
+	once sync.Once
+	c    Caller
+}
+
+// resolve returns the Caller for d.pc, resolving it on the first call.
+func (d *deferredCaller) resolve() Caller {
+	d.once.Do(func() {
+		d.c = NewFromPC(d.pc)
+	})
+	return d.c
+}
+
+// LazyLocation returns a fmt.Stringer for the location of the caller,
+// with the same skip semantics as New, that records only the program
+// counter now and resolves and formats the location, as ShortLocation
+// does, only when its String method is called. Printf-style loggers with
+// level filtering thus pay nothing for formatting in suppressed messages:
+//
+//	log.Debugf("cache miss at %s", caller.LazyLocation(0))
+//
+// String returns an empty string if the skip is invalid or the caller
+// cannot be determined.
+func LazyLocation(skip int) fmt.Stringer {
+	if skip < 0 {
+		return lazyLocation{site: noCaller}
+	}
+	return lazyLocation{site: Deferred(skip + 1)}
+}
+
+// lazyLocation is the fmt.Stringer returned by LazyLocation.
+type lazyLocation struct {
+	site func() Caller
+}
+
+// String returns the short location of the recorded caller.
+func (l lazyLocation) String() string {
+	c := l.site()
+	if isNil(c) {
+		return ""
+	}
+	return c.ShortLocation()
+}
@@ -0,0 +1,87 @@
+package caller
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// deferredHelper returns a Deferred thunk for its caller.
+//
+//go:noinline
+func deferredHelper(skip int) func() Caller {
+	return Deferred(skip)
+}
+
+// TestDeferred tests that the thunk resolves the caller it recorded, once.
+func TestDeferred(t *testing.T) {
+	t.Parallel()
+
+	site, line := deferredHelper(0), Immediate().Line()
+	c := site()
+	if c == nil || c.Function() != "TestDeferred" || c.Line() != line {
+		t.Fatalf("Deferred(0)() = %v, want TestDeferred at line %d", c, line)
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := site(); got != c {
+				t.Errorf("Deferred(0)() = %p, want the first result %p", got, c)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := deferredHelper(-1)(); got != nil {
+		t.Errorf("Deferred(-1)() = %v, want nil", got)
+	}
+	if got := deferredHelper(1 << 20)(); got != nil {
+		t.Errorf("Deferred(1<<20)() = %v, want nil", got)
+	}
+}
+
+// BenchmarkDeferred measures recording a caller without resolving it.
+func BenchmarkDeferred(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		_ = Deferred(0)
+	}
+}
+
+// lazyLocationHelper returns a LazyLocation for its caller.
+//
+//go:noinline
+func lazyLocationHelper(skip int) fmt.Stringer {
+	return LazyLocation(skip)
+}
+
+// TestLazyLocation tests that the location formats as ShortLocation.
+func TestLazyLocation(t *testing.T) {
+	t.Parallel()
+
+	loc, want := lazyLocationHelper(0), Immediate().ShortLocation()
+	if got := loc.String(); got != want {
+		t.Errorf("LazyLocation(0).String() = %q, want %q", got, want)
+	}
+	if got := fmt.Sprintf("at %s", loc); got != "at "+want {
+		t.Errorf("Sprintf() = %q, want %q", got, "at "+want)
+	}
+
+	for _, skip := range []int{-1, 1 << 20} {
+		if got := lazyLocationHelper(skip).String(); got != "" {
+			t.Errorf("LazyLocation(%d).String() = %q, want empty", skip, got)
+		}
+	}
+}
+
+// BenchmarkLazyLocation measures a suppressed debug message, which
+// records the caller but never formats it.
+func BenchmarkLazyLocation(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		_ = LazyLocation(0)
+	}
+}
@@ -0,0 +1,80 @@
+package caller
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrNotFunc is returned by DefinitionSite for a value that is not a
+	// function, or for a nil function.
+	ErrNotFunc = errors.New("not a non-nil function")
+
+	// ErrNoDefinition is returned when a function's definition site cannot
+	// be resolved, such as for compiler-generated wrappers.
+	ErrNoDefinition = errors.New("definition site not available")
+
+	// ErrNoMethod is returned by MethodSite when the type has no exported
+	// method with the requested name.
+	ErrNoMethod = errors.New("method not found")
+)
+
+// autogeneratedFile is the file name the runtime reports for
+// compiler-generated functions.
+const autogeneratedFile = "<autogenerated>"
+
+// DefinitionSite returns the location where the function value fn is
+// defined: the line of its func declaration or function literal. It lets
+// frameworks log which handler or callback was registered, not just where
+// the registration happened.
+//
+// Method values such as t.Method are compiled into generated wrappers
+// with no source position; DefinitionSite reports ErrNoDefinition for
+// them. Pass a method expression (T.Method) instead, or use MethodSite.
+func DefinitionSite(fn any) (Caller, error) {
+	f, err := FuncOfValue(fn)
+	if err != nil {
+		return nil, err
+	}
+	return f.DefinitionSite()
+}
+
+// MethodSite returns the location where the named method of receiver's
+// type is defined, for router and dependency-injection frameworks that
+// print registration tables. The receiver may be a value, a pointer, or a
+// reflect.Type; methods declared on either T or *T are found regardless
+// of which of the two is passed. Only exported methods of concrete types
+// can be resolved.
+func MethodSite(receiver any, method string) (Caller, error) {
+	t, ok := receiver.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(receiver)
+	}
+	if t == nil {
+		return nil, fmt.Errorf("%w: %s on nil receiver", ErrNoMethod, method)
+	}
+
+	// Look on the base type first: asking *T for a method declared on T
+	// yields a generated wrapper rather than the method itself
+	candidates := []reflect.Type{t, reflect.PointerTo(t)}
+	if t.Kind() == reflect.Pointer {
+		candidates = []reflect.Type{t.Elem(), t}
+	}
+	for _, ct := range candidates {
+		if m, ok := ct.MethodByName(method); ok && m.Func.IsValid() {
+			return definitionAt(m.Func.Pointer())
+		}
+	}
+	return nil, fmt.Errorf("%w: %s.%s", ErrNoMethod, t, method)
+}
+
+// definitionAt resolves the definition site of the function whose entry
+// point is pc.
+func definitionAt(pc uintptr) (Caller, error) {
+	f, ok := FuncOf(pc)
+	if !ok {
+		return nil, fmt.Errorf("%w: no function at %#x", ErrNoDefinition, pc)
+	}
+	return f.DefinitionSite()
+}
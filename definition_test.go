@@ -0,0 +1,171 @@
+package caller
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// definitionTarget is a function with a known definition line.
+func definitionTarget() {}
+
+// definitionType has methods with value and pointer receivers.
+type definitionType struct{}
+
+func (definitionType) Value()    {}
+func (*definitionType) Pointer() {}
+
+// declLine returns the line of this file on which decl appears.
+func declLine(t *testing.T, decl string) int {
+	t.Helper()
+	_, file, _, _ := runtime.Caller(0)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, decl) {
+			return i + 1
+		}
+	}
+	t.Fatalf("declaration %q not found", decl)
+	return 0
+}
+
+// TestDefinitionSite tests DefinitionSite for functions, closures and
+// method expressions, and its errors.
+func TestDefinitionSite(t *testing.T) {
+	t.Parallel()
+
+	_, file, here, _ := runtime.Caller(0)
+	closure := func() {}
+	valueLine := declLine(t, "func (definitionType) Value(")
+	pointerLine := declLine(t, "func (*definitionType) Pointer(")
+
+	tests := []struct {
+		name     string
+		fn       any
+		wantFunc string
+		wantLine int
+	}{
+		{"function", definitionTarget, "definitionTarget", declLine(t, "func definitionTarget(")},
+		{"closure", closure, "TestDefinitionSite.func1", here + 1},
+		{"value method expression", definitionType.Value, "definitionType.Value", valueLine},
+		{"pointer method expression", (*definitionType).Pointer, "(*definitionType).Pointer", pointerLine},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := DefinitionSite(tt.fn)
+			if err != nil {
+				t.Fatalf("DefinitionSite() error = %v", err)
+			}
+			if got := c.Function(); got != tt.wantFunc {
+				t.Errorf("Function() = %q, want %q", got, tt.wantFunc)
+			}
+			if c.File() != file || c.Line() != tt.wantLine {
+				t.Errorf("Location() = %q, want %s:%d", c.Location(), file, tt.wantLine)
+			}
+		})
+	}
+}
+
+// TestDefinitionSite_Errors tests non-functions, nil functions and
+// method values.
+func TestDefinitionSite_Errors(t *testing.T) {
+	t.Parallel()
+
+	var nilFunc func()
+	var v definitionType
+	tests := []struct {
+		name string
+		fn   any
+		want error
+	}{
+		{"nil", nil, ErrNotFunc},
+		{"not a function", 42, ErrNotFunc},
+		{"nil function", nilFunc, ErrNotFunc},
+		{"method value", v.Value, ErrNoDefinition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := DefinitionSite(tt.fn)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("DefinitionSite() error = %v, want %v", err, tt.want)
+			}
+			if c != nil {
+				t.Errorf("DefinitionSite() = %v, want nil", c)
+			}
+		})
+	}
+
+	if _, err := definitionAt(0); !errors.Is(err, ErrNoDefinition) {
+		t.Errorf("definitionAt(0) error = %v, want %v", err, ErrNoDefinition)
+	}
+}
+
+// TestMethodSite tests resolving methods from values, pointers and types.
+func TestMethodSite(t *testing.T) {
+	t.Parallel()
+
+	var v definitionType
+	valueLine := declLine(t, "func (definitionType) Value(")
+	pointerLine := declLine(t, "func (*definitionType) Pointer(")
+	tests := []struct {
+		name     string
+		receiver any
+		method   string
+		wantFunc string
+		wantLine int
+	}{
+		{"value receiver on value", v, "Value", "definitionType.Value", valueLine},
+		{"value receiver on pointer", &v, "Value", "definitionType.Value", valueLine},
+		{"pointer receiver on value", v, "Pointer", "(*definitionType).Pointer", pointerLine},
+		{"pointer receiver on pointer", &v, "Pointer", "(*definitionType).Pointer", pointerLine},
+		{"reflect.Type", reflect.TypeOf(v), "Value", "definitionType.Value", valueLine},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := MethodSite(tt.receiver, tt.method)
+			if err != nil {
+				t.Fatalf("MethodSite() error = %v", err)
+			}
+			if got := c.Function(); got != tt.wantFunc {
+				t.Errorf("Function() = %q, want %q", got, tt.wantFunc)
+			}
+			if got := c.Line(); got != tt.wantLine {
+				t.Errorf("Line() = %d, want %d", got, tt.wantLine)
+			}
+		})
+	}
+}
+
+// TestMethodSite_Errors tests unknown methods, nil receivers and
+// interface types.
+func TestMethodSite_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		receiver any
+		method   string
+	}{
+		{"nil receiver", nil, "Value"},
+		{"unknown method", definitionType{}, "Missing"},
+		{"unexported method", definitionType{}, "value"},
+		{"interface type", reflect.TypeOf((*Caller)(nil)).Elem(), "File"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := MethodSite(tt.receiver, tt.method); !errors.Is(err, ErrNoMethod) {
+				t.Errorf("MethodSite() error = %v, want %v", err, ErrNoMethod)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package caller
+
+import "sync/atomic"
+
+// Deployment identifies the service a process belongs to, so that every
+// exported stack can be attributed without passing it to each capture.
+type Deployment struct {
+	Service     string `json:"service,omitempty"`     // Service name, such as "checkout"
+	Version     string `json:"version,omitempty"`     // Service version or release, such as "v1.4.2"
+	Environment string `json:"environment,omitempty"` // Deployment environment, such as "production"
+}
+
+// deployment holds the Deployment set by SetDeployment, if any. The value
+// pointed to is shared by captured stacks and never modified.
+var deployment atomic.Pointer[Deployment]
+
+// SetDeployment registers the deployment metadata of the process,
+// package-wide. It is meant to be called once at startup:
+//
+//	caller.SetDeployment(caller.Deployment{
+//		Service:     "checkout",
+//		Version:     version,
+//		Environment: os.Getenv("APP_ENV"),
+//	})
+//
+// Stacks captured afterwards record it and include it in their JSON under
+// "deployment", as do the stacks of PanicError values. Passing the zero
+// Deployment removes it.
+func SetDeployment(d Deployment) {
+	if d == (Deployment{}) {
+		deployment.Store(nil)
+		return
+	}
+	deployment.Store(&d)
+}
+
+// CurrentDeployment returns the deployment metadata set by SetDeployment,
+// and reports whether any is set.
+func CurrentDeployment() (Deployment, bool) {
+	d := deployment.Load()
+	if d == nil {
+		return Deployment{}, false
+	}
+	return *d, true
+}
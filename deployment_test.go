@@ -0,0 +1,52 @@
+package caller
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestSetDeployment tests that deployment metadata is recorded by stacks
+// captured after it is set, survives a JSON round trip, and can be removed.
+func TestSetDeployment(t *testing.T) {
+	restoreGlobal(t, &deployment)
+
+	before := stackHelper(0)
+	d := Deployment{Service: "checkout", Version: "v1.4.2", Environment: "production"}
+	SetDeployment(d)
+
+	if got, ok := CurrentDeployment(); !ok || got != d {
+		t.Errorf("CurrentDeployment() = %+v, %v, want %+v", got, ok, d)
+	}
+	if _, ok := before.Deployment(); ok {
+		t.Error("Deployment() of a stack captured before SetDeployment reported true")
+	}
+
+	s := stackHelper(0)
+	if got, ok := s.Deployment(); !ok || got != d {
+		t.Errorf("Deployment() = %+v, %v, want %+v", got, ok, d)
+	}
+
+	data := mustMarshal(t, s)
+	if !strings.Contains(data, `"deployment":{"service":"checkout","version":"v1.4.2","environment":"production"}`) {
+		t.Errorf("MarshalJSON() = %.300s, want a deployment object", data)
+	}
+	var decoded Stack
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got, ok := decoded.Deployment(); !ok || got != d {
+		t.Errorf("decoded Deployment() = %+v, %v, want %+v", got, ok, d)
+	}
+
+	SetDeployment(Deployment{})
+	if _, ok := CurrentDeployment(); ok {
+		t.Error("CurrentDeployment() reported true after setting the zero Deployment")
+	}
+	if data := mustMarshal(t, stackHelper(0)); strings.Contains(data, `"deployment"`) {
+		t.Errorf("MarshalJSON() = %.300s, want no deployment object", data)
+	}
+	if _, ok := (*Stack)(nil).Deployment(); ok {
+		t.Error("Deployment() of a nil Stack reported true")
+	}
+}
@@ -0,0 +1,129 @@
+package caller
+
+import (
+	"log/slog"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Deprecation describes a call to a deprecated API, as reported by
+// Deprecated.
+type Deprecation struct {
+	Message    string // Message passed to Deprecated
+	API        Caller // Deprecated function that called Deprecated
+	CalledFrom Caller // First caller outside the package of API
+}
+
+// deprecationSink holds the function set by SetDeprecationSink, or nil
+// for the default.
+var deprecationSink atomic.Pointer[func(Deprecation)]
+
+// deprecations holds the call sites Deprecated has reported, keyed by
+// deprecationKey.
+var deprecations sync.Map
+
+// deprecatedSites records, per program counter of a call to a deprecated
+// function, what Deprecated found out about the call site, so that calls
+// from a site already reported return without walking the stack.
+var deprecatedSites siteMap[atomic.Int32]
+
+// States of a call site in deprecatedSites.
+const (
+	siteUnresolved int32 = iota // Not seen yet
+	siteReported                // Outside the package, and reported
+	siteInternal                // Inside the package, so the reported site lies further up
+)
+
+// deprecationKey identifies a deprecated API called from a call site.
+type deprecationKey struct {
+	api  string
+	site Key
+}
+
+// SetDeprecationSink installs fn to receive the warnings reported by
+// Deprecated, in place of the default, which logs them with slog at
+// warning level. It must be safe for concurrent use.
+// Passing nil restores the default.
+func SetDeprecationSink(fn func(Deprecation)) {
+	if fn == nil {
+		deprecationSink.Store(nil)
+		return
+	}
+	deprecationSink.Store(&fn)
+}
+
+// CallerOutside returns the first caller outside the package of the
+// function skip frames above the caller of CallerOutside: with 0, it is
+// the first caller of the calling function's package from elsewhere,
+// which library code uses to find out which of its users called it.
+// It returns nil if skip is negative or there is no such caller.
+func CallerOutside(skip int) Caller {
+	if skip < 0 {
+		return nil
+	}
+	_, outside := externalCaller(skip)
+	if outside == nil {
+		return nil
+	}
+	return captured(outside, "")
+}
+
+// Deprecated reports that the function calling it is deprecated, at most
+// once for each distinct call site outside its package, so library
+// authors can sunset an API with actionable warnings:
+//
+//	func (c *Client) Fetch(url string) error {
+//		caller.Deprecated("Client.Fetch is deprecated, use Client.Get")
+//		...
+//	}
+//
+// logs the message once per place in user code that calls Fetch, with
+// that location attached. Warnings go to the sink installed with
+// SetDeprecationSink, or to slog by default. Calls from within the
+// package of the deprecated function are not reported.
+func Deprecated(msg string) {
+	// Skip Deprecated and the deprecated function to reach the call site
+	pc := callSitePC(2)
+	if pc == 0 {
+		return
+	}
+	state := deprecatedSites.get(pc)
+	if state.Load() == siteReported {
+		return
+	}
+
+	api, outside := externalCaller(1)
+	if api == nil || outside == nil {
+		return
+	}
+	key := deprecationKey{api: api.FullFunction(), site: KeyOf(outside)}
+	_, seen := deprecations.LoadOrStore(key, struct{}{})
+	if state.Load() == siteUnresolved {
+		// A call site of another function of the package, such as a
+		// wrapper, leads to as many reported sites as it has callers
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if frameCallerInfo(frame).Package() == api.Package() {
+			state.Store(siteInternal)
+		} else {
+			state.Store(siteReported)
+		}
+	}
+	if seen {
+		return
+	}
+
+	d := Deprecation{Message: msg, API: api, CalledFrom: outside}
+	if sink := deprecationSink.Load(); sink != nil {
+		(*sink)(d)
+		return
+	}
+	slog.Warn(msg, slog.String("deprecated", api.FullFunction()), slog.Any("caller", outside))
+}
+
+// resetDeprecations forgets the call sites Deprecated has reported, so
+// that tests can run repeatedly in one process.
+func resetDeprecations() {
+	deprecatedSites.reset()
+	deprecations.Clear()
+}
@@ -0,0 +1,96 @@
+package caller
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// deprecatedAPI stands in for a deprecated library function.
+func deprecatedAPI() {
+	Deprecated("deprecatedAPI is deprecated")
+}
+
+// outsideHelper calls CallerOutside on behalf of its caller.
+func outsideHelper() Caller {
+	return CallerOutside(0)
+}
+
+// TestCallerOutside tests that CallerOutside skips frames of the calling
+// package. Tests share the package of this library, so the first caller
+// outside it is testing.tRunner.
+func TestCallerOutside(t *testing.T) {
+	t.Parallel()
+
+	if got, want := outsideHelper().FullFunction(), "testing.tRunner"; got != want {
+		t.Errorf("CallerOutside(0) = %q, want %q", got, want)
+	}
+	if got := CallerOutside(-1); got != nil {
+		t.Errorf("CallerOutside(-1) = %v, want nil", got)
+	}
+	if got := CallerOutside(10000); got != nil {
+		t.Errorf("CallerOutside(10000) = %v, want nil", got)
+	}
+}
+
+// TestDeprecated tests that warnings go to the sink once per external
+// call site.
+func TestDeprecated(t *testing.T) {
+	t.Cleanup(resetDeprecations)
+	restoreGlobal(t, &deprecationSink)
+	var (
+		mu  sync.Mutex
+		got []Deprecation
+	)
+	SetDeprecationSink(func(d Deprecation) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, d)
+	})
+
+	// Each subtest calls from the same site in testing.tRunner, so only
+	// the first one is reported
+	for range 3 {
+		t.Run("call", func(*testing.T) { deprecatedAPI() })
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("sink received %d warnings, want 1", len(got))
+	}
+	d := got[0]
+	if d.Message != "deprecatedAPI is deprecated" || d.API.Function() != "deprecatedAPI" || d.CalledFrom.FullFunction() != "testing.tRunner" {
+		t.Errorf("Deprecation = {%q %v %v}, want deprecatedAPI called from testing.tRunner", d.Message, d.API.FullFunction(), d.CalledFrom.FullFunction())
+	}
+}
+
+// TestDeprecated_ExternalSite tests that a call site outside the package
+// is reported once and then recognized by its program counter alone.
+func TestDeprecated_ExternalSite(t *testing.T) {
+	t.Cleanup(resetDeprecations)
+	restoreGlobal(t, &deprecationSink)
+	var got []Deprecation
+	SetDeprecationSink(func(d Deprecation) { got = append(got, d) })
+
+	// The function returned by sync.OnceFunc calls deprecatedAPI from
+	// one call site in package sync
+	for range 3 {
+		sync.OnceFunc(deprecatedAPI)()
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("sink received %d warnings, want 1", len(got))
+	}
+	if pkg := got[0].CalledFrom.Package(); pkg != "sync" {
+		t.Errorf("CalledFrom.Package() = %q, want sync", pkg)
+	}
+	reported := 0
+	deprecatedSites.bySite.Range(func(_, v any) bool {
+		if state, ok := v.(*atomic.Int32); ok && state.Load() == siteReported {
+			reported++
+		}
+		return true
+	})
+	if reported != 1 {
+		t.Errorf("%d call sites recorded as reported, want 1", reported)
+	}
+}
@@ -0,0 +1,53 @@
+package caller
+
+import (
+	"runtime"
+	"sync"
+)
+
+// depthBuffers holds program counter buffers for StackDepth, grown as
+// deep stacks need them, so that repeated calls on deep stacks do not
+// allocate.
+var depthBuffers = sync.Pool{
+	New: func() any {
+		return new([]uintptr)
+	},
+}
+
+// StackDepth returns the number of frames on the stack of the calling
+// goroutine, counting the function calling StackDepth and the runtime
+// frames that start the goroutine, so that code can detect unexpectedly
+// deep call chains before deciding whether capturing a full Stack is
+// worth it:
+//
+//	if caller.StackDepth() > 500 {
+//		log.Warn("deep recursion", "stack", caller.NewStack(0))
+//	}
+//
+// It counts the frames runtime.Callers reports without resolving them, so
+// it is much cheaper than NewStack and does not allocate for stacks of up
+// to 64 frames. Like NewStack, it counts functions the compiler inlined
+// into their callers as frames of their own. Unlike NewStack, its count
+// is not bounded.
+func StackDepth() int {
+	// Skip runtime.Callers and StackDepth
+	var arr [64]uintptr
+	if n := runtime.Callers(2, arr[:]); n < len(arr) {
+		return n
+	}
+
+	bp, ok := depthBuffers.Get().(*[]uintptr)
+	if !ok {
+		bp = new([]uintptr)
+	}
+	defer depthBuffers.Put(bp)
+	for size := max(2*len(arr), cap(*bp)); ; size *= 2 {
+		if cap(*bp) < size {
+			*bp = make([]uintptr, size)
+		}
+		buf := (*bp)[:size]
+		if n := runtime.Callers(2, buf); n < len(buf) {
+			return n
+		}
+	}
+}
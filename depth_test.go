@@ -0,0 +1,66 @@
+package caller
+
+import (
+	"runtime"
+	"testing"
+)
+
+// recurseDepth calls f from n nested frames.
+//
+//go:noinline
+func recurseDepth(n int, f func()) {
+	if n == 0 {
+		f()
+		return
+	}
+	recurseDepth(n-1, f)
+}
+
+// TestStackDepth tests that StackDepth counts the frames runtime.Callers
+// returns, on shallow stacks and on stacks deeper than its fixed buffer.
+func TestStackDepth(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []int{0, 10, 200, 5000} {
+		var got, want int
+		recurseDepth(n, func() {
+			got = StackDepth()
+			want = runtime.Callers(1, make([]uintptr, 2*n+64))
+		})
+		if got != want {
+			t.Errorf("StackDepth() at %d nested frames = %d, want %d", n, got, want)
+		}
+	}
+
+	base := StackDepth()
+	var nested int
+	recurseDepth(100, func() { nested = StackDepth() })
+	if nested < base+100 {
+		t.Errorf("StackDepth() = %d after 100 nested calls, want at least %d", nested, base+100)
+	}
+}
+
+// inlinedDepth returns StackDepth from a function small enough to be
+// inlined into its caller.
+func inlinedDepth() int {
+	return StackDepth()
+}
+
+// TestStackDepth_Inlined tests that StackDepth counts inlined functions as
+// frames of their own.
+func TestStackDepth_Inlined(t *testing.T) {
+	t.Parallel()
+
+	base := StackDepth()
+	if got, want := inlinedDepth(), base+1; got != want {
+		t.Errorf("StackDepth() from an inlined function = %d, want %d", got, want)
+	}
+}
+
+// BenchmarkStackDepth measures counting the frames of a shallow stack.
+func BenchmarkStackDepth(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		_ = StackDepth()
+	}
+}
@@ -0,0 +1,322 @@
+package caller
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"time"
+)
+
+// Limits applied by ParseGoroutines to ParseLimits fields that are not
+// positive.
+const (
+	DefaultParseMaxFrames     = 1024
+	DefaultParseMaxGoroutines = 100_000
+	DefaultParseMaxLineLength = 64 * 1024
+)
+
+// ErrParseLimit is matched by every ParseLimitError.
+var ErrParseLimit = errors.New("traceback parse limit exceeded")
+
+// ParseLimitError reports that a traceback exceeded one of the limits of
+// ParseLimits.
+type ParseLimitError struct {
+	Limit string // Name of the limit: "frames", "goroutines" or "line length"
+	Max   int    // Value of the limit
+	Line  int    // Line of the traceback at which it was exceeded, from 1
+}
+
+// Error implements the error interface.
+func (e *ParseLimitError) Error() string {
+	return "traceback line " + strconv.Itoa(e.Line) + ": more than " + strconv.Itoa(e.Max) + " " + e.Limit
+}
+
+// Unwrap returns ErrParseLimit.
+func (e *ParseLimitError) Unwrap() error {
+	return ErrParseLimit
+}
+
+// ParseLimits bounds the resources a traceback parser spends on its
+// input, so that services parsing stack text from users or other
+// untrusted sources cannot be made to exhaust their memory. Fields that
+// are not positive select the corresponding default.
+type ParseLimits struct {
+	MaxFrames     int // Maximum number of frames of one goroutine
+	MaxGoroutines int // Maximum number of goroutines
+	MaxLineLength int // Maximum length of a line in bytes
+}
+
+// withDefaults returns l with its unset fields set to their defaults.
+func (l ParseLimits) withDefaults() ParseLimits {
+	if l.MaxFrames <= 0 {
+		l.MaxFrames = DefaultParseMaxFrames
+	}
+	if l.MaxGoroutines <= 0 {
+		l.MaxGoroutines = DefaultParseMaxGoroutines
+	}
+	if l.MaxLineLength <= 0 {
+		l.MaxLineLength = DefaultParseMaxLineLength
+	}
+	return l
+}
+
+// Goroutine is a goroutine parsed from a runtime traceback, such as the
+// output of debug.Stack, runtime.Stack or a crash or SIGQUIT dump.
+type Goroutine struct {
+	ID        uint64        `json:"id"`                   // Goroutine ID
+	State     string        `json:"state,omitempty"`      // Status or wait reason, such as "running" or "chan receive"
+	Wait      time.Duration `json:"wait,omitempty"`       // How long it has been blocked, to the minute, if reported
+	Locked    bool          `json:"locked,omitempty"`     // Whether it is locked to its thread
+	Elided    bool          `json:"elided,omitempty"`     // Whether the runtime left frames out of the traceback
+	Stack     *Stack        `json:"stack,omitempty"`      // Frames, innermost first
+	CreatedBy Caller        `json:"created_by,omitempty"` // Go statement that started it, or nil if not reported
+}
+
+// appendTraceback appends the goroutine to b in Go runtime traceback
+// format, as ParseGoroutines reads it.
+func (g *Goroutine) appendTraceback(b []byte) []byte {
+	b = append(b, "goroutine "...)
+	b = strconv.AppendUint(b, g.ID, 10)
+	b = append(b, " ["...)
+	b = append(b, g.State...)
+	if m := int64(g.Wait / time.Minute); m > 0 {
+		b = append(b, ", "...)
+		b = strconv.AppendInt(b, m, 10)
+		b = append(b, " minutes"...)
+	}
+	if g.Locked {
+		b = append(b, ", locked to thread"...)
+	}
+	b = append(b, "]:\n"...)
+	b = g.Stack.appendFrames(b)
+	if g.Elided {
+		b = append(b, "...additional frames elided...\n"...)
+	}
+	if !isNil(g.CreatedBy) {
+		b = append(b, "created by "...)
+		b = append(b, g.CreatedBy.FullFunction()...)
+		b = append(b, "\n\t"...)
+		b = append(b, g.CreatedBy.Location()...)
+		b = append(b, '\n')
+	}
+	return b
+}
+
+// ParseGoroutines parses the goroutines of a runtime traceback in data:
+//
+//	goroutine 7 [chan receive, 5 minutes]:
+//	example.com/app.(*Server).wait(...)
+//		/src/app/server.go:42 +0x1d
+//	created by example.com/app.Start in goroutine 1
+//		/src/app/main.go:12 +0x25
+//
+// Text outside goroutine blocks, such as a panic message, is ignored.
+// The stacks it returns have their GoroutineID set, but no program
+// counters, so they are never recaptured or offset. If data exceeds
+// limits, it returns the goroutines parsed so far with a
+// *ParseLimitError.
+func ParseGoroutines(data []byte, limits ParseLimits) ([]*Goroutine, error) {
+	var gs []*Goroutine
+	err := parseTraceback(bytes.NewReader(data), limits, func(g *Goroutine) bool {
+		gs = append(gs, g)
+		return true
+	})
+	return gs, err
+}
+
+// ReadGoroutines returns an iterator over the goroutines of the runtime
+// traceback read from r, parsed incrementally as r is read, so that dumps
+// of hundreds of megabytes can be processed holding a single goroutine
+// in memory:
+//
+//	for g, err := range caller.ReadGoroutines(f, caller.ParseLimits{}) {
+//		if err != nil {
+//			return err
+//		}
+//		count[g.State]++
+//	}
+//
+// It parses like ParseGoroutines, and ParseLimits bound each goroutine
+// and line as well as the number of goroutines, which huge dumps may need
+// to raise. Iteration ends after the first error, which is yielded with a
+// nil goroutine. The iterator reads r, so it can be used only once.
+func ReadGoroutines(r io.Reader, limits ParseLimits) iter.Seq2[*Goroutine, error] {
+	return func(yield func(*Goroutine, error) bool) {
+		err := parseTraceback(r, limits, func(g *Goroutine) bool {
+			return yield(g, nil)
+		})
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// parseTraceback parses the traceback read from r, calling yield with
+// each goroutine as it is completed, until yield returns false.
+func parseTraceback(r io.Reader, limits ParseLimits, yield func(*Goroutine) bool) error {
+	p := tracebackParser{limits: limits.withDefaults(), yield: yield}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, min(4096, p.limits.MaxLineLength+1)), p.limits.MaxLineLength+1)
+	for !p.stopped && sc.Scan() {
+		if err := p.line(sc.Bytes()); err != nil {
+			return err
+		}
+	}
+	if p.stopped {
+		return nil
+	}
+	if err := sc.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return &ParseLimitError{Limit: "line length", Max: p.limits.MaxLineLength, Line: p.lineNo + 1}
+		}
+		return fmt.Errorf("read traceback: %w", err)
+	}
+	p.end()
+	return nil
+}
+
+// tracebackParser parses a traceback line by line.
+type tracebackParser struct {
+	limits  ParseLimits
+	yield   func(*Goroutine) bool // Called with each completed goroutine
+	stopped bool                  // Whether yield returned false
+	lineNo  int                   // Number of lines read
+	n       int                   // Number of goroutines started
+	g       *Goroutine            // Goroutine being parsed, or nil between goroutines
+	frames  []*callerInfo
+	fn      string // Function of the frame awaiting its location line
+	pending bool   // Whether fn awaits its location line
+	created bool   // Whether fn is that of a created by line
+}
+
+// line parses the next line of the traceback.
+func (p *tracebackParser) line(l []byte) error {
+	p.lineNo++
+	if len(l) > p.limits.MaxLineLength {
+		return &ParseLimitError{Limit: "line length", Max: p.limits.MaxLineLength, Line: p.lineNo}
+	}
+	l = bytes.TrimSuffix(l, []byte("\r"))
+
+	if g, ok := parseGoroutineHeader(l); ok {
+		if p.end(); p.stopped {
+			return nil
+		}
+		if p.n++; p.n > p.limits.MaxGoroutines {
+			return &ParseLimitError{Limit: "goroutines", Max: p.limits.MaxGoroutines, Line: p.lineNo}
+		}
+		p.g = g
+		return nil
+	}
+	if p.g == nil {
+		return nil
+	}
+
+	switch {
+	case len(l) == 0:
+		p.end()
+	case l[0] == '\t' && p.pending:
+		file, line := parseTracebackLocation(l[1:])
+		p.flush(file, line)
+	case bytes.HasPrefix(l, []byte("created by ")):
+		p.flush("", 0)
+		fn, _, _ := bytes.Cut(l[len("created by "):], []byte(" in goroutine "))
+		p.fn, p.pending, p.created = string(fn), true, true
+	case bytes.HasPrefix(l, []byte("...")):
+		p.g.Elided = true
+	case bytes.HasSuffix(l, []byte(")")) && bytes.IndexByte(l, '(') > 0:
+		p.flush("", 0)
+		if len(p.frames) >= p.limits.MaxFrames {
+			return &ParseLimitError{Limit: "frames", Max: p.limits.MaxFrames, Line: p.lineNo}
+		}
+		p.fn, p.pending, p.created = string(l[:bytes.LastIndexByte(l, '(')]), true, false
+	}
+	return nil
+}
+
+// flush completes the frame awaiting its location, if any, with file and
+// line.
+func (p *tracebackParser) flush(file string, line int) {
+	if !p.pending {
+		return
+	}
+	c := &callerInfo{file: file, line: line, fn: p.fn, dotIdx: functionNameIndex(p.fn), flags: flagOwnsStrings}
+	if p.created {
+		p.g.CreatedBy = c
+	} else {
+		p.frames = append(p.frames, c)
+	}
+	p.fn, p.pending = "", false
+}
+
+// end completes the goroutine being parsed, if any, and passes it to
+// yield, recording whether it asked to stop.
+func (p *tracebackParser) end() {
+	g := p.g
+	if g == nil {
+		return
+	}
+	p.flush("", 0)
+	g.Stack = &Stack{frames: p.frames, goid: g.ID}
+	p.g, p.frames = nil, nil
+	p.stopped = !p.yield(g)
+}
+
+// parseGoroutineHeader parses a goroutine header line such as
+// "goroutine 7 [chan receive, 5 minutes, locked to thread]:", which may
+// carry further fields before the status with GOTRACEBACK=system.
+func parseGoroutineHeader(l []byte) (*Goroutine, bool) {
+	rest, ok := bytes.CutPrefix(l, []byte("goroutine "))
+	if !ok {
+		return nil, false
+	}
+	id, rest, ok := bytes.Cut(rest, []byte(" "))
+	if !ok {
+		return nil, false
+	}
+	goid, err := strconv.ParseUint(string(id), 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	open := bytes.IndexByte(rest, '[')
+	if open < 0 || !bytes.HasSuffix(rest, []byte("]:")) {
+		return nil, false
+	}
+
+	g := &Goroutine{ID: goid}
+	for i, field := range bytes.Split(rest[open+1:len(rest)-2], []byte(", ")) {
+		switch {
+		case i == 0:
+			g.State = string(field)
+		case bytes.Equal(field, []byte("locked to thread")):
+			g.Locked = true
+		case bytes.HasSuffix(field, []byte(" minutes")):
+			if m, err := strconv.Atoi(string(field[:len(field)-len(" minutes")])); err == nil {
+				g.Wait = time.Duration(m) * time.Minute
+			}
+		}
+	}
+	return g, true
+}
+
+// parseTracebackLocation parses the location of a frame, such as
+// "/src/app/server.go:42 +0x1d", without its leading tab.
+func parseTracebackLocation(l []byte) (string, int) {
+	for _, sep := range [...]string{" +0x", " fp="} {
+		if i := bytes.Index(l, []byte(sep)); i >= 0 {
+			l = l[:i]
+		}
+	}
+	i := bytes.LastIndexByte(l, ':')
+	if i < 0 {
+		return string(l), 0
+	}
+	line, err := strconv.Atoi(string(l[i+1:]))
+	if err != nil {
+		return string(l), 0
+	}
+	return string(l[:i]), line
+}
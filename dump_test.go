@@ -0,0 +1,255 @@
+package caller
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// testTraceback is a traceback with the variations ParseGoroutines
+// handles.
+const testTraceback = `panic: boom
+
+goroutine 1 [running]:
+main.(*T).M(...)
+	/src/app/main.go:10
+main.main()
+	/src/app/main.go:20 +0x1d
+
+goroutine 7 gp=0xc000007c00 m=nil [chan receive, 5 minutes, locked to thread]:
+runtime.gopark(0x0?, 0x0?, 0x0?, 0x0?, 0x0?)
+	/usr/local/go/src/runtime/proc.go:435 +0xce fp=0xc00004ef38 sp=0xc00004ef18 pc=0x46f0ae
+example.com/app.wait[...](0xc000016100)
+	/src/my app/wait.go:7 +0x25
+...additional frames elided...
+created by example.com/app.Start in goroutine 1
+	/src/app/start.go:12 +0x3a
+
+goroutine 9 [select]:
+example.com/app.loop()
+`
+
+// TestParseGoroutines tests parsing goroutine headers, frames and
+// creation sites.
+func TestParseGoroutines(t *testing.T) {
+	t.Parallel()
+
+	gs, err := ParseGoroutines([]byte(strings.ReplaceAll(testTraceback, "main.main()\n", "main.main()\r\n")), ParseLimits{})
+	if err != nil {
+		t.Fatalf("ParseGoroutines() error = %v", err)
+	}
+	if len(gs) != 3 {
+		t.Fatalf("ParseGoroutines() = %d goroutines, want 3", len(gs))
+	}
+
+	tests := []struct {
+		name    string
+		g       *Goroutine
+		id      uint64
+		state   string
+		wait    time.Duration
+		locked  bool
+		elided  bool
+		frames  []string
+		created string
+	}{
+		{"running", gs[0], 1, "running", 0, false, false, []string{"main.(*T).M /src/app/main.go:10", "main.main /src/app/main.go:20"}, ""},
+		{"blocked", gs[1], 7, "chan receive", 5 * time.Minute, true, true, []string{
+			"runtime.gopark /usr/local/go/src/runtime/proc.go:435",
+			"example.com/app.wait[...] /src/my app/wait.go:7",
+		}, "example.com/app.Start /src/app/start.go:12"},
+		{"truncated", gs[2], 9, "select", 0, false, false, []string{"example.com/app.loop "}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			g := tt.g
+			if g.ID != tt.id || g.State != tt.state || g.Wait != tt.wait || g.Locked != tt.locked || g.Elided != tt.elided {
+				t.Errorf("goroutine = %+v, want ID %d, state %q, wait %v, locked %t, elided %t", g, tt.id, tt.state, tt.wait, tt.locked, tt.elided)
+			}
+			if got := g.Stack.GoroutineID(); got != tt.id {
+				t.Errorf("Stack.GoroutineID() = %d, want %d", got, tt.id)
+			}
+			var frames []string
+			for _, f := range g.Stack.frames {
+				frames = append(frames, f.FullFunction()+" "+f.Location())
+			}
+			if strings.Join(frames, "\n") != strings.Join(tt.frames, "\n") {
+				t.Errorf("frames = %q, want %q", frames, tt.frames)
+			}
+			var created string
+			if g.CreatedBy != nil {
+				created = g.CreatedBy.FullFunction() + " " + g.CreatedBy.Location()
+			}
+			if created != tt.created {
+				t.Errorf("CreatedBy = %q, want %q", created, tt.created)
+			}
+		})
+	}
+}
+
+// TestParseGoroutines_Runtime tests parsing a traceback of all
+// goroutines written by the runtime.
+func TestParseGoroutines_Runtime(t *testing.T) {
+	t.Parallel()
+
+	block := make(chan struct{})
+	defer close(block)
+	started := make(chan struct{})
+	go parkedHelper(started, block)
+	<-started
+
+	buf := make([]byte, 1<<20)
+	gs, err := ParseGoroutines(buf[:runtime.Stack(buf, true)], ParseLimits{})
+	if err != nil {
+		t.Fatalf("ParseGoroutines() error = %v", err)
+	}
+	for _, g := range gs {
+		if g.Stack.hasFunction("github.com/balinomad/go-caller/v2.parkedHelper") {
+			if g.State != "chan receive" || g.CreatedBy == nil || g.CreatedBy.Function() != "TestParseGoroutines_Runtime" {
+				t.Errorf("goroutine = %+v, want a chan receive created by the test", g)
+			}
+			return
+		}
+	}
+	t.Errorf("ParseGoroutines() found no goroutine running parkedHelper in %d goroutines", len(gs))
+}
+
+// parkedHelper signals started and blocks until block is closed.
+//
+//go:noinline
+func parkedHelper(started chan<- struct{}, block <-chan struct{}) {
+	close(started)
+	<-block
+}
+
+// TestParseGoroutines_Limits tests that each limit stops the parse with a
+// *ParseLimitError.
+func TestParseGoroutines_Limits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		data   string
+		limits ParseLimits
+		want   ParseLimitError
+		parsed int
+	}{
+		{"frames", testTraceback, ParseLimits{MaxFrames: 1}, ParseLimitError{Limit: "frames", Max: 1, Line: 6}, 0},
+		{"goroutines", testTraceback, ParseLimits{MaxGoroutines: 2}, ParseLimitError{Limit: "goroutines", Max: 2, Line: 18}, 2},
+		{"line length", testTraceback, ParseLimits{MaxLineLength: 60}, ParseLimitError{Limit: "line length", Max: 60, Line: 9}, 1},
+		{"long last line", "goroutine 1 [running]:\n" + strings.Repeat("x", 100), ParseLimits{MaxLineLength: 99}, ParseLimitError{Limit: "line length", Max: 99, Line: 2}, 0},
+		{"default line length", strings.Repeat("x", DefaultParseMaxLineLength+1), ParseLimits{}, ParseLimitError{Limit: "line length", Max: DefaultParseMaxLineLength, Line: 1}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			gs, err := ParseGoroutines([]byte(tt.data), tt.limits)
+			var le *ParseLimitError
+			if !errors.As(err, &le) || *le != tt.want || !errors.Is(err, ErrParseLimit) {
+				t.Fatalf("ParseGoroutines() error = %v, want %v", err, &tt.want)
+			}
+			if len(gs) != tt.parsed {
+				t.Errorf("ParseGoroutines() = %d goroutines, want %d", len(gs), tt.parsed)
+			}
+		})
+	}
+}
+
+// TestParseGoroutines_NoGoroutines tests that text without goroutine
+// headers yields no goroutines.
+func TestParseGoroutines_NoGoroutines(t *testing.T) {
+	t.Parallel()
+
+	for _, data := range []string{"", "panic: boom\n", "goroutine x [running]:\nmain.main()\n", "goroutine 1 running:\n"} {
+		if gs, err := ParseGoroutines([]byte(data), ParseLimits{}); len(gs) != 0 || err != nil {
+			t.Errorf("ParseGoroutines(%q) = %v, %v, want none", data, gs, err)
+		}
+	}
+}
+
+// TestReadGoroutines tests iterating over the goroutines of a traceback
+// read incrementally.
+func TestReadGoroutines(t *testing.T) {
+	t.Parallel()
+
+	errRead := errors.New("read failed")
+	tests := []struct {
+		name    string
+		r       io.Reader
+		limits  ParseLimits
+		ids     []uint64
+		wantErr error
+	}{
+		{"one byte reads", iotest.OneByteReader(strings.NewReader(testTraceback)), ParseLimits{}, []uint64{1, 7, 9}, nil},
+		{"limit", strings.NewReader(testTraceback), ParseLimits{MaxFrames: 1}, []uint64{}, ErrParseLimit},
+		{"read error", io.MultiReader(strings.NewReader(testTraceback[:60]), iotest.ErrReader(errRead)), ParseLimits{}, []uint64{}, errRead},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ids := []uint64{}
+			var err error
+			for g, e := range ReadGoroutines(tt.r, tt.limits) {
+				if e != nil {
+					if g != nil {
+						t.Errorf("ReadGoroutines() yielded %+v with error %v, want nil", g, e)
+					}
+					err = e
+					continue
+				}
+				ids = append(ids, g.ID)
+			}
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("ReadGoroutines() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(ids, tt.ids) {
+				t.Errorf("ReadGoroutines() IDs = %v, want %v", ids, tt.ids)
+			}
+		})
+	}
+}
+
+// TestReadGoroutines_Break tests that breaking out of the iteration stops
+// reading.
+func TestReadGoroutines_Break(t *testing.T) {
+	t.Parallel()
+
+	r := strings.NewReader(testTraceback + strings.Repeat("\n", 1<<20))
+	for g, err := range ReadGoroutines(r, ParseLimits{}) {
+		if err != nil || g.ID != 1 {
+			t.Fatalf("ReadGoroutines() = %+v, %v, want goroutine 1", g, err)
+		}
+		break
+	}
+	if r.Len() == 0 {
+		t.Error("ReadGoroutines() read all of its input after the loop ended")
+	}
+}
+
+// TestReadGoroutines_Large tests streaming a dump with more goroutines
+// than fit in its read buffer.
+func TestReadGoroutines_Large(t *testing.T) {
+	t.Parallel()
+
+	const n = 20_000
+	block := "goroutine 5 [select]:\nexample.com/app.loop()\n\t/src/app/loop.go:3 +0x1\n\n"
+	count := 0
+	for g, err := range ReadGoroutines(strings.NewReader(strings.Repeat(block, n)), ParseLimits{MaxGoroutines: n}) {
+		if err != nil {
+			t.Fatalf("ReadGoroutines() error = %v", err)
+		}
+		if len(g.Stack.frames) != 1 {
+			t.Fatalf("goroutine has %d frames, want 1", len(g.Stack.frames))
+		}
+		count++
+	}
+	if count != n {
+		t.Errorf("ReadGoroutines() = %d goroutines, want %d", count, n)
+	}
+}
@@ -0,0 +1,174 @@
+package caller
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GroupOption configures how a GoroutineAnalyzer groups goroutines.
+type GroupOption func(*groupConfig)
+
+// groupConfig holds the settings applied by GroupOption values.
+type groupConfig struct {
+	byFunction bool // Compare frames by FunctionKey, ignoring lines
+}
+
+// GroupByFunction makes goroutines group together when their stacks have
+// the same frames by FunctionKey, ignoring line numbers and the checkout
+// location, so that dumps from builds that differ by unrelated edits, or
+// goroutines blocked at different lines of the same functions, still
+// group together.
+func GroupByFunction() GroupOption {
+	return func(cfg *groupConfig) {
+		cfg.byFunction = true
+	}
+}
+
+// GoroutineGroup is a set of goroutines with the same stack, as found by
+// a GoroutineAnalyzer.
+type GoroutineGroup struct {
+	Stack   *Stack         // Stack of the first goroutine of the group, without its goroutine ID
+	IDs     []uint64       // IDs of the goroutines, in the order they were added
+	States  map[string]int // Number of goroutines in each state
+	MaxWait time.Duration  // Longest time any of the goroutines has been blocked
+}
+
+// Count returns the number of goroutines in the group.
+func (g *GoroutineGroup) Count() int {
+	return len(g.IDs)
+}
+
+// String renders the group as its goroutine count and states, most
+// frequent first, followed by its stack in runtime traceback format:
+//
+//	40 goroutines [chan receive: 38, select: 2, up to 5 minutes]:
+//	example.com/app.(*Pool).wait(...)
+//		/src/app/pool.go:42
+func (g *GoroutineGroup) String() string {
+	b := strconv.AppendInt(nil, int64(g.Count()), 10)
+	if g.Count() == 1 {
+		b = append(b, " goroutine ["...)
+	} else {
+		b = append(b, " goroutines ["...)
+	}
+	states := slices.SortedFunc(maps.Keys(g.States), func(a, b string) int {
+		return cmp.Or(cmp.Compare(g.States[b], g.States[a]), strings.Compare(a, b))
+	})
+	for i, state := range states {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = append(b, state...)
+		b = append(b, ": "...)
+		b = strconv.AppendInt(b, int64(g.States[state]), 10)
+	}
+	if m := int64(g.MaxWait / time.Minute); m > 0 {
+		b = append(b, ", up to "...)
+		b = strconv.AppendInt(b, m, 10)
+		b = append(b, " minutes"...)
+	}
+	b = append(b, "]:\n"...)
+	return string(g.Stack.appendTraceback(b))
+}
+
+// GoroutineAnalyzer groups goroutines parsed from tracebacks by their
+// stacks and counts the states of each group, so that dumps of thousands
+// of goroutines reduce to the few distinct things they were doing:
+//
+//	a := caller.NewGoroutineAnalyzer()
+//	for g, err := range caller.ReadGoroutines(f, caller.ParseLimits{}) {
+//		if err != nil {
+//			return err
+//		}
+//		a.Add(g)
+//	}
+//	for _, group := range a.Groups() {
+//		fmt.Println(group)
+//	}
+//
+// It keeps one stack per group rather than each goroutine added. A
+// GoroutineAnalyzer is not safe for concurrent use.
+type GoroutineAnalyzer struct {
+	cfg    groupConfig
+	groups map[string]*GoroutineGroup
+	order  []*GoroutineGroup // Groups in the order they were created
+}
+
+// NewGoroutineAnalyzer returns an empty GoroutineAnalyzer configured by
+// opts.
+func NewGoroutineAnalyzer(opts ...GroupOption) *GoroutineAnalyzer {
+	a := &GoroutineAnalyzer{groups: make(map[string]*GoroutineGroup)}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&a.cfg)
+		}
+	}
+	return a
+}
+
+// Add adds each of gs to the group of its stack. Nil goroutines are
+// ignored.
+func (a *GoroutineAnalyzer) Add(gs ...*Goroutine) {
+	for _, g := range gs {
+		if g == nil {
+			continue
+		}
+		key := a.cfg.key(g.Stack)
+		group, ok := a.groups[key]
+		if !ok {
+			group = &GoroutineGroup{States: make(map[string]int)}
+			if g.Stack != nil {
+				group.Stack = &Stack{frames: g.Stack.frames}
+			}
+			a.groups[key] = group
+			a.order = append(a.order, group)
+		}
+		group.IDs = append(group.IDs, g.ID)
+		group.States[g.State]++
+		group.MaxWait = max(group.MaxWait, g.Wait)
+	}
+}
+
+// Groups returns the groups found so far, largest first, and in the
+// order they were first seen among groups of the same size.
+func (a *GoroutineAnalyzer) Groups() []*GoroutineGroup {
+	groups := slices.Clone(a.order)
+	slices.SortStableFunc(groups, func(x, y *GoroutineGroup) int {
+		return cmp.Compare(y.Count(), x.Count())
+	})
+	return groups
+}
+
+// GroupGoroutines groups gs by their stacks, as a GoroutineAnalyzer
+// configured by opts would, and returns the groups largest first.
+func GroupGoroutines(gs []*Goroutine, opts ...GroupOption) []*GoroutineGroup {
+	a := NewGoroutineAnalyzer(opts...)
+	a.Add(gs...)
+	return a.Groups()
+}
+
+// key returns the string identifying the group of goroutines with
+// stack s.
+func (cfg groupConfig) key(s *Stack) string {
+	if s == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, f := range s.frames {
+		if cfg.byFunction {
+			b.WriteString(FunctionKey(f))
+		} else {
+			b.WriteString(f.file)
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(f.line))
+			b.WriteByte(' ')
+			b.WriteString(f.fn)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package caller
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// groupTraceback is a traceback with goroutines blocked at two lines of
+// one function and one running elsewhere.
+const groupTraceback = `goroutine 3 [chan receive, 2 minutes]:
+example.com/app.(*Pool).wait(...)
+	/src/app/pool.go:42 +0x1d
+example.com/app.worker()
+	/src/app/pool.go:10 +0x25
+
+goroutine 4 [running]:
+example.com/app.serve()
+	/src/app/serve.go:5
+
+goroutine 5 [chan receive, 5 minutes]:
+example.com/app.(*Pool).wait(...)
+	/src/app/pool.go:42 +0x1d
+example.com/app.worker()
+	/src/app/pool.go:10 +0x25
+
+goroutine 6 [select]:
+example.com/app.(*Pool).wait(...)
+	/src/app/pool.go:47 +0x40
+example.com/app.worker()
+	/src/app/pool.go:10 +0x25
+`
+
+// TestGroupGoroutines tests grouping goroutines by identical stacks and
+// by function.
+func TestGroupGoroutines(t *testing.T) {
+	t.Parallel()
+
+	gs, err := ParseGoroutines([]byte(groupTraceback), ParseLimits{})
+	if err != nil {
+		t.Fatalf("ParseGoroutines() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		opts   []GroupOption
+		ids    [][]uint64
+		states []map[string]int
+		waits  []time.Duration
+	}{
+		{
+			"identical", nil,
+			[][]uint64{{3, 5}, {4}, {6}},
+			[]map[string]int{{"chan receive": 2}, {"running": 1}, {"select": 1}},
+			[]time.Duration{5 * time.Minute, 0, 0},
+		},
+		{
+			"by function", []GroupOption{GroupByFunction(), nil},
+			[][]uint64{{3, 5, 6}, {4}},
+			[]map[string]int{{"chan receive": 2, "select": 1}, {"running": 1}},
+			[]time.Duration{5 * time.Minute, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			groups := GroupGoroutines(append(slices.Clone(gs), nil), tt.opts...)
+			if len(groups) != len(tt.ids) {
+				t.Fatalf("GroupGoroutines() = %d groups, want %d", len(groups), len(tt.ids))
+			}
+			for i, g := range groups {
+				if !slices.Equal(g.IDs, tt.ids[i]) || g.Count() != len(tt.ids[i]) {
+					t.Errorf("group %d IDs = %v, want %v", i, g.IDs, tt.ids[i])
+				}
+				if len(g.States) != len(tt.states[i]) {
+					t.Errorf("group %d States = %v, want %v", i, g.States, tt.states[i])
+				}
+				for state, n := range tt.states[i] {
+					if g.States[state] != n {
+						t.Errorf("group %d States = %v, want %v", i, g.States, tt.states[i])
+					}
+				}
+				if g.MaxWait != tt.waits[i] {
+					t.Errorf("group %d MaxWait = %v, want %v", i, g.MaxWait, tt.waits[i])
+				}
+				if g.Stack.GoroutineID() != 0 {
+					t.Errorf("group %d Stack.GoroutineID() = %d, want 0", i, g.Stack.GoroutineID())
+				}
+			}
+		})
+	}
+}
+
+// TestGoroutineAnalyzer tests adding goroutines incrementally.
+func TestGoroutineAnalyzer(t *testing.T) {
+	t.Parallel()
+
+	a := NewGoroutineAnalyzer()
+	if len(a.Groups()) != 0 {
+		t.Fatal("new GoroutineAnalyzer has groups")
+	}
+	for g, err := range ReadGoroutines(strings.NewReader(groupTraceback), ParseLimits{}) {
+		if err != nil {
+			t.Fatalf("ReadGoroutines() error = %v", err)
+		}
+		a.Add(g)
+	}
+	a.Add(&Goroutine{ID: 9, State: "idle"})
+	groups := a.Groups()
+	if len(groups) != 4 || groups[0].Count() != 2 || groups[3].Stack != nil {
+		t.Errorf("Groups() = %v, want 4 groups, the largest first and the stackless last", groups)
+	}
+}
+
+// TestGoroutineGroup_String tests rendering a group with its states and
+// stack.
+func TestGoroutineGroup_String(t *testing.T) {
+	t.Parallel()
+
+	gs, err := ParseGoroutines([]byte(groupTraceback), ParseLimits{})
+	if err != nil {
+		t.Fatalf("ParseGoroutines() error = %v", err)
+	}
+	groups := GroupGoroutines(gs, GroupByFunction())
+
+	want := "3 goroutines [chan receive: 2, select: 1, up to 5 minutes]:\n" +
+		"example.com/app.(*Pool).wait(...)\n\t/src/app/pool.go:42\n" +
+		"example.com/app.worker(...)\n\t/src/app/pool.go:10\n"
+	if got := groups[0].String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	want = "1 goroutine [running: 1]:\nexample.com/app.serve(...)\n\t/src/app/serve.go:5\n"
+	if got := groups[1].String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
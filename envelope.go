@@ -0,0 +1,71 @@
+package caller
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSONSchemaVersion is the version of the JSON encoding of Caller and
+// Stack written by VersionedJSON.
+//
+// Decoding always accepts both plain and versioned payloads and follows
+// forward-compatible rules: a payload without "v" is treated as version
+// 1; a payload with a newer version than JSONSchemaVersion is decoded on
+// a best-effort basis, using the fields this version understands;
+// unknown fields are ignored. Only a negative version is rejected.
+const JSONSchemaVersion = 1
+
+// VersionedJSON returns a json.Marshaler encoding c like its MarshalJSON
+// method, with a schema version field, as in {"v":1,"file":"main.go",...},
+// so that payloads stored long-term remain interpretable as the encoding
+// evolves:
+//
+//	b, err := json.Marshal(caller.VersionedJSON(c))
+//
+// A nil c encodes as null.
+func VersionedJSON(c Caller) json.Marshaler {
+	return versionedCaller{c: c}
+}
+
+// VersionedJSON returns a json.Marshaler encoding s like its MarshalJSON
+// method, with a schema version field in the stack and in each frame,
+// as VersionedJSON does for a Caller.
+func (s *Stack) VersionedJSON() json.Marshaler {
+	return versionedStack{s: s}
+}
+
+// versionedCaller is the json.Marshaler returned by VersionedJSON.
+type versionedCaller struct {
+	c Caller
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v versionedCaller) MarshalJSON() ([]byte, error) {
+	if isNil(v.c) {
+		return []byte("null"), nil
+	}
+	ci, ok := v.c.(*callerInfo)
+	if !ok {
+		fn := v.c.FullFunction()
+		ci = &callerInfo{file: v.c.File(), line: v.c.Line(), fn: fn, dotIdx: functionNameIndex(fn)}
+	}
+	return ci.marshalJSON(JSONSchemaVersion)
+}
+
+// versionedStack is the json.Marshaler returned by Stack.VersionedJSON.
+type versionedStack struct {
+	s *Stack
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v versionedStack) MarshalJSON() ([]byte, error) {
+	return v.s.marshalJSON(JSONSchemaVersion)
+}
+
+// checkSchemaVersion validates the version found in a JSON payload.
+func checkSchemaVersion(v int) error {
+	if v < 0 {
+		return fmt.Errorf("invalid schema version: %d", v)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package caller
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestVersionedJSON tests that VersionedJSON adds the schema version to
+// Caller and Stack output, and that plain output has none.
+func TestVersionedJSON(t *testing.T) {
+	t.Parallel()
+
+	c := &callerInfo{file: "a.go", line: 1, fn: "pkg.F", dotIdx: 3}
+	s := &Stack{frames: []*callerInfo{c}}
+
+	for name, tc := range map[string]struct {
+		v    any
+		want string
+	}{
+		"caller":      {VersionedJSON(c), `{"v":1,"file":"a.go","line":1,"function":"F","package":"pkg"}`},
+		"mock caller": {VersionedJSON(&mockCaller{file: "a.go", line: 1, fullFn: "pkg.F"}), `{"v":1,"file":"a.go","line":1,"function":"F","package":"pkg"}`},
+		"nil caller":  {VersionedJSON((*callerInfo)(nil)), `null`},
+		"stack":       {s.VersionedJSON(), `{"v":1,"frames":[{"v":1,"file":"a.go","line":1,"function":"F","package":"pkg"}]}`},
+		"empty stack": {(&Stack{}).VersionedJSON(), `{"v":1,"frames":[]}`},
+		"nil stack":   {(*Stack)(nil).VersionedJSON(), `null`},
+		"plain stack": {s, `{"frames":[{"file":"a.go","line":1,"function":"F","package":"pkg"}]}`},
+	} {
+		b, err := json.Marshal(tc.v)
+		if err != nil {
+			t.Fatalf("%s: json.Marshal() error = %v", name, err)
+		}
+		if string(b) != tc.want {
+			t.Errorf("%s: json.Marshal() = %s, want %s", name, b, tc.want)
+		}
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if want := `{"file":"a.go","line":1,"function":"F","package":"pkg"}`; string(b) != want {
+		t.Errorf("json.Marshal() = %s, want %s", b, want)
+	}
+}
+
+// TestCallerInfo_UnmarshalJSON_Versions tests the forward-compatible
+// decoding rules for versioned caller payloads.
+func TestCallerInfo_UnmarshalJSON_Versions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		jsonData  string
+		expectErr bool
+	}{
+		{"no version", `{"file":"a.go","line":1,"function":"F","package":"pkg"}`, false},
+		{"current version", `{"v":1,"file":"a.go","line":1,"function":"F","package":"pkg"}`, false},
+		{"newer version with unknown fields", `{"v":7,"file":"a.go","line":1,"function":"F","package":"pkg","column":3}`, false},
+		{"negative version", `{"v":-1,"file":"a.go"}`, true},
+		{"non-integer version", `{"v":"one","file":"a.go"}`, true},
+	}
+	want := &callerInfo{file: "a.go", line: 1, fn: "pkg.F"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got callerInfo
+			err := json.Unmarshal([]byte(tt.jsonData), &got)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected an error, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(want) {
+				t.Errorf("UnmarshalJSON() got = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// TestStack_JSON tests Stack encoding, round trips and decoding rules.
+func TestStack_JSON(t *testing.T) {
+	t.Parallel()
+
+	s := &Stack{frames: []*callerInfo{
+		{file: "a.go", line: 1, fn: "pkg.A", dotIdx: 3},
+		{file: "b.go", line: 2, fn: "pkg.B", dotIdx: 3},
+	}}
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var got Stack
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got.Len() != 2 || !got.Frame(0).Equal(s.Frame(0)) || !got.Frame(1).Equal(s.Frame(1)) {
+		t.Errorf("round trip = %s, want %s", mustMarshal(t, &got), b)
+	}
+
+	live := stackHelper(0, WithBuildID("abc/def"))
+	var decoded Stack
+	if err := json.Unmarshal([]byte(mustMarshal(t, live)), &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if live.BuildID() != "abc/def" || decoded.BuildID() != "abc/def" {
+		t.Errorf("BuildID() = %q, decoded %q, want %q", live.BuildID(), decoded.BuildID(), "abc/def")
+	}
+	if got, ok := decoded.Build(); !ok || got != CurrentBuild() {
+		t.Errorf("decoded Build() = %+v, %v, want %+v", got, ok, CurrentBuild())
+	}
+
+	for name, tc := range map[string]struct {
+		v    any
+		want string
+	}{
+		"nil stack":   {(*Stack)(nil), `null`},
+		"empty stack": {&Stack{}, `{"frames":[]}`},
+		"build ID":    {&Stack{buildID: "abc/def"}, `{"build_id":"abc/def","frames":[]}`},
+		"build info": {
+			&Stack{build: &BuildInfo{GoVersion: "go1.23.0", GOOS: "linux", GOARCH: "amd64", Module: "example.com/app", ModuleVersion: "v1.2.3"}},
+			`{"build":{"go_version":"go1.23.0","goos":"linux","goarch":"amd64","module":"example.com/app","module_version":"v1.2.3"},"frames":[]}`,
+		},
+	} {
+		if got := mustMarshal(t, tc.v); got != tc.want {
+			t.Errorf("%s: json.Marshal() = %s, want %s", name, got, tc.want)
+		}
+	}
+
+	var withNulls Stack
+	if err := json.Unmarshal([]byte(`{"v":2,"frames":[null,{"file":"a.go"}],"extra":true}`), &withNulls); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got := withNulls.Len(); got != 1 {
+		t.Errorf("Len() = %d, want 1", got)
+	}
+
+	for _, data := range []string{`{"v":-1,"frames":[]}`, `[`, `{"frames":[{"line":-1}]}`} {
+		var bad Stack
+		if err := json.Unmarshal([]byte(data), &bad); err == nil {
+			t.Errorf("json.Unmarshal(%s) expected an error, but got nil", data)
+		}
+	}
+}
+
+// mustMarshal returns the JSON encoding of v as a string.
+func mustMarshal(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return string(b)
+}
@@ -0,0 +1,163 @@
+package caller
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// siteError is an error created by Errorf with at most one %w verb.
+type siteError struct {
+	msg    string
+	err    error // Error wrapped with %w, if any
+	caller Caller
+}
+
+// siteErrors is an error created by Errorf with several %w verbs.
+type siteErrors struct {
+	msg    string
+	errs   []error
+	caller Caller
+}
+
+// errorSite is implemented by errors that record where they were created.
+type errorSite interface {
+	errorSite() Caller
+}
+
+// Errorf formats an error like fmt.Errorf, including wrapping with %w,
+// and records the location of the call, which CallerFromError retrieves:
+//
+//	return caller.Errorf("load %s: %w", name, err)
+//
+// The message is exactly that of fmt.Errorf, and errors.Is, errors.As and
+// errors.Unwrap behave as for the error fmt.Errorf returns.
+func Errorf(format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	c := callSite(0)
+	// fmt.Errorf returns an error implementing one of the Unwrap forms
+	// when the format has %w verbs
+	if u, ok := err.(interface{ Unwrap() []error }); ok { //nolint:errorlint // inspecting the error fmt.Errorf returned, not a chain
+		return &siteErrors{msg: err.Error(), errs: u.Unwrap(), caller: c}
+	}
+	return &siteError{msg: err.Error(), err: errors.Unwrap(err), caller: c}
+}
+
+// Error implements the error interface.
+func (e *siteError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the error wrapped with %w, or nil if there is none.
+func (e *siteError) Unwrap() error {
+	return e.err
+}
+
+// errorSite returns the location of the call to Errorf.
+func (e *siteError) errorSite() Caller {
+	return e.caller
+}
+
+// Error implements the error interface.
+func (e *siteErrors) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the errors wrapped with %w.
+func (e *siteErrors) Unwrap() []error {
+	return e.errs
+}
+
+// errorSite returns the location of the call to Errorf.
+func (e *siteErrors) errorSite() Caller {
+	return e.caller
+}
+
+// errorSite returns the location of the call to Annotate.
+func (e *AnnotatedError) errorSite() Caller {
+	return e.Caller
+}
+
+// errorSite returns the location of the panic.
+func (e *PanicError) errorSite() Caller {
+	return e.Caller
+}
+
+// CallerFromError returns the location recorded by the first error in the
+// chain of err that carries one: an error created by Errorf, or an
+// *AnnotatedError or *PanicError. It returns nil if there is none.
+func CallerFromError(err error) Caller {
+	var es errorSite
+	if !errors.As(err, &es) {
+		return nil
+	}
+	return es.errorSite()
+}
+
+// Format implements the fmt.Formatter interface: %v and %s print the
+// message, %q prints it quoted, and %+v prints it followed by the
+// location of the call to Annotate, in Go runtime traceback format.
+func (e *AnnotatedError) Format(f fmt.State, verb rune) {
+	formatError(f, verb, e, func(b []byte) []byte {
+		return appendSite(b, e.Caller)
+	})
+}
+
+// Format implements the fmt.Formatter interface: %v and %s print the
+// message, %q prints it quoted, and %+v prints it followed by the stack
+// of the panic, or the location of the panic if the stack is unknown, in
+// Go runtime traceback format:
+//
+//	panic: boom [example.com/app.run (main.go:12)]
+//	example.com/app.run(...)
+//		/src/app/main.go:12 +0x25
+//	...
+func (e *PanicError) Format(f fmt.State, verb rune) {
+	formatError(f, verb, e, func(b []byte) []byte {
+		if e.Stack.Len() > 0 {
+			return e.Stack.appendFrames(b)
+		}
+		return appendSite(b, e.Caller)
+	})
+}
+
+// formatError formats err for verb, calling appendDetail to append the
+// lines that follow the message for %+v.
+func formatError(f fmt.State, verb rune, err error, appendDetail func([]byte) []byte) {
+	var b []byte
+	switch verb {
+	case 'v':
+		b = append(b, err.Error()...)
+		if f.Flag('+') {
+			if detail := appendDetail(nil); len(detail) > 0 {
+				b = append(b, '\n')
+				b = append(b, detail...)
+			}
+		}
+	case 's':
+		b = append(b, err.Error()...)
+	case 'q':
+		b = strconv.AppendQuote(b, err.Error())
+	default:
+		b = fmt.Appendf(b, "%%!%c(%T=%s)", verb, err, err.Error())
+	}
+	// fmt.State reports write errors through the result of the printing
+	// function, not here
+	_, _ = f.Write(b)
+}
+
+// appendSite appends c to b as a single frame in Go runtime traceback
+// format, or nothing if c is nil.
+func appendSite(b []byte, c Caller) []byte {
+	if isNil(c) {
+		return b
+	}
+	fn := c.FullFunction()
+	if fn == "" {
+		fn = "?"
+	}
+	b = append(b, fn...)
+	b = append(b, "(...)\n\t"...)
+	b = append(b, c.Location()...)
+	return append(b, '\n')
+}
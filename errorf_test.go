@@ -0,0 +1,126 @@
+package caller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"testing"
+)
+
+// TestErrorf tests that Errorf matches fmt.Errorf and records its call
+// site.
+func TestErrorf(t *testing.T) {
+	t.Parallel()
+
+	err := Errorf("read %s: %w", "config", io.EOF)
+	_, file, line, _ := runtime.Caller(0)
+	if c := CallerFromError(err); c.Function() != "TestErrorf" || c.File() != file || c.Line() != line-1 {
+		t.Errorf("CallerFromError() = %v, want %s:%d", c, file, line-1)
+	}
+
+	tests := []struct {
+		name       string
+		format     string
+		args       []any
+		wantIs     []error
+		wantUnwrap error
+	}{
+		{"no wrapping", "plain %d", []any{1}, nil, nil},
+		{"single", "read: %w", []any{io.EOF}, []error{io.EOF}, io.EOF},
+		{"multiple", "%w and %w", []any{io.EOF, os.ErrNotExist}, []error{io.EOF, os.ErrNotExist}, nil},
+		{"%v does not wrap", "read: %v", []any{io.EOF}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := Errorf(tt.format, tt.args...)
+			want := fmt.Errorf(tt.format, tt.args...)
+			if got.Error() != want.Error() {
+				t.Errorf("Error() = %q, want %q", got.Error(), want.Error())
+			}
+			for _, target := range tt.wantIs {
+				if !errors.Is(got, target) {
+					t.Errorf("errors.Is(%v) = false, want true", target)
+				}
+			}
+			if u := errors.Unwrap(got); u != tt.wantUnwrap { //nolint:errorlint // checking the exact unwrapped value
+				t.Errorf("errors.Unwrap() = %v, want %v", u, tt.wantUnwrap)
+			}
+			if CallerFromError(got) == nil {
+				t.Error("CallerFromError() = nil, want the call site")
+			}
+		})
+	}
+}
+
+// TestCallerFromError tests finding the recorded location in error
+// chains.
+func TestCallerFromError(t *testing.T) {
+	t.Parallel()
+
+	c := &callerInfo{file: "/src/a.go", line: 3, fn: "pkg.A", dotIdx: 3}
+	inner := Errorf("inner")
+	tests := []struct {
+		name string
+		err  error
+		want Caller
+	}{
+		{"nil", nil, nil},
+		{"plain", io.EOF, nil},
+		{"annotated", &AnnotatedError{Caller: c, Err: io.EOF}, c},
+		{"panic", &PanicError{Value: "boom", Caller: c}, c},
+		{"wrapped by fmt", fmt.Errorf("outer: %w", &AnnotatedError{Caller: c}), c},
+		{"outermost first", &AnnotatedError{Caller: c, Err: inner}, c},
+		{"inner", fmt.Errorf("outer: %w", inner), CallerFromError(inner)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := CallerFromError(tt.err)
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(tt.want)) {
+				t.Errorf("CallerFromError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestErrorFormat tests the %v, %s, %q and %+v forms of the errors that
+// carry a location.
+func TestErrorFormat(t *testing.T) {
+	t.Parallel()
+
+	site := &callerInfo{file: "/src/app/main.go", line: 12, fn: "example.com/app.run", dotIdx: 15}
+	annotated := &AnnotatedError{Msg: "load", Caller: site, Err: io.EOF}
+	unknown := &AnnotatedError{Msg: "load", Err: io.EOF}
+	stack := &Stack{frames: []*callerInfo{site, {file: "/src/app/main.go", line: 5, fn: "example.com/app.main", dotIdx: 15}}}
+	panicked := &PanicError{Value: "boom", Caller: site, Stack: stack}
+	noStack := &PanicError{Value: "boom", Caller: site}
+
+	tests := []struct {
+		name   string
+		format string
+		err    error
+		want   string
+	}{
+		{"annotated %v", "%v", annotated, annotated.Error()},
+		{"annotated %s", "%s", annotated, annotated.Error()},
+		{"annotated %q", "%q", annotated, `"load (main.go:12): EOF"`},
+		{"annotated %+v", "%+v", annotated, "load (main.go:12): EOF\nexample.com/app.run(...)\n\t/src/app/main.go:12\n"},
+		{"unknown site %+v", "%+v", unknown, "load: EOF"},
+		{"panic %v", "%v", panicked, panicked.Error()},
+		{"panic %+v", "%+v", panicked, panicked.Error() + "\nexample.com/app.run(...)\n\t/src/app/main.go:12\nexample.com/app.main(...)\n\t/src/app/main.go:5\n"},
+		{"panic without stack %+v", "%+v", noStack, noStack.Error() + "\nexample.com/app.run(...)\n\t/src/app/main.go:12\n"},
+		{"wrapped %+v", "%+v", fmt.Errorf("outer: %w", annotated), "outer: load (main.go:12): EOF"},
+		{"bad verb", "%d", annotated, "%!d(*caller.AnnotatedError=load (main.go:12): EOF)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := fmt.Sprintf(tt.format, tt.err); got != tt.want {
+				t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
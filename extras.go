@@ -0,0 +1,75 @@
+package caller
+
+import "time"
+
+// frameExtras holds the optional data of a callerInfo. Most captures
+// carry none of it, so it is allocated only when first set, keeping
+// plain captures and the frames of stacks small.
+type frameExtras struct {
+	at     time.Time         // Capture time, if recorded with WithTimestamp
+	recap  *recaptureContext // Stack the caller was captured from, if recorded with WithRecapture
+	labels []string          // Labels attached by decorators
+}
+
+// frameFlags is a set of boolean properties of a frame, stored in the
+// padding after its dotIdx.
+type frameFlags uint8
+
+const (
+	flagHidden   frameFlags = 1 << iota // A decorator hid the frame
+	flagInApp                           // The frame is application code
+	flagOwnsFile                        // The file path was allocated for the frame, rather than pointing into the executable
+	flagOwnsFunc                        // The function name was allocated for the frame, rather than pointing into the executable
+	flagInlined                         // The compiler inlined the frame into the next one
+
+	// flagOwnsStrings marks frames decoded or parsed from text
+	flagOwnsStrings = flagOwnsFile | flagOwnsFunc
+)
+
+// extras returns the optional data of c, allocating it if needed.
+func (c *callerInfo) extras() *frameExtras {
+	if c.extra == nil {
+		c.extra = &frameExtras{}
+	}
+	return c.extra
+}
+
+// capturedAt returns the capture time of c, or the zero time if it was
+// not recorded.
+func (c *callerInfo) capturedAt() time.Time {
+	if c.extra == nil {
+		return time.Time{}
+	}
+	return c.extra.at
+}
+
+// setCapturedAt records t as the capture time of c; the zero time
+// allocates nothing.
+func (c *callerInfo) setCapturedAt(t time.Time) {
+	if !t.IsZero() || c.extra != nil {
+		c.extras().at = t
+	}
+}
+
+// recapture returns the stack c was captured from, or nil if it was not
+// kept.
+func (c *callerInfo) recapture() *recaptureContext {
+	if c.extra == nil {
+		return nil
+	}
+	return c.extra.recap
+}
+
+// has reports whether all of flags are set on c.
+func (c *callerInfo) has(flags frameFlags) bool {
+	return c.flags&flags == flags
+}
+
+// setFlag sets or clears flag on c.
+func (c *callerInfo) setFlag(flag frameFlags, on bool) {
+	if on {
+		c.flags |= flag
+	} else {
+		c.flags &^= flag
+	}
+}
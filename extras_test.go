@@ -0,0 +1,41 @@
+package caller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestFrameExtras tests that optional capture data is allocated only when
+// set, and flags never, keeping plain captures at their original size.
+func TestFrameExtras(t *testing.T) {
+	t.Parallel()
+
+	if got, want := reflect.TypeFor[callerInfo]().Size(), 7*reflect.TypeFor[uintptr]().Size(); got > want {
+		t.Errorf("callerInfo size = %d, want at most %d", got, want)
+	}
+
+	c, ok := Immediate().(*callerInfo)
+	if !ok || c.extra != nil {
+		t.Fatalf("Immediate() = %#v, want a callerInfo without extras", c)
+	}
+	c.setCapturedAt(time.Time{})
+	c.setFlag(flagInApp, true)
+	c.setFlag(flagHidden, true)
+	if c.extra != nil {
+		t.Error("flags or a zero capture time allocated extras, want none")
+	}
+	if !c.has(flagInApp|flagHidden) || !InApp(c) || !c.hidden() {
+		t.Error("has() = false after setFlag(), want true")
+	}
+	c.setFlag(flagHidden, false)
+	if c.has(flagHidden) || !c.has(flagInApp) {
+		t.Error("setFlag(flagHidden, false) changed other flags or kept the flag")
+	}
+
+	at := time.Now()
+	c.setCapturedAt(at)
+	if !c.CapturedAt().Equal(at) {
+		t.Errorf("CapturedAt() = %v, want %v", c.CapturedAt(), at)
+	}
+}
@@ -0,0 +1,121 @@
+package caller
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Func describes a function of the running program. It wraps
+// runtime.Func with the name components and location helpers of Caller,
+// for code that works with functions rather than call sites, such as
+// registries of handlers or callbacks. The zero Func is invalid.
+type Func struct {
+	f  *runtime.Func
+	id callerInfo // Function name and its package separator only
+}
+
+// FuncOf returns the function containing the program counter pc. It
+// reports false if pc does not belong to a known function.
+func FuncOf(pc uintptr) (Func, bool) {
+	f := runtime.FuncForPC(pc)
+	if f == nil {
+		return Func{}, false
+	}
+	name := f.Name()
+	return Func{f: f, id: callerInfo{fn: name, dotIdx: functionNameIndex(name)}}, true
+}
+
+// FuncOfValue returns the function that the function value fn calls. It
+// returns ErrNotFunc if fn is not a non-nil function. As with
+// DefinitionSite, a method value yields a compiler-generated wrapper,
+// whose name ends in "-fm".
+func FuncOfValue(fn any) (Func, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return Func{}, fmt.Errorf("%w: %T", ErrNotFunc, fn)
+	}
+	f, ok := FuncOf(v.Pointer())
+	if !ok {
+		return Func{}, fmt.Errorf("%w: no function at %#x", ErrNoDefinition, v.Pointer())
+	}
+	return f, nil
+}
+
+// Valid reports whether f describes a function.
+func (f Func) Valid() bool {
+	return f.f != nil
+}
+
+// Runtime returns the underlying runtime.Func, or nil if f is invalid.
+func (f Func) Runtime() *runtime.Func {
+	return f.f
+}
+
+// Entry returns the entry address of the function, or 0 if f is invalid.
+func (f Func) Entry() uintptr {
+	if f.f == nil {
+		return 0
+	}
+	return f.f.Entry()
+}
+
+// FullFunction returns the full function name including package.
+func (f Func) FullFunction() string {
+	return f.id.FullFunction()
+}
+
+// Function returns the function name without the package.
+func (f Func) Function() string {
+	return f.id.Function()
+}
+
+// Package returns the full import path of the package.
+func (f Func) Package() string {
+	return f.id.Package()
+}
+
+// PackageName returns the name of the package without the directory.
+func (f Func) PackageName() string {
+	return f.id.PackageName()
+}
+
+// String returns the full function name.
+func (f Func) String() string {
+	return f.id.FullFunction()
+}
+
+// FileLine returns the file and line of the source code for the program
+// counter pc within the function, as runtime.Func.FileLine does. It
+// returns an empty file and 0 if f is invalid.
+func (f Func) FileLine(pc uintptr) (string, int) {
+	if f.f == nil {
+		return "", 0
+	}
+	return f.f.FileLine(pc)
+}
+
+// CallerAt returns a Caller for the program counter pc within the
+// function, with the file path mapped as for a live capture. It returns
+// nil if f is invalid.
+func (f Func) CallerAt(pc uintptr) Caller {
+	if f.f == nil {
+		return nil
+	}
+	file, line := f.f.FileLine(pc)
+	return newCallerInfo(file, line, f.id.fn)
+}
+
+// DefinitionSite returns the location where the function is defined, as
+// the package-level DefinitionSite does for a function value.
+func (f Func) DefinitionSite() (Caller, error) {
+	if f.f == nil {
+		return nil, fmt.Errorf("%w: invalid function", ErrNoDefinition)
+	}
+	file, line := f.f.FileLine(f.f.Entry())
+	if file == autogeneratedFile || file == "" {
+		return nil, fmt.Errorf("%w: %s is compiler-generated", ErrNoDefinition, strings.TrimSuffix(f.id.fn, "-fm"))
+	}
+	return newCallerInfo(file, line, f.id.fn), nil
+}
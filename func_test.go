@@ -0,0 +1,100 @@
+package caller
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestFuncOfValue tests the name components and definition site of a
+// function value.
+func TestFuncOfValue(t *testing.T) {
+	t.Parallel()
+
+	f, err := FuncOfValue(definitionTarget)
+	if err != nil {
+		t.Fatalf("FuncOfValue() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"FullFunction", f.FullFunction(), "github.com/balinomad/go-caller/v2.definitionTarget"},
+		{"Function", f.Function(), "definitionTarget"},
+		{"Package", f.Package(), "github.com/balinomad/go-caller/v2"},
+		{"PackageName", f.PackageName(), "v2"},
+		{"String", f.String(), "github.com/balinomad/go-caller/v2.definitionTarget"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if tt.got != tt.want {
+				t.Errorf("%s() = %q, want %q", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+
+	if !f.Valid() || f.Runtime() == nil || f.Entry() != f.Runtime().Entry() {
+		t.Errorf("FuncOfValue() = %+v, want a valid function", f)
+	}
+	site, err := f.DefinitionSite()
+	if err != nil || site.Line() != declLine(t, "func definitionTarget()") {
+		t.Errorf("DefinitionSite() = %v, %v, want the declaration line", site, err)
+	}
+	if file, line := f.FileLine(f.Entry()); file != site.File() || line != site.Line() {
+		t.Errorf("FileLine() = %s:%d, want %v", file, line, site)
+	}
+}
+
+// TestFuncOf tests resolving a program counter to its function and a
+// Caller within it.
+func TestFuncOf(t *testing.T) {
+	t.Parallel()
+
+	pc, _, line, _ := runtime.Caller(0)
+	f, ok := FuncOf(pc)
+	if !ok || f.Function() != "TestFuncOf" {
+		t.Fatalf("FuncOf() = %v, %t, want TestFuncOf", f, ok)
+	}
+	c := f.CallerAt(pc)
+	if c == nil || c.Line() != line || c.Function() != "TestFuncOf" {
+		t.Errorf("CallerAt() = %v, want line %d of TestFuncOf", c, line)
+	}
+
+	if _, ok := FuncOf(0); ok {
+		t.Error("FuncOf(0) reports a function")
+	}
+}
+
+// TestFunc_Invalid tests the zero Func and invalid function values.
+func TestFunc_Invalid(t *testing.T) {
+	t.Parallel()
+
+	var f Func
+	if f.Valid() || f.Runtime() != nil || f.Entry() != 0 || f.String() != "" || f.CallerAt(1) != nil {
+		t.Errorf("zero Func = %+v, want invalid", f)
+	}
+	if file, line := f.FileLine(1); file != "" || line != 0 {
+		t.Errorf("zero Func FileLine() = %s:%d, want none", file, line)
+	}
+	if _, err := f.DefinitionSite(); !errors.Is(err, ErrNoDefinition) {
+		t.Errorf("zero Func DefinitionSite() error = %v, want %v", err, ErrNoDefinition)
+	}
+
+	var nilFunc func()
+	for _, v := range []any{nil, 42, nilFunc} {
+		if _, err := FuncOfValue(v); !errors.Is(err, ErrNotFunc) {
+			t.Errorf("FuncOfValue(%T) error = %v, want %v", v, err, ErrNotFunc)
+		}
+	}
+
+	f, err := FuncOfValue(definitionType{}.Value)
+	if err != nil || !strings.HasSuffix(f.FullFunction(), "-fm") {
+		t.Fatalf("FuncOfValue(method value) = %v, %v, want a -fm wrapper", f, err)
+	}
+	if _, err := f.DefinitionSite(); !errors.Is(err, ErrNoDefinition) {
+		t.Errorf("DefinitionSite() of a method value error = %v, want %v", err, ErrNoDefinition)
+	}
+}
@@ -0,0 +1,116 @@
+package caller
+
+import (
+	"errors"
+	"runtime"
+)
+
+// ErrDisallowedCaller is matched by every GuardError.
+var ErrDisallowedCaller = errors.New("disallowed caller")
+
+// GuardError reports that an API was invoked from a disallowed location.
+type GuardError struct {
+	Callee Caller // Function that performed the check
+	Caller Caller // First caller outside the callee's package; nil if there is none
+}
+
+// Error implements the error interface.
+func (e *GuardError) Error() string {
+	callee := "function"
+	if !isNil(e.Callee) && e.Callee.FullFunction() != "" {
+		callee = e.Callee.FullFunction()
+	}
+	if isNil(e.Caller) {
+		return callee + " called without an external caller, which is not allowed"
+	}
+	return callee + " called from " + e.Caller.FullFunction() + " (" + e.Caller.ShortLocation() + "), which is not allowed"
+}
+
+// Unwrap returns ErrDisallowedCaller.
+func (e *GuardError) Unwrap() error {
+	return ErrDisallowedCaller
+}
+
+// EnsureCalledFrom checks that the function calling it was invoked from
+// code matching at least one of matchers, and returns a *GuardError if it
+// was not. It is a runtime complement to linters for enforcing layering:
+//
+//	func (s *Store) rawQuery(q string) error {
+//		if err := caller.EnsureCalledFrom(caller.MatchPackage("example.com/app/internal/...")); err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// The checked caller is the first frame outside the package of the
+// function calling EnsureCalledFrom, so helpers within that package do
+// not need to be listed.
+func EnsureCalledFrom(matchers ...Matcher) error {
+	return checkCalledFrom(true, matchers)
+}
+
+// ForbidCalledFrom checks that the function calling it was not invoked
+// from code matching any of matchers, and returns a *GuardError if it was.
+// The checked caller is determined as for EnsureCalledFrom.
+func ForbidCalledFrom(matchers ...Matcher) error {
+	return checkCalledFrom(false, matchers)
+}
+
+// MustBeCalledFrom is like EnsureCalledFrom but panics with the
+// *GuardError instead of returning it, for code where a layering
+// violation is a programming error that should fail loudly.
+func MustBeCalledFrom(matchers ...Matcher) {
+	if err := checkCalledFrom(true, matchers); err != nil {
+		panic(err) //nolint:forbidigo // panicking is what the caller asked for
+	}
+}
+
+// MustNotBeCalledFrom is like ForbidCalledFrom but panics with the
+// *GuardError instead of returning it.
+func MustNotBeCalledFrom(matchers ...Matcher) {
+	if err := checkCalledFrom(false, matchers); err != nil {
+		panic(err) //nolint:forbidigo // panicking is what the caller asked for
+	}
+}
+
+// checkCalledFrom checks the first caller outside the package of the
+// function calling the exported guard that called checkCalledFrom: it
+// must match one of matchers if allow is set, and none of them otherwise.
+func checkCalledFrom(allow bool, matchers []Matcher) error {
+	// Skip checkCalledFrom and the exported guard
+	callee, outside := externalCaller(2)
+	if outside != nil && matchAny(matchers, outside) == allow {
+		return nil
+	}
+	if outside == nil && !allow {
+		return nil
+	}
+	err := &GuardError{Callee: callee}
+	if outside != nil {
+		err.Caller = outside
+	}
+	return err
+}
+
+// externalCaller returns the function skip frames above the caller of
+// externalCaller (the callee), and the first frame above it that belongs
+// to a different package. Either result is nil if it cannot be determined.
+func externalCaller(skip int) (*callerInfo, *callerInfo) {
+	frames := runtime.CallersFrames(callers(skip + 1))
+
+	frame, more := frames.Next()
+	if frame.Function == "" && frame.File == "" {
+		return nil, nil
+	}
+	callee := frameCallerInfo(frame)
+	pkg := callee.Package()
+
+	for more {
+		frame, more = frames.Next()
+		c := frameCallerInfo(frame)
+		if c.Package() != pkg {
+			return callee, c
+		}
+	}
+	return callee, nil
+}
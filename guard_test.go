@@ -0,0 +1,98 @@
+package caller
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// guardedAPI stands in for an internal API. Tests call it from this
+// package, so the first caller outside the package is testing.tRunner.
+func guardedAPI(forbid bool, matchers ...Matcher) error {
+	if forbid {
+		return ForbidCalledFrom(matchers...)
+	}
+	return EnsureCalledFrom(matchers...)
+}
+
+// TestEnsureCalledFrom tests allowed and disallowed callers.
+func TestEnsureCalledFrom(t *testing.T) {
+	t.Parallel()
+
+	if err := guardedAPI(false, MatchPackage("testing")); err != nil {
+		t.Errorf("EnsureCalledFrom(testing) error = %v, want nil", err)
+	}
+
+	err := guardedAPI(false, MatchPackage("example.com/app/..."))
+	var ge *GuardError
+	if !errors.As(err, &ge) {
+		t.Fatalf("EnsureCalledFrom(example.com/app/...) error = %v, want *GuardError", err)
+	}
+	if !errors.Is(err, ErrDisallowedCaller) {
+		t.Error("GuardError should match ErrDisallowedCaller")
+	}
+	if got, want := ge.Callee.Function(), "guardedAPI"; got != want {
+		t.Errorf("Callee.Function() = %q, want %q", got, want)
+	}
+	if got, want := ge.Caller.FullFunction(), "testing.tRunner"; got != want {
+		t.Errorf("Caller.FullFunction() = %q, want %q", got, want)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "guardedAPI called from testing.tRunner") {
+		t.Errorf("Error() = %q, want it to name callee and caller", msg)
+	}
+}
+
+// TestForbidCalledFrom tests forbidden and permitted callers.
+func TestForbidCalledFrom(t *testing.T) {
+	t.Parallel()
+
+	if err := guardedAPI(true, MatchPackage("example.com/app")); err != nil {
+		t.Errorf("ForbidCalledFrom(example.com/app) error = %v, want nil", err)
+	}
+	if err := guardedAPI(true, MatchPackage("testing")); !errors.Is(err, ErrDisallowedCaller) {
+		t.Errorf("ForbidCalledFrom(testing) error = %v, want ErrDisallowedCaller", err)
+	}
+}
+
+// TestGuardError_Error tests the message when parts are missing.
+func TestGuardError_Error(t *testing.T) {
+	t.Parallel()
+
+	err := &GuardError{}
+	if got, want := err.Error(), "function called without an external caller, which is not allowed"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+// TestMustBeCalledFrom tests that the panicking guards panic with the
+// error the others return, and only on a violation.
+func TestMustBeCalledFrom(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		guard     func()
+		wantPanic bool
+	}{
+		{"allowed", func() { MustBeCalledFrom(MatchPackage("testing")) }, false},
+		{"not allowed", func() { MustBeCalledFrom(MatchPackage("example.com/app/...")) }, true},
+		{"not forbidden", func() { MustNotBeCalledFrom(MatchPackage("example.com/app/...")) }, false},
+		{"forbidden", func() { MustNotBeCalledFrom(MatchPackage("testing")) }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			defer func() {
+				r := recover()
+				err, ok := r.(*GuardError)
+				if ok != tt.wantPanic {
+					t.Errorf("recover() = %v, want a *GuardError: %v", r, tt.wantPanic)
+				}
+				if ok && !strings.HasPrefix(err.Callee.FullFunction(), thisPackage+".TestMustBeCalledFrom.") {
+					t.Errorf("Callee = %v, want the closure calling the guard", err.Callee)
+				}
+			}()
+			tt.guard()
+		})
+	}
+}
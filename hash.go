@@ -0,0 +1,45 @@
+package caller
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+)
+
+// Key is a comparable identity of a call site: two callers have the same
+// Key exactly when EqualCallers reports them equal. Use it as a built-in
+// map key, or canonicalize it with the unique package to share one copy
+// per call site across high-cardinality structures:
+//
+//	h := unique.Make(caller.KeyOf(c))
+type Key struct {
+	File     string // Full file path
+	Line     int    // Line number
+	Function string // Full function name including package
+}
+
+// KeyOf returns the Key of c, or the zero Key if c is nil.
+func KeyOf(c Caller) Key {
+	return Key{File: File(c), Line: Line(c), Function: FullFunction(c)}
+}
+
+// HashCaller returns a hash of the file, line and full function name of c,
+// consistent with EqualCallers, for custom hash maps keyed by call site.
+// Hashes are only comparable between calls with the same seed.
+// A nil caller hashes like the zero Key.
+func HashCaller(seed maphash.Seed, c Caller) uint64 {
+	var h maphash.Hash
+	h.SetSeed(seed)
+	h.WriteString(File(c))
+	var line [8]byte
+	binary.LittleEndian.PutUint64(line[:], uint64(Line(c)))
+	h.Write(line[:])
+	h.WriteString(FullFunction(c))
+	return h.Sum64()
+}
+
+// EqualCallers reports whether a and b have the same file, line and full
+// function name. Unlike Caller.Equal, it treats two nil callers as equal,
+// as hash maps require of their equality function.
+func EqualCallers(a, b Caller) bool {
+	return KeyOf(a) == KeyOf(b)
+}
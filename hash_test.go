@@ -0,0 +1,52 @@
+package caller
+
+import (
+	"hash/maphash"
+	"testing"
+	"unique"
+)
+
+// TestHashCaller tests that HashCaller, EqualCallers and KeyOf agree on
+// which callers identify the same call site.
+func TestHashCaller(t *testing.T) {
+	t.Parallel()
+
+	seed := maphash.MakeSeed()
+	a := &callerInfo{file: "/src/a.go", line: 1, fn: "pkg.A", dotIdx: 3}
+	var typedNil *callerInfo
+
+	tests := []struct {
+		name string
+		x, y Caller
+		want bool
+	}{
+		{"same pointer", a, a, true},
+		{"equal fields", a, &callerInfo{file: "/src/a.go", line: 1, fn: "pkg.A"}, true},
+		{"other implementation", a, &mockCaller{file: "/src/a.go", line: 1, fullFn: "pkg.A"}, true},
+		{"different line", a, &callerInfo{file: "/src/a.go", line: 2, fn: "pkg.A", dotIdx: 3}, false},
+		{"different file", a, &callerInfo{file: "/src/b.go", line: 1, fn: "pkg.A", dotIdx: 3}, false},
+		{"different function", a, &callerInfo{file: "/src/a.go", line: 1, fn: "pkg.B", dotIdx: 3}, false},
+		{"both nil", nil, typedNil, true},
+		{"nil and valid", nil, a, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := EqualCallers(tt.x, tt.y); got != tt.want {
+				t.Errorf("EqualCallers() = %v, want %v", got, tt.want)
+			}
+			if got := KeyOf(tt.x) == KeyOf(tt.y); got != tt.want {
+				t.Errorf("KeyOf() equal = %v, want %v", got, tt.want)
+			}
+			if got := unique.Make(KeyOf(tt.x)) == unique.Make(KeyOf(tt.y)); got != tt.want {
+				t.Errorf("unique.Make(KeyOf()) equal = %v, want %v", got, tt.want)
+			}
+			if tt.want && HashCaller(seed, tt.x) != HashCaller(seed, tt.y) {
+				t.Error("HashCaller() differs for equal callers")
+			}
+			if !tt.want && HashCaller(seed, tt.x) == HashCaller(seed, tt.y) {
+				t.Error("HashCaller() collides for different callers")
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package caller
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// captureHook is a registered OnCapture function. Hooks are stored by
+// pointer so that each registration can be removed individually.
+type captureHook struct {
+	fn func(Caller)
+}
+
+var (
+	// hooksMu serializes changes to hooks.
+	hooksMu sync.Mutex
+
+	// hooks holds the registered capture hooks as a copy-on-write slice.
+	// It is nil when no hook is registered, so the capture path pays for
+	// a single atomic load when the feature is unused.
+	hooks atomic.Pointer[[]*captureHook]
+)
+
+// OnCapture registers fn to be called with every Caller captured by New,
+// Immediate, NewFromPC and Recorder.Capture, so that audit and security
+// tooling can observe where captures happen. It returns a function that
+// unregisters fn; calling it more than once has no further effect.
+//
+// Hooks run synchronously on the capturing goroutine, in registration
+// order, and must be safe for concurrent use. A hook must not retain the
+// Caller beyond what it needs, and should return quickly.
+func OnCapture(fn func(Caller)) func() {
+	if fn == nil {
+		return func() {}
+	}
+	h := &captureHook{fn: fn}
+
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	var list []*captureHook
+	if cur := hooks.Load(); cur != nil {
+		list = slices.Clone(*cur)
+	}
+	list = append(list, h)
+	hooks.Store(&list)
+
+	return func() {
+		hooksMu.Lock()
+		defer hooksMu.Unlock()
+		cur := hooks.Load()
+		if cur == nil {
+			return
+		}
+		list := slices.DeleteFunc(slices.Clone(*cur), func(other *captureHook) bool { return other == h })
+		if len(list) == 0 {
+			hooks.Store(nil)
+			return
+		}
+		hooks.Store(&list)
+	}
+}
+
+// runHooks calls every registered capture hook with c.
+func runHooks(c Caller) {
+	list := hooks.Load()
+	if list == nil {
+		return
+	}
+	for _, h := range *list {
+		h.fn(c)
+	}
+}
@@ -0,0 +1,49 @@
+package caller
+
+import "testing"
+
+// TestOnCapture tests that hooks see captures in registration order and
+// stop receiving them once removed.
+func TestOnCapture(t *testing.T) {
+	var first, second []Caller
+	removeFirst := OnCapture(func(c Caller) { first = append(first, c) })
+	removeSecond := OnCapture(func(c Caller) { second = append(second, c) })
+	t.Cleanup(removeFirst)
+	t.Cleanup(removeSecond)
+
+	c := Immediate()
+	if len(first) != 1 || !first[0].Equal(c) {
+		t.Errorf("first hook saw %v, want [%v]", first, c)
+	}
+	if len(second) != 1 || !second[0].Equal(c) {
+		t.Errorf("second hook saw %v, want [%v]", second, c)
+	}
+
+	removeFirst()
+	removeFirst() // no effect
+	NewRecorder(1).Capture("")
+	if len(first) != 1 {
+		t.Errorf("removed hook saw %d captures, want 1", len(first))
+	}
+	if len(second) != 2 {
+		t.Errorf("remaining hook saw %d captures, want 2", len(second))
+	}
+
+	removeSecond()
+	if hooks.Load() != nil {
+		t.Error("hooks should be nil once every hook is removed")
+	}
+	Immediate()
+	if len(second) != 2 {
+		t.Errorf("removed hook saw %d captures, want 2", len(second))
+	}
+}
+
+// TestOnCapture_Nil tests that a nil hook is ignored.
+func TestOnCapture_Nil(t *testing.T) {
+	remove := OnCapture(nil)
+	if hooks.Load() != nil {
+		t.Error("OnCapture(nil) should not register a hook")
+	}
+	remove()
+}
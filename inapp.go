@@ -0,0 +1,71 @@
+package caller
+
+import (
+	"slices"
+	"strings"
+)
+
+// InAppRules classifies captured frames as application code, "in-app"
+// frames, or as code of dependencies, so that error trackers and other
+// downstream tools can highlight the former and collapse the latter. A
+// frame is in-app if it matches any of the rules.
+type InAppRules struct {
+	Modules      []string // Import paths whose packages, and those below them, are in-app; "main" matches package main
+	PathPrefixes []string // Prefixes of the file paths of in-app source files, after any FileMapper
+}
+
+// WithInAppRules makes a capture classify the frames it resolves by
+// rules. The result is stored with each frame, read back with InApp, and
+// encoded in its JSON and slog output as "in_app":
+//
+//	var inApp = caller.WithInAppRules(caller.InAppRules{
+//		Modules: []string{caller.CurrentBuild().Module, "main"},
+//	})
+//
+//	s := caller.NewStack(0, inApp)
+//
+// Rules without modules or path prefixes classify no frame as in-app.
+// Decorators given with WithDecorator see the classification in
+// FrameInfo and may override it.
+func WithInAppRules(rules InAppRules) Option {
+	rules.Modules = slices.Clone(rules.Modules)
+	rules.PathPrefixes = slices.Clone(rules.PathPrefixes)
+	return func(cfg *captureConfig) {
+		cfg.inApp = &rules
+	}
+}
+
+// Match reports whether c is in-app under r, for classifying callers
+// after the fact, such as those decoded from JSON. It returns false if c
+// is nil.
+func (r InAppRules) Match(c Caller) bool {
+	return r.match(File(c), FullFunction(c))
+}
+
+// match reports whether the frame in file, of the function with the full
+// name fn, is in-app under r.
+func (r InAppRules) match(file, fn string) bool {
+	for _, prefix := range r.PathPrefixes {
+		if prefix != "" && strings.HasPrefix(file, prefix) {
+			return true
+		}
+	}
+	if i := functionNameIndex(fn); i > 0 {
+		pkg := fn[:i]
+		for _, m := range r.Modules {
+			if m != "" && (pkg == m || strings.HasPrefix(pkg, m+"/")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// InApp reports whether c was classified as application code when it was
+// captured, by the rules given with WithInAppRules or by a decorator. It
+// returns false if c is nil, was captured without rules, or is not a
+// Caller of this package.
+func InApp(c Caller) bool {
+	ci, ok := c.(*callerInfo)
+	return ok && ci != nil && ci.has(flagInApp)
+}
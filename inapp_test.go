@@ -0,0 +1,146 @@
+package caller
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// inAppStack captures a stack whose first frame is its caller.
+func inAppStack(opts ...Option) *Stack {
+	return NewStack(0, append(opts, KeepAllFrames())...)
+}
+
+// inAppCaller captures its caller with NewWith and opts.
+func inAppCaller(opts ...Option) Caller {
+	return NewWith(0, opts...)
+}
+
+// TestWithInAppRules tests classifying captured frames by module and
+// path prefix, overriding the classification in decorators, and leaving
+// captures without rules unclassified.
+func TestWithInAppRules(t *testing.T) {
+	t.Parallel()
+
+	if InApp(Immediate()) {
+		t.Error("InApp(Immediate()) = true without rules, want false")
+	}
+
+	// The module prefix must match whole path elements
+	prefix := WithInAppRules(InAppRules{Modules: []string{"github.com/balinomad/go-call", ""}})
+	if c := inAppCaller(prefix); InApp(c) {
+		t.Errorf("InApp(%v) = true for a module that only shares a prefix, want false", c)
+	}
+
+	module := WithInAppRules(InAppRules{Modules: []string{"github.com/balinomad/go-caller/v2"}})
+	s := inAppStack(module)
+	if !InApp(s.Caller0()) {
+		t.Errorf("InApp(%v) = false for a frame of the module, want true", s.Caller0())
+	}
+	for _, f := range s.Frames() {
+		if strings.HasPrefix(f.FullFunction(), "testing.") && InApp(f) {
+			t.Errorf("InApp(%v) = true for a standard library frame, want false", f)
+		}
+	}
+	if InApp(inAppStack().Caller0()) {
+		t.Error("InApp() = true for a stack captured without rules, want false")
+	}
+	if c := inAppCaller(module, WithRecapture()); !InApp(c) {
+		t.Errorf("InApp(%v) = false with WithRecapture, want true", c)
+	}
+
+	c := inAppCaller(module)
+	b, err := json.Marshal(c)
+	if err != nil || !strings.HasSuffix(string(b), `,"in_app":true}`) {
+		t.Errorf("json.Marshal() = %s, %v, want an in_app field", b, err)
+	}
+	got := NewEmpty()
+	if err := json.Unmarshal(b, got); err != nil || !InApp(got) {
+		t.Errorf("json.Unmarshal(%s) = %v, %v, want an in-app caller", b, got, err)
+	}
+	if err := json.Unmarshal([]byte(`{"file":"a.go"}`), got); err != nil || InApp(got) {
+		t.Errorf("json.Unmarshal() into an in-app caller = %v, %v, want it not in-app", got, err)
+	}
+	if !InApp(Normalize(c, IgnoreMachinePaths())) {
+		t.Error("InApp(Normalize()) = false, want true")
+	}
+
+	var found bool
+	for _, a := range c.LogValue().Group() {
+		found = found || a.Key == "in_app" && a.Value.Kind() == slog.KindBool && a.Value.Bool()
+	}
+	if !found {
+		t.Errorf("LogValue() = %v, want an in_app attribute", c.LogValue())
+	}
+
+	// File path prefixes match after the FileMapper
+	if InApp(inAppCaller(WithInAppRules(InAppRules{PathPrefixes: []string{"/nowhere/"}}))) {
+		t.Error("InApp(inAppCaller()) = true outside the path prefix, want false")
+	}
+	if !InApp(inAppCaller(WithInAppRules(InAppRules{PathPrefixes: []string{Immediate().File()[:1]}}))) {
+		t.Error("InApp(inAppCaller()) = false inside the path prefix, want true")
+	}
+
+	flip := WithDecorator(func(f *FrameInfo) {
+		f.InApp = !f.InApp
+	})
+	if InApp(inAppCaller(module, flip)) {
+		t.Error("InApp(inAppCaller()) = true after a decorator cleared it, want false")
+	}
+}
+
+// TestInAppRules_Match tests classifying callers after the fact.
+func TestInAppRules_Match(t *testing.T) {
+	t.Parallel()
+
+	rules := InAppRules{Modules: []string{"example.com/app", ""}, PathPrefixes: []string{"/src/app/"}}
+	tests := []struct {
+		name string
+		c    Caller
+		want bool
+	}{
+		{"nil", nil, false},
+		{"module", &callerInfo{file: "x.go", fn: "example.com/app/db.Open", dotIdx: 18}, true},
+		{"module root", &callerInfo{file: "x.go", fn: "example.com/app.Run", dotIdx: 15}, true},
+		{"module prefix only", &callerInfo{file: "x.go", fn: "example.com/apps.Run", dotIdx: 16}, false},
+		{"path prefix", &callerInfo{file: "/src/app/main.go", fn: "main.main", dotIdx: 4}, true},
+		{"dependency", &callerInfo{file: "/go/pkg/mod/x.go", fn: "example.com/lib.F", dotIdx: 15}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := rules.Match(tt.c); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if (InAppRules{}).Match(Immediate()) {
+		t.Error("InAppRules{}.Match() = true, want false")
+	}
+}
+
+// TestInApp tests InApp on nil callers and callers of other types.
+func TestInApp(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		c    Caller
+		want bool
+	}{
+		{"nil", nil, false},
+		{"typed nil", (*callerInfo)(nil), false},
+		{"mock", &mockCaller{file: "a.go", line: 1}, false},
+		{"not in-app", &callerInfo{file: "a.go", line: 1}, false},
+		{"in-app", &callerInfo{file: "a.go", line: 1, flags: flagInApp}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := InApp(tt.c); got != tt.want {
+				t.Errorf("InApp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package caller
+
+import (
+	"log/slog"
+	"runtime"
+)
+
+// Info is a concrete, value-typed form of a captured caller, for
+// performance-sensitive code that wants to avoid the heap allocation and
+// interface boxing of New. Its accessors behave like those of Caller.
+// The zero Info is invalid.
+//
+//	if info, ok := caller.Capture(0); ok {
+//		fmt.Println(info.ShortLocation())
+//	}
+//
+// Info provides every Caller method except UnmarshalJSON; use its Caller
+// method where a Caller is needed.
+type Info struct {
+	c callerInfo
+}
+
+// Capture returns an Info for the caller, with the same skip semantics
+// as New. It reports false if the skip is invalid or the caller cannot
+// be determined.
+//
+// Capture does not allocate, except when resolving a frame that the
+// compiler inlined, or when a Recorder or capture hook is installed,
+// which then receives a copy of the capture as for New.
+func Capture(skip int) (Info, bool) {
+	if skip < 0 {
+		return Info{}, false
+	}
+
+	// runtime.Caller allocates its program counter buffer and frame
+	// iterator, so resolve a single return address directly instead.
+	// Skip runtime.Callers, Capture, and the function calling Capture.
+	var pcs [1]uintptr
+	if runtime.Callers(skip+skipAdjust+1, pcs[:]) == 0 {
+		return Info{}, false
+	}
+	pc := pcs[0] - 1
+	f := runtime.FuncForPC(pc)
+	if f == nil {
+		return Info{}, false
+	}
+	file, line := f.FileLine(pc)
+
+	info := Info{c: makeCallerInfo(file, line, f.Name())}
+	if recorder.Load() != nil || hooks.Load() != nil {
+		c := info.c
+		captured(&c, "")
+	}
+	return info, true
+}
+
+// Caller returns the information as a Caller. Each call allocates.
+func (i Info) Caller() Caller {
+	c := i.c
+	return &c
+}
+
+// Valid returns true if the caller is usable.
+func (i Info) Valid() bool {
+	return i.c.Valid()
+}
+
+// File returns the file name.
+func (i Info) File() string {
+	return i.c.File()
+}
+
+// Line returns the line number.
+func (i Info) Line() int {
+	return i.c.Line()
+}
+
+// Location returns a formatted string with file:line.
+func (i Info) Location() string {
+	return i.c.Location()
+}
+
+// ShortLocation returns a formatted string with just filename:line.
+func (i Info) ShortLocation() string {
+	return i.c.ShortLocation()
+}
+
+// Function returns just the function or method name
+// without package prefix.
+func (i Info) Function() string {
+	return i.c.Function()
+}
+
+// FullFunction returns the full function name including package.
+func (i Info) FullFunction() string {
+	return i.c.FullFunction()
+}
+
+// Package returns the full import path of the package.
+func (i Info) Package() string {
+	return i.c.Package()
+}
+
+// PackageName returns the name of the package without the directory.
+func (i Info) PackageName() string {
+	return i.c.PackageName()
+}
+
+// String returns a formatted string as returned by ShortLocation().
+func (i Info) String() string {
+	return i.c.String()
+}
+
+// Equal reports whether the information is semantically equal to other,
+// as for Caller.Equal.
+func (i Info) Equal(other Caller) bool {
+	return i.c.Equal(other)
+}
+
+// MarshalJSON implements the json.Marshaler interface, producing the
+// same encoding as Caller.
+func (i Info) MarshalJSON() ([]byte, error) {
+	return i.c.MarshalJSON()
+}
+
+// LogValue implements the slog.LogValuer interface, producing the same
+// value as Caller.
+func (i Info) LogValue() slog.Value {
+	return i.c.LogValue()
+}
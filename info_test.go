@@ -0,0 +1,93 @@
+package caller
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// captureHelper captures an Info whose location is its caller.
+func captureHelper(skip int) (Info, bool) {
+	return Capture(skip)
+}
+
+// TestCapture tests that Capture resolves the same frame as New and that
+// Info's accessors match those of the equivalent Caller.
+func TestCapture(t *testing.T) {
+	t.Parallel()
+
+	info, ok := captureHelper(0)
+	want := stackHelper(0).Caller0()
+	if !ok {
+		t.Fatal("Capture(0) reported false")
+	}
+	if info.File() != want.File() || info.Line() != want.Line()-1 {
+		t.Errorf("Capture(0) = %q, want %s:%d", info.Location(), want.File(), want.Line()-1)
+	}
+
+	c := info.Caller()
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"Valid", info.Valid(), c.Valid()},
+		{"Location", info.Location(), c.Location()},
+		{"ShortLocation", info.ShortLocation(), c.ShortLocation()},
+		{"Function", info.Function(), "TestCapture"},
+		{"FullFunction", info.FullFunction(), c.FullFunction()},
+		{"Package", info.Package(), c.Package()},
+		{"PackageName", info.PackageName(), c.PackageName()},
+		{"String", info.String(), c.String()},
+		{"Equal", info.Equal(c), true},
+		{"LogValue", info.LogValue().String(), c.LogValue().String()},
+		{"MarshalJSON", string(mustMarshal(t, info)), string(mustMarshal(t, c))},
+	}
+	for _, tt := range checks {
+		if tt.got != tt.want {
+			t.Errorf("%s() = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if _, ok := Capture(-1); ok {
+		t.Error("Capture(-1) reported true")
+	}
+	if _, ok := Capture(10000); ok {
+		t.Error("Capture(10000) reported true")
+	}
+}
+
+// TestInfo_Zero tests that the zero Info is invalid and renders empty.
+func TestInfo_Zero(t *testing.T) {
+	t.Parallel()
+
+	var info Info
+	if info.Valid() || info.Location() != "" || info.Function() != "" || info.Package() != "" {
+		t.Errorf("zero Info = %q/%q/%q, want invalid and empty", info.Location(), info.Function(), info.Package())
+	}
+	data, err := json.Marshal(info)
+	if err != nil || string(data) != "{}" {
+		t.Errorf("json.Marshal(Info{}) = %s, %v, want {}", data, err)
+	}
+}
+
+// TestCapture_Allocs tests that Capture does not allocate.
+func TestCapture_Allocs(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, ok := Capture(0); !ok {
+			t.Fatal("Capture(0) reported false")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Capture(0) allocated %v times per run, want 0", allocs)
+	}
+}
+
+// TestCapture_Hooks tests that Capture feeds installed capture hooks.
+func TestCapture_Hooks(t *testing.T) {
+	var seen Caller
+	t.Cleanup(OnCapture(func(c Caller) { seen = c }))
+
+	info, _ := captureHelper(0)
+	if !info.Equal(seen) {
+		t.Errorf("hook saw %v, want %v", seen, info)
+	}
+}
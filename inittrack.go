@@ -0,0 +1,91 @@
+package caller
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InitRecord describes a piece of initialization work tracked with
+// TrackInit.
+type InitRecord struct {
+	Caller   Caller        // Call site of TrackInit, in the initializing function; nil if unknown
+	Start    time.Time     // Time TrackInit was called
+	Duration time.Duration // Time until the function returned by TrackInit was called; 0 if it was not
+}
+
+// String returns the record formatted as "function (file:line) duration".
+func (r InitRecord) String() string {
+	var sb strings.Builder
+	sb.WriteString(FullFunction(r.Caller))
+	sb.WriteString(" (")
+	sb.WriteString(ShortLocation(r.Caller))
+	sb.WriteString(")")
+	if r.Duration > 0 {
+		sb.WriteByte(' ')
+		sb.WriteString(r.Duration.String())
+	}
+	return sb.String()
+}
+
+var (
+	// initMu guards initRecords.
+	initMu sync.Mutex
+
+	// initRecords holds the records of TrackInit, in call order.
+	initRecords []InitRecord
+)
+
+// TrackInit records where and when initialization work runs, for a
+// startup report of what ran during initialization, in which order, from
+// where and for how long, to debug slow or surprising init ordering.
+// Route package init functions and expensive package-level variable
+// initializers through it:
+//
+//	func init() {
+//		defer caller.TrackInit()()
+//		...
+//	}
+//
+// It returns a function that records the duration of the work when
+// called; calling it more than once has no further effect. Read the
+// report with InitReport. Tracking is opt-in per call site: only work
+// routed through TrackInit is recorded. TrackInit is safe for concurrent
+// use and may be used outside initialization as well.
+func TrackInit() func() {
+	var c Caller
+	if pc, file, line, ok := runtime.Caller(1); ok {
+		var fullFunc string
+		if f := runtime.FuncForPC(pc); f != nil {
+			fullFunc = f.Name()
+		}
+		c = newCallerInfo(file, line, fullFunc)
+	}
+	start := time.Now()
+
+	initMu.Lock()
+	i := len(initRecords)
+	initRecords = append(initRecords, InitRecord{Caller: c, Start: start})
+	initMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			d := time.Since(start)
+			initMu.Lock()
+			defer initMu.Unlock()
+			if i < len(initRecords) {
+				initRecords[i].Duration = d
+			}
+		})
+	}
+}
+
+// InitReport returns the records of TrackInit, in the order it was
+// called.
+func InitReport() []InitRecord {
+	initMu.Lock()
+	defer initMu.Unlock()
+	return append([]InitRecord(nil), initRecords...)
+}
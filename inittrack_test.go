@@ -0,0 +1,53 @@
+package caller
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// trackedInit is package-level initialization work routed through
+// TrackInit.
+var trackedInit = func() int {
+	defer TrackInit()()
+	return 1
+}()
+
+// TestTrackInit tests that TrackInit records package initialization and
+// later work in call order, with durations once finished.
+func TestTrackInit(t *testing.T) {
+	before := InitReport()
+	if len(before) == 0 || trackedInit != 1 {
+		t.Fatal("InitReport() is empty, want the package-level initializer")
+	}
+	if got := before[0].Caller; !strings.HasPrefix(got.FullFunction(), "github.com/balinomad/go-caller/v2.init.func") || !strings.HasSuffix(got.File(), "inittrack_test.go") {
+		t.Errorf("InitReport()[0].Caller = %v, want the initializer closure", got)
+	}
+
+	done := TrackInit()
+	_, file, line, _ := runtime.Caller(0)
+	time.Sleep(time.Millisecond)
+	done()
+	done()
+
+	records := InitReport()
+	if len(records) != len(before)+1 {
+		t.Fatalf("len(InitReport()) = %d, want %d", len(records), len(before)+1)
+	}
+	r := records[len(records)-1]
+	if r.Caller.File() != file || r.Caller.Line() != line-1 || r.Caller.Function() != "TestTrackInit" {
+		t.Errorf("Caller = %v, want TestTrackInit at %s:%d", r.Caller, file, line-1)
+	}
+	if r.Duration < time.Millisecond || r.Start.IsZero() {
+		t.Errorf("Start, Duration = %v, %v, want a start time and at least 1ms", r.Start, r.Duration)
+	}
+	if s := r.String(); !strings.HasPrefix(s, "github.com/balinomad/go-caller/v2.TestTrackInit (inittrack_test.go:") || !strings.HasSuffix(s, "s") {
+		t.Errorf("String() = %q, want function, location and duration", s)
+	}
+
+	records[0] = InitRecord{}
+	if InitReport()[0].Caller == nil {
+		t.Error("modifying the result of InitReport() changed the report")
+	}
+}
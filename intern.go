@@ -0,0 +1,81 @@
+package caller
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultInternerSize is the capacity of the zero Interner, and the one
+// used by NewInterner for a non-positive size.
+const DefaultInternerSize = 1024
+
+// Interner canonicalizes callers equal by EqualCallers to a single shared
+// instance, so that a service capturing the same few hundred call sites
+// millions of times keeps one Caller per call site rather than one per
+// capture:
+//
+//	var sites = caller.NewInterner(0)
+//
+//	func track() {
+//		c := sites.Intern(caller.New(0))
+//		...
+//	}
+//
+// It holds up to a fixed number of callers, evicting the least recently
+// used once full. The zero Interner is ready to use and holds up to
+// DefaultInternerSize callers. An Interner is safe for concurrent use.
+type Interner struct {
+	mu    sync.Mutex
+	size  int
+	items map[Key]*list.Element
+	order list.List // Elements holding callers, most recently used first
+}
+
+// NewInterner returns an Interner holding up to size callers.
+// A non-positive size selects DefaultInternerSize.
+func NewInterner(size int) *Interner {
+	if size <= 0 {
+		size = DefaultInternerSize
+	}
+	return &Interner{size: size, items: make(map[Key]*list.Element, size)}
+}
+
+// Intern returns the caller held by the interner that is equal to c, or
+// adds c and returns it if there is none. Nil callers are returned as is.
+func (in *Interner) Intern(c Caller) Caller {
+	if isNil(c) {
+		return c
+	}
+	k := KeyOf(c)
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.items == nil {
+		if in.size <= 0 {
+			in.size = DefaultInternerSize
+		}
+		in.items = make(map[Key]*list.Element, in.size)
+	}
+	if e, ok := in.items[k]; ok {
+		in.order.MoveToFront(e)
+		if held, ok := e.Value.(Caller); ok {
+			return held
+		}
+	}
+	if in.order.Len() >= in.size {
+		if oldest := in.order.Back(); oldest != nil {
+			if held, ok := in.order.Remove(oldest).(Caller); ok {
+				delete(in.items, KeyOf(held))
+			}
+		}
+	}
+	in.items[k] = in.order.PushFront(c)
+	return c
+}
+
+// Len returns the number of callers held by the interner.
+func (in *Interner) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.order.Len()
+}
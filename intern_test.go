@@ -0,0 +1,118 @@
+package caller
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestInterner tests that equal callers are canonicalized to the first
+// one interned.
+func TestInterner(t *testing.T) {
+	t.Parallel()
+
+	f := newCompactFixture()
+	in := NewInterner(0)
+	if in.size != DefaultInternerSize {
+		t.Errorf("NewInterner(0) size = %d, want %d", in.size, DefaultInternerSize)
+	}
+
+	tests := []struct {
+		name string
+		c    Caller
+		want Caller
+	}{
+		{"first", f.a1, f.a1},
+		{"equal mock", f.a2, f.a1},
+		{"distinct", f.b, f.b},
+		{"nil", nil, nil},
+		{"nil pointer", (*callerInfo)(nil), (*callerInfo)(nil)},
+		{"copy", &callerInfo{file: "/src/a.go", line: 2, fn: "app.B", dotIdx: 3}, f.b},
+	}
+	for _, tt := range tests {
+		if got := in.Intern(tt.c); got != tt.want {
+			t.Errorf("%s: Intern() = %p, want %p", tt.name, got, tt.want)
+		}
+	}
+	if got := in.Len(); got != 2 {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+	if in.SizeBytes() <= 2*callerInfoSize {
+		t.Errorf("SizeBytes() = %d, want the held callers counted", in.SizeBytes())
+	}
+}
+
+// TestInterner_Zero tests that the zero Interner is usable and holds
+// DefaultInternerSize callers.
+func TestInterner_Zero(t *testing.T) {
+	t.Parallel()
+
+	f := newCompactFixture()
+	var in Interner
+	if got := in.Intern(f.a1); got != f.a1 {
+		t.Errorf("Intern() = %p, want %p", got, f.a1)
+	}
+	if got := in.Intern(f.a2); got != f.a1 {
+		t.Errorf("Intern() = %p, want the interned %p", got, f.a1)
+	}
+	if in.size != DefaultInternerSize || in.Len() != 1 {
+		t.Errorf("size, Len() = %d, %d, want %d, 1", in.size, in.Len(), DefaultInternerSize)
+	}
+}
+
+// TestInterner_Evict tests that the least recently used caller is evicted
+// once the interner is full.
+func TestInterner_Evict(t *testing.T) {
+	t.Parallel()
+
+	f := newCompactFixture()
+	in := NewInterner(2)
+	in.Intern(f.a1)
+	in.Intern(f.b)
+	in.Intern(f.a2) // Uses a1, leaving b the oldest
+	in.Intern(f.c)
+
+	if got := in.Len(); got != 2 {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+	if got := in.Intern(f.a2); got != f.a1 {
+		t.Errorf("Intern() = %p, want the retained %p", got, f.a1)
+	}
+	b := &callerInfo{file: "/src/a.go", line: 2, fn: "app.B", dotIdx: 3}
+	if got := in.Intern(b); got != b {
+		t.Errorf("Intern() = %p, want the evicted caller replaced by %p", got, b)
+	}
+}
+
+// TestInterner_Concurrent tests that concurrent interning of equal
+// callers yields a single instance.
+func TestInterner_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	in := NewInterner(4)
+	got := make([]Caller, 16)
+	var wg sync.WaitGroup
+	for i := range got {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got[i] = in.Intern(&callerInfo{file: "/src/a.go", line: 1, fn: "app.A", dotIdx: 3})
+		}()
+	}
+	wg.Wait()
+	for i, c := range got {
+		if c != got[0] {
+			t.Fatalf("Intern() #%d = %p, want %p", i, c, got[0])
+		}
+	}
+}
+
+// BenchmarkInterner_Intern measures interning an already held caller.
+func BenchmarkInterner_Intern(b *testing.B) {
+	in := NewInterner(0)
+	c := &callerInfo{file: "/src/a.go", line: 1, fn: "app.A", dotIdx: 3}
+	in.Intern(c)
+	b.ReportAllocs()
+	for range b.N {
+		in.Intern(c)
+	}
+}
@@ -0,0 +1,88 @@
+package caller
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// ErrInvalidCaller is returned when unmarshaling into the Caller
+// returned by Invalid, which cannot be modified.
+var ErrInvalidCaller = errors.New("cannot unmarshal into the invalid caller")
+
+// invalidCaller is the type of the shared Caller returned by Invalid.
+// It is stateless, so every instance is identical and immutable.
+type invalidCaller struct{}
+
+// invalidCaller implements the Caller interface.
+var _ Caller = invalidCaller{}
+
+// Invalid returns a shared Caller that is never valid: its accessors
+// return zero values, it is not equal to any Caller, and it marshals to
+// JSON null. It stands in for nil where a non-nil Caller is required,
+// avoiding the pitfalls of nil and typed-nil interface values:
+//
+//	c := caller.OrInvalid(caller.New(skip))
+//
+// Invalid() == Invalid() is always true.
+func Invalid() Caller {
+	return invalidCaller{}
+}
+
+// OrInvalid returns c, or Invalid() if c is nil or a typed-nil Caller of
+// this package, so that the result of a constructor never needs a nil
+// check.
+func OrInvalid(c Caller) Caller {
+	if ci, ok := c.(*callerInfo); c == nil || (ok && ci == nil) {
+		return Invalid()
+	}
+	return c
+}
+
+// Valid returns false.
+func (invalidCaller) Valid() bool { return false }
+
+// File returns an empty string.
+func (invalidCaller) File() string { return "" }
+
+// Line returns 0.
+func (invalidCaller) Line() int { return 0 }
+
+// Location returns an empty string.
+func (invalidCaller) Location() string { return "" }
+
+// ShortLocation returns an empty string.
+func (invalidCaller) ShortLocation() string { return "" }
+
+// Function returns an empty string.
+func (invalidCaller) Function() string { return "" }
+
+// FullFunction returns an empty string.
+func (invalidCaller) FullFunction() string { return "" }
+
+// Package returns an empty string.
+func (invalidCaller) Package() string { return "" }
+
+// PackageName returns an empty string.
+func (invalidCaller) PackageName() string { return "" }
+
+// String returns an empty string.
+func (invalidCaller) String() string { return "" }
+
+// Equal returns false, as the invalid caller is not equal to any caller.
+func (invalidCaller) Equal(Caller) bool { return false }
+
+// MarshalJSON returns JSON null, as for a nil Caller.
+func (invalidCaller) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// UnmarshalJSON returns ErrInvalidCaller, as the invalid caller is
+// shared and cannot be modified.
+func (invalidCaller) UnmarshalJSON([]byte) error {
+	return ErrInvalidCaller
+}
+
+// LogValue returns an empty slog.Value, as for any invalid caller.
+func (invalidCaller) LogValue() slog.Value {
+	return slog.Value{}
+}
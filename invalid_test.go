@@ -0,0 +1,70 @@
+package caller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+// TestInvalid tests that the invalid caller is shared, empty and
+// immutable.
+func TestInvalid(t *testing.T) {
+	t.Parallel()
+
+	c := Invalid()
+	if c == nil || c != Invalid() {
+		t.Fatal("Invalid() should return the same non-nil Caller")
+	}
+	if c.Valid() || c.File() != "" || c.Line() != 0 || c.Location() != "" || c.ShortLocation() != "" ||
+		c.Function() != "" || c.FullFunction() != "" || c.Package() != "" || c.PackageName() != "" || c.String() != "" {
+		t.Error("Invalid() should report invalid, empty information")
+	}
+	if c.Equal(c) || c.Equal(Immediate()) {
+		t.Error("Invalid() should not be equal to any caller")
+	}
+	if Immediate().Equal(c) {
+		t.Error("a valid caller should not be equal to Invalid()")
+	}
+	if got := c.LogValue(); !got.Equal(slog.Value{}) {
+		t.Errorf("LogValue() = %v, want empty", got)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil || string(data) != "null" {
+		t.Errorf("json.Marshal(Invalid()) = %s, %v, want null", data, err)
+	}
+	if err := c.UnmarshalJSON([]byte(`{"file":"a.go"}`)); !errors.Is(err, ErrInvalidCaller) {
+		t.Errorf("UnmarshalJSON() error = %v, want %v", err, ErrInvalidCaller)
+	}
+	if Invalid().File() != "" {
+		t.Error("UnmarshalJSON() modified the shared invalid caller")
+	}
+}
+
+// TestOrInvalid tests that OrInvalid replaces nil and typed-nil callers
+// with Invalid() and passes valid ones through.
+func TestOrInvalid(t *testing.T) {
+	t.Parallel()
+
+	valid := New(0)
+	tests := []struct {
+		name string
+		c    Caller
+		want Caller
+	}{
+		{"nil", nil, Invalid()},
+		{"typed nil", (*callerInfo)(nil), Invalid()},
+		{"failed New", New(-1), Invalid()},
+		{"invalid", Invalid(), Invalid()},
+		{"valid", valid, valid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := OrInvalid(tt.c); got != tt.want {
+				t.Errorf("OrInvalid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
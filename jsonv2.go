@@ -0,0 +1,298 @@
+//go:build go1.27 && goexperiment.jsonv2
+
+package caller
+
+import (
+	"encoding/json"
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"fmt"
+	"strconv"
+)
+
+// callerInfo and Stack encode through the json/v2 streaming API without
+// falling back to their reflection-based json.Marshaler implementations.
+var (
+	_ jsonv2.MarshalerTo     = (*callerInfo)(nil)
+	_ jsonv2.UnmarshalerFrom = (*callerInfo)(nil)
+	_ jsonv2.MarshalerTo     = (*Stack)(nil)
+	_ jsonv2.UnmarshalerFrom = (*Stack)(nil)
+)
+
+// MarshalJSONTo implements the json/v2 MarshalerTo interface, producing
+// the same encoding as MarshalJSON.
+func (c *callerInfo) MarshalJSONTo(enc *jsontext.Encoder) error {
+	if err := enc.WriteValue(appendCallerJSON(nil, c)); err != nil {
+		return fmt.Errorf("JSON marshal: %w", err)
+	}
+	return nil
+}
+
+// UnmarshalJSONFrom implements the json/v2 UnmarshalerFrom interface,
+// accepting the same payloads as UnmarshalJSON. Object member names are
+// matched exactly, as json/v2 does by default.
+func (c *callerInfo) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	var (
+		v, line             int
+		file, function, pkg string
+		inApp               bool
+	)
+	err := readObject(dec, func(name string) error {
+		var err error
+		switch name {
+		case "v":
+			v, err = readInt(dec)
+		case "file":
+			file, err = readString(dec)
+		case "line":
+			line, err = readInt(dec)
+		case "function":
+			function, err = readString(dec)
+		case "package":
+			pkg, err = readString(dec)
+		case "in_app":
+			inApp, err = readBool(dec)
+		default:
+			err = dec.SkipValue()
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return c.setDecoded(v, file, line, function, pkg, inApp)
+}
+
+// MarshalJSONTo implements the json/v2 MarshalerTo interface, producing
+// the same encoding as MarshalJSON. Frames are written one at a time,
+// reusing a single buffer.
+func (s *Stack) MarshalJSONTo(enc *jsontext.Encoder) error {
+	if s == nil {
+		return writeTokens(enc, jsontext.Null)
+	}
+	if err := writeTokens(enc, jsontext.BeginObject); err != nil {
+		return err
+	}
+	if s.buildID != "" {
+		if err := writeTokens(enc, jsontext.String("build_id"), jsontext.String(s.buildID)); err != nil {
+			return err
+		}
+	}
+	if s.build != nil {
+		if err := writeObject(enc, "build", s.build); err != nil {
+			return err
+		}
+	}
+	if s.deploy != nil {
+		if err := writeObject(enc, "deployment", s.deploy); err != nil {
+			return err
+		}
+	}
+
+	if err := writeTokens(enc, jsontext.String("frames"), jsontext.BeginArray); err != nil {
+		return err
+	}
+	var buf []byte
+	for _, f := range s.frames {
+		buf = appendCallerJSON(buf[:0], f)
+		if err := enc.WriteValue(buf); err != nil {
+			return fmt.Errorf("JSON marshal: %w", err)
+		}
+	}
+	return writeTokens(enc, jsontext.EndArray, jsontext.EndObject)
+}
+
+// UnmarshalJSONFrom implements the json/v2 UnmarshalerFrom interface,
+// accepting the same payloads as UnmarshalJSON. Object member names are
+// matched exactly, as json/v2 does by default.
+func (s *Stack) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	var (
+		v       int
+		buildID string
+		build   *BuildInfo
+		deploy  *Deployment
+		frames  []*callerInfo
+	)
+	err := readObject(dec, func(name string) error {
+		var err error
+		switch name {
+		case "v":
+			v, err = readInt(dec)
+		case "build_id":
+			buildID, err = readString(dec)
+		case "build":
+			err = readObjectValue(dec, &build)
+		case "deployment":
+			err = readObjectValue(dec, &deploy)
+		case "frames":
+			frames, err = readFrames(dec)
+		default:
+			err = dec.SkipValue()
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := checkSchemaVersion(v); err != nil {
+		return err
+	}
+	s.setDecoded(frames, buildID, build, deploy)
+	return nil
+}
+
+// writeTokens writes each of toks to enc.
+func writeTokens(enc *jsontext.Encoder, toks ...jsontext.Token) error {
+	for _, tok := range toks {
+		if err := enc.WriteToken(tok); err != nil {
+			return fmt.Errorf("JSON marshal: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeObject writes the member name with the json.Marshal encoding of
+// v, for the small metadata objects of a Stack.
+func writeObject(enc *jsontext.Encoder, name string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("JSON marshal: %w", err)
+	}
+	if err := writeTokens(enc, jsontext.String(name)); err != nil {
+		return err
+	}
+	if err := enc.WriteValue(b); err != nil {
+		return fmt.Errorf("JSON marshal: %w", err)
+	}
+	return nil
+}
+
+// readObject reads a JSON object from dec, calling member with the name
+// of each member, which must consume its value. A null reads as an empty
+// object.
+func readObject(dec *jsontext.Decoder, member func(name string) error) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return fmt.Errorf("JSON unmarshal: %w", err)
+	}
+	switch tok.Kind() {
+	case 'n':
+		return nil
+	case '{':
+	default:
+		return fmt.Errorf("JSON unmarshal: expected an object, got %v", tok.Kind())
+	}
+	for dec.PeekKind() != '}' {
+		name, err := dec.ReadToken()
+		if err != nil {
+			return fmt.Errorf("JSON unmarshal: %w", err)
+		}
+		if err := member(name.String()); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.ReadToken(); err != nil {
+		return fmt.Errorf("JSON unmarshal: %w", err)
+	}
+	return nil
+}
+
+// readObjectValue reads the next value from dec into v with
+// json.Unmarshal, for the small metadata objects of a Stack.
+func readObjectValue(dec *jsontext.Decoder, v any) error {
+	val, err := dec.ReadValue()
+	if err != nil {
+		return fmt.Errorf("JSON unmarshal: %w", err)
+	}
+	if err := json.Unmarshal(val, v); err != nil {
+		return fmt.Errorf("JSON unmarshal: %w", err)
+	}
+	return nil
+}
+
+// readFrames reads a JSON array of callers from dec, keeping null
+// elements as nil. A null reads as no frames.
+func readFrames(dec *jsontext.Decoder) ([]*callerInfo, error) {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return nil, fmt.Errorf("JSON unmarshal: %w", err)
+	}
+	switch tok.Kind() {
+	case 'n':
+		return nil, nil
+	case '[':
+	default:
+		return nil, fmt.Errorf("JSON unmarshal: expected an array, got %v", tok.Kind())
+	}
+	frames := []*callerInfo{}
+	for dec.PeekKind() != ']' {
+		if dec.PeekKind() == 'n' {
+			if _, err := dec.ReadToken(); err != nil {
+				return nil, fmt.Errorf("JSON unmarshal: %w", err)
+			}
+			frames = append(frames, nil)
+			continue
+		}
+		c := &callerInfo{}
+		if err := c.UnmarshalJSONFrom(dec); err != nil {
+			return nil, err
+		}
+		frames = append(frames, c)
+	}
+	if _, err := dec.ReadToken(); err != nil {
+		return nil, fmt.Errorf("JSON unmarshal: %w", err)
+	}
+	return frames, nil
+}
+
+// readString reads a JSON string from dec. A null reads as "".
+func readString(dec *jsontext.Decoder) (string, error) {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return "", fmt.Errorf("JSON unmarshal: %w", err)
+	}
+	switch tok.Kind() {
+	case 'n':
+		return "", nil
+	case '"':
+		return tok.String(), nil
+	default:
+		return "", fmt.Errorf("JSON unmarshal: expected a string, got %v", tok.Kind())
+	}
+}
+
+// readInt reads a JSON integer from dec. A null reads as 0.
+func readInt(dec *jsontext.Decoder) (int, error) {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return 0, fmt.Errorf("JSON unmarshal: %w", err)
+	}
+	switch tok.Kind() {
+	case 'n':
+		return 0, nil
+	case '0':
+		n, err := strconv.Atoi(tok.String())
+		if err != nil {
+			return 0, fmt.Errorf("JSON unmarshal: %w", err)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("JSON unmarshal: expected an integer, got %v", tok.Kind())
+	}
+}
+
+// readBool reads a JSON boolean from dec. A null reads as false.
+func readBool(dec *jsontext.Decoder) (bool, error) {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return false, fmt.Errorf("JSON unmarshal: %w", err)
+	}
+	switch tok.Kind() {
+	case 'n':
+		return false, nil
+	case 't', 'f':
+		return tok.Bool(), nil
+	default:
+		return false, fmt.Errorf("JSON unmarshal: expected a boolean, got %v", tok.Kind())
+	}
+}
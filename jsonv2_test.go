@@ -0,0 +1,88 @@
+//go:build go1.27 && goexperiment.jsonv2
+
+package caller
+
+import (
+	jsonv2 "encoding/json/v2"
+	"testing"
+)
+
+// mustMarshalV2 returns the json/v2 encoding of v, failing the test on
+// error.
+func mustMarshalV2(t *testing.T, v any) string {
+	t.Helper()
+	b, err := jsonv2.Marshal(v)
+	if err != nil {
+		t.Fatalf("jsonv2.Marshal() error = %v", err)
+	}
+	return string(b)
+}
+
+// TestJSONv2 tests that json/v2 encodes callers and stacks as
+// json.Marshal does and decodes them back.
+func TestJSONv2(t *testing.T) {
+	t.Parallel()
+
+	c, s := Immediate(), stackHelper(0)
+	for name, v := range map[string]any{
+		"caller":       c,
+		"in-app":       &callerInfo{file: "a.go", line: 1, fn: "main.main", dotIdx: 4, flags: flagInApp},
+		"stack":        s,
+		"nil stack":    (*Stack)(nil),
+		"empty stack":  &Stack{},
+		"stack fields": &Stack{frames: s.frames[:1], buildID: "abc", deploy: &Deployment{Service: "api"}},
+	} {
+		if got, want := mustMarshalV2(t, v), mustMarshal(t, v); got != want {
+			t.Errorf("%s: jsonv2.Marshal() = %s, want %s", name, got, want)
+		}
+	}
+
+	got := NewEmpty()
+	if err := jsonv2.Unmarshal([]byte(mustMarshal(t, c)), got); err != nil || !got.Equal(c) {
+		t.Errorf("jsonv2.Unmarshal() = %v, %v, want %v", got, err, c)
+	}
+	inApp := NewEmpty()
+	if err := jsonv2.Unmarshal([]byte(`{"file":"a.go","in_app":true}`), inApp); err != nil || !InApp(inApp) {
+		t.Errorf("jsonv2.Unmarshal() = %v, %v, want an in-app caller", inApp, err)
+	}
+	var gotStack Stack
+	data := `{"build_id":"abc","build":{"goos":"linux"},"deployment":{"service":"api"},"frames":[null,` + mustMarshal(t, c) + `],"extra":[1]}`
+	if err := jsonv2.Unmarshal([]byte(data), &gotStack); err != nil {
+		t.Fatalf("jsonv2.Unmarshal() error = %v", err)
+	}
+	if gotStack.Len() != 1 || !gotStack.Frame(0).Equal(c) || gotStack.buildID != "abc" ||
+		gotStack.build == nil || gotStack.build.GOOS != "linux" || gotStack.deploy == nil || gotStack.deploy.Service != "api" {
+		t.Errorf("jsonv2.Unmarshal() = %+v, want the decoded fields without null frames", gotStack)
+	}
+}
+
+// TestJSONv2_Errors tests that json/v2 decoding rejects malformed
+// payloads.
+func TestJSONv2_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+		v    any
+	}{
+		{"caller not an object", `[]`, NewEmpty()},
+		{"negative line", `{"line":-1}`, NewEmpty()},
+		{"fractional line", `{"line":1.5}`, NewEmpty()},
+		{"file not a string", `{"file":1}`, NewEmpty()},
+		{"negative version", `{"v":-1}`, NewEmpty()},
+		{"truncated", `{"file":`, NewEmpty()},
+		{"frames not an array", `{"frames":{}}`, &Stack{}},
+		{"bad frame", `{"frames":[{"line":-1}]}`, &Stack{}},
+		{"bad build", `{"build":[]}`, &Stack{}},
+		{"stack negative version", `{"v":-1,"frames":[]}`, &Stack{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := jsonv2.Unmarshal([]byte(tt.data), tt.v); err == nil {
+				t.Errorf("jsonv2.Unmarshal(%s) expected an error, but got nil", tt.data)
+			}
+		})
+	}
+}
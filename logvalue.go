@@ -0,0 +1,113 @@
+package caller
+
+import (
+	"log/slog"
+	"runtime"
+)
+
+// LogValueMode selects how LogValueAs renders a Caller as a slog.Value.
+type LogValueMode int32
+
+const (
+	// LogValueGroup renders a group with file, line, function and package
+	// attributes, as the LogValue method does.
+	LogValueGroup LogValueMode = iota
+
+	// LogValueShort renders a single string as returned by ShortLocation,
+	// such as "main.go:42".
+	LogValueShort
+
+	// LogValueFull renders a single string as returned by Location,
+	// such as "/path/to/main.go:42".
+	LogValueFull
+
+	// LogValueFunction renders a single string with the full function
+	// name followed by the location, such as
+	// "github.com/user/pkg.Handle /path/to/main.go:42".
+	LogValueFunction
+)
+
+// LogValueAs renders c as a slog.Value in the given mode, regardless of
+// how callers render elsewhere. The single-string modes suit log schemas
+// that expect a flat field such as caller=main.go:42 rather than a nested
+// group:
+//
+//	logger.Info("user logged in", "caller", caller.LogValueAs(c, caller.LogValueShort))
+//
+// Unknown modes behave like LogValueGroup. For an invalid or nil caller,
+// it returns an empty slog.Value.
+func LogValueAs(c Caller, mode LogValueMode) slog.Value {
+	if !Valid(c) {
+		return slog.Value{}
+	}
+
+	switch mode {
+	case LogValueShort:
+		return slog.StringValue(c.ShortLocation())
+	case LogValueFull:
+		return slog.StringValue(c.Location())
+	case LogValueFunction:
+		if fn := c.FullFunction(); fn != "" {
+			return slog.StringValue(fn + " " + c.Location())
+		}
+		return slog.StringValue(c.Location())
+	default:
+		return c.LogValue()
+	}
+}
+
+// DefaultKey is the attribute key used by Args.
+const DefaultKey = "caller"
+
+// Attr returns a slog.Attr with the given key holding a Caller for the
+// immediate caller of Attr, so call sites need no intermediate variable:
+//
+//	logger.Info("user logged in", caller.Attr("src"))
+func Attr(key string) slog.Attr {
+	return slog.Any(key, New(0))
+}
+
+// Args returns a key/value pair, under DefaultKey, holding a Caller for
+// the immediate caller of Args, for use with the variadic slog methods:
+//
+//	logger.Info("user logged in", caller.Args()...)
+func Args() []any {
+	return []any{DefaultKey, New(0)}
+}
+
+// FromSlogRecord returns a Caller for the call site that created r, from
+// its program counter, so that slog handlers and middleware can render or
+// classify the source of the records they receive with this package.
+// It reports false if r has no program counter, as for records created
+// with a zero PC or by loggers with source capture disabled, or if the
+// call site cannot be resolved. The returned Caller is not passed to
+// capture hooks or the Recorder.
+func FromSlogRecord(r slog.Record) (Caller, bool) {
+	if r.PC == 0 {
+		return nil, false
+	}
+	// The PC is a return address, as runtime.Callers returns it
+	frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+	if frame.File == "" && frame.Function == "" {
+		return nil, false
+	}
+	return frameCallerInfo(frame), true
+}
+
+// StackLogMode selects how Stack.LogValueAs renders a Stack as a
+// slog.Value.
+type StackLogMode int32
+
+const (
+	// StackLogGroups renders a group holding one sub-group per frame,
+	// keyed "frame.0", "frame.1" and so on from the innermost frame, each
+	// rendered like a Caller. Text handlers print it as flat keys such as
+	// stack.frame.0.file, and JSON handlers as nested objects.
+	// This is how the LogValue method renders a Stack.
+	StackLogGroups StackLogMode = iota
+
+	// StackLogStrings renders a single value holding a slice of
+	// ShortLocation strings, innermost first, which JSON handlers print as
+	// an array and text handlers as a bracketed list.
+	StackLogStrings
+)
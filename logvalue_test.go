@@ -0,0 +1,123 @@
+package caller
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestLogValueAs tests each LogValue rendering mode.
+func TestLogValueAs(t *testing.T) {
+	t.Parallel()
+
+	c := &callerInfo{file: "/src/main.go", line: 42, fn: "example.com/app.Run", dotIdx: functionNameIndex("example.com/app.Run")}
+	noFunc := &callerInfo{file: "/src/main.go", line: 42, dotIdx: -1}
+
+	tests := []struct {
+		name string
+		mode LogValueMode
+		c    *callerInfo
+		want string
+	}{
+		{"short", LogValueShort, c, "main.go:42"},
+		{"full", LogValueFull, c, "/src/main.go:42"},
+		{"function", LogValueFunction, c, "example.com/app.Run /src/main.go:42"},
+		{"function, no function name", LogValueFunction, noFunc, "/src/main.go:42"},
+	}
+	for _, tt := range tests {
+		got := LogValueAs(tt.c, tt.mode)
+		if got.Kind() != slog.KindString || got.String() != tt.want {
+			t.Errorf("%s: LogValueAs() = %v (%v), want string %q", tt.name, got, got.Kind(), tt.want)
+		}
+	}
+	if got := LogValueAs(&mockCaller{file: "/src/main.go", line: 42, fullFn: "pkg.F"}, LogValueFunction); got.String() != "pkg.F /src/main.go:42" {
+		t.Errorf("LogValueAs() of a mock caller = %v, want pkg.F /src/main.go:42", got)
+	}
+
+	for _, nilCaller := range []Caller{nil, (*callerInfo)(nil), Invalid()} {
+		if got := LogValueAs(nilCaller, LogValueShort); got.Any() != nil {
+			t.Errorf("LogValueAs(%v) = %v, want empty value", nilCaller, got)
+		}
+	}
+
+	for _, mode := range []LogValueMode{LogValueGroup, LogValueMode(99)} {
+		if got := LogValueAs(c, mode).Kind(); got != slog.KindGroup {
+			t.Errorf("LogValueAs() kind in mode %d = %v, want %v", mode, got, slog.KindGroup)
+		}
+	}
+	if got := c.LogValue().Kind(); got != slog.KindGroup {
+		t.Errorf("LogValue() kind = %v, want %v", got, slog.KindGroup)
+	}
+}
+
+// TestAttr tests that Attr captures its immediate caller.
+func TestAttr(t *testing.T) {
+	t.Parallel()
+
+	a := Attr("src")
+	if a.Key != "src" {
+		t.Errorf("Attr().Key = %q, want %q", a.Key, "src")
+	}
+	c, ok := a.Value.Any().(Caller)
+	if !ok {
+		t.Fatalf("Attr().Value = %v, want a Caller", a.Value)
+	}
+	if got, want := c.Function(), "TestAttr"; got != want {
+		t.Errorf("Function() = %q, want %q", got, want)
+	}
+}
+
+// TestArgs tests that Args returns a key/value pair for its immediate caller.
+func TestArgs(t *testing.T) {
+	t.Parallel()
+
+	args := Args()
+	if len(args) != 2 || args[0] != DefaultKey {
+		t.Fatalf("Args() = %v, want [%q <caller>]", args, DefaultKey)
+	}
+	c, ok := args[1].(Caller)
+	if !ok {
+		t.Fatalf("Args()[1] = %v, want a Caller", args[1])
+	}
+	if got, want := c.Function(), "TestArgs"; got != want {
+		t.Errorf("Function() = %q, want %q", got, want)
+	}
+}
+
+// recordHandler is a slog.Handler keeping the last record it handled.
+type recordHandler struct {
+	last *slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.last = &r
+	return nil
+}
+
+// TestFromSlogRecord tests resolving the call site of a record.
+func TestFromSlogRecord(t *testing.T) {
+	t.Parallel()
+
+	h := &recordHandler{}
+	slog.New(h).Info("hello")
+	line := Immediate().Line() - 1
+
+	c, ok := FromSlogRecord(*h.last)
+	if !ok || c.Function() != "TestFromSlogRecord" || c.Line() != line {
+		t.Errorf("FromSlogRecord() = %v, %t, want TestFromSlogRecord at line %d", c, ok, line)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{h.last.PC}).Next()
+	if frame.File != c.File() || frame.Function != c.FullFunction() {
+		t.Errorf("FromSlogRecord() = %v, want the record's source %s %s", c, frame.Function, frame.File)
+	}
+
+	if c, ok := FromSlogRecord(slog.NewRecord(time.Now(), slog.LevelInfo, "no pc", 0)); ok || c != nil {
+		t.Errorf("FromSlogRecord() without a PC = %v, %t, want nil, false", c, ok)
+	}
+}
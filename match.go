@@ -0,0 +1,109 @@
+package caller
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Matcher reports whether a Caller satisfies some criterion.
+// Matchers are used wherever the package has to select frames, such as
+// when checking which code is allowed to call an API.
+type Matcher func(c Caller) bool
+
+// MatchPackage returns a Matcher for callers whose package import path
+// matches any of patterns. A pattern is either an exact import path, or
+// an import path followed by "/..." to also match every package below it,
+// as in the go command: "example.com/app/internal/..." matches
+// "example.com/app/internal" and "example.com/app/internal/db".
+func MatchPackage(patterns ...string) Matcher {
+	return func(c Caller) bool {
+		if isNil(c) {
+			return false
+		}
+		return matchPackagePatterns(patterns, c.Package())
+	}
+}
+
+// MatchFunction returns a Matcher for callers whose full function name,
+// as returned by FullFunction, is any of names.
+func MatchFunction(names ...string) Matcher {
+	return func(c Caller) bool {
+		if isNil(c) {
+			return false
+		}
+		fn := c.FullFunction()
+		for _, name := range names {
+			if fn == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchAny returns a Matcher for callers that match any of matchers.
+// Nil matchers are ignored.
+func MatchAny(matchers ...Matcher) Matcher {
+	return func(c Caller) bool {
+		return matchAny(matchers, c)
+	}
+}
+
+// matchAny reports whether c matches any of matchers.
+func matchAny(matchers []Matcher, c Caller) bool {
+	for _, m := range matchers {
+		if m != nil && m(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPackagePattern reports whether the import path pkg matches pattern.
+func matchPackagePattern(pattern, pkg string) bool {
+	if pkg == "" {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return pkg == prefix || strings.HasPrefix(pkg, prefix+"/")
+	}
+	return pkg == pattern
+}
+
+// matchPackagePatterns reports whether pkg matches any of patterns.
+func matchPackagePatterns(patterns []string, pkg string) bool {
+	for _, p := range patterns {
+		if matchPackagePattern(p, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchFunctionSuffix returns a Matcher for callers whose full function
+// name ends with a match for any of patterns, which are regular
+// expressions anchored at the end of the name. It complements
+// MatchPackage for noise frames inside otherwise relevant packages:
+//
+//	caller.MatchFunctionSuffix(`\.func\d+`, `\.init(\.\d+)?`) // closures and init functions
+//
+// It panics if a pattern is not a valid regular expression, like
+// regexp.MustCompile; patterns are expected to be constants.
+func MatchFunctionSuffix(patterns ...string) Matcher {
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		res[i] = regexp.MustCompile(`(?:` + p + `)$`)
+	}
+	return func(c Caller) bool {
+		if isNil(c) {
+			return false
+		}
+		fn := c.FullFunction()
+		for _, re := range res {
+			if re.MatchString(fn) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,102 @@
+package caller
+
+import "testing"
+
+// TestMatchPackage tests exact and recursive package patterns.
+func TestMatchPackage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		patterns []string
+		fn       string
+		want     bool
+	}{
+		{"exact", []string{"example.com/app"}, "example.com/app.F", true},
+		{"exact, subpackage", []string{"example.com/app"}, "example.com/app/db.F", false},
+		{"recursive, root", []string{"example.com/app/..."}, "example.com/app.F", true},
+		{"recursive, subpackage", []string{"example.com/app/..."}, "example.com/app/db.(*DB).Query", true},
+		{"recursive, sibling prefix", []string{"example.com/app/..."}, "example.com/apple.F", false},
+		{"second pattern", []string{"a", "example.com/app"}, "example.com/app.F", true},
+		{"no package", []string{"main"}, "main", false},
+		{"no patterns", nil, "example.com/app.F", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := &callerInfo{file: "x.go", fn: tt.fn, dotIdx: functionNameIndex(tt.fn)}
+			if got := MatchPackage(tt.patterns...)(c); got != tt.want {
+				t.Errorf("MatchPackage(%q)(%q) = %v, want %v", tt.patterns, tt.fn, got, tt.want)
+			}
+		})
+	}
+
+	if MatchPackage("...")(nil) {
+		t.Error("MatchPackage() should not match a nil caller")
+	}
+}
+
+// TestMatchFunction tests matching by full function name.
+func TestMatchFunction(t *testing.T) {
+	t.Parallel()
+
+	c := &callerInfo{fn: "pkg.(*T).M", dotIdx: 3}
+	if !MatchFunction("pkg.F", "pkg.(*T).M")(c) {
+		t.Error("MatchFunction() should match the full function name")
+	}
+	if MatchFunction("(*T).M")(c) {
+		t.Error("MatchFunction() should not match a partial name")
+	}
+	if MatchFunction("")(nil) {
+		t.Error("MatchFunction() should not match a nil caller")
+	}
+}
+
+// TestMatchAny tests combining matchers, including nil ones.
+func TestMatchAny(t *testing.T) {
+	t.Parallel()
+
+	c := &callerInfo{fn: "pkg.F", dotIdx: 3}
+	if !MatchAny(nil, MatchFunction("x"), MatchPackage("pkg"))(c) {
+		t.Error("MatchAny() should match when one matcher matches")
+	}
+	if MatchAny()(c) {
+		t.Error("MatchAny() with no matchers should not match")
+	}
+}
+
+// TestMatchFunctionSuffix tests end-anchored function name patterns.
+func TestMatchFunctionSuffix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		patterns []string
+		fn       string
+		want     bool
+	}{
+		{"closure", []string{`\.func\d+`}, "example.com/app.Run.func1", true},
+		{"nested closure", []string{`\.func\d+`}, "example.com/app.Run.func1.2", false},
+		{"nested closure pattern", []string{`\.func\d+(\.\d+)*`}, "example.com/app.Run.func1.2", true},
+		{"not at end", []string{`\.func\d+`}, "example.com/app.Run.func1.Wrap", false},
+		{"init", []string{`\.init`}, "example.com/app.init", true},
+		{"numbered init", []string{`\.init(\.\d+)?`}, "example.com/app.init.0", true},
+		{"init closure", []string{`\.init(\.\d+)?`}, "example.com/app.init.func1", false},
+		{"alternation", []string{`\.init|-fm`}, "example.com/app.(*T).M-fm", true},
+		{"second pattern", []string{`\.x`, `\.Run`}, "example.com/app.Run", true},
+		{"no patterns", nil, "example.com/app.Run", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := &callerInfo{file: "x.go", fn: tt.fn, dotIdx: functionNameIndex(tt.fn)}
+			if got := MatchFunctionSuffix(tt.patterns...)(c); got != tt.want {
+				t.Errorf("MatchFunctionSuffix(%q)(%q) = %v, want %v", tt.patterns, tt.fn, got, tt.want)
+			}
+		})
+	}
+
+	if MatchFunctionSuffix(`.*`)(nil) {
+		t.Error("MatchFunctionSuffix() should not match a nil caller")
+	}
+}
@@ -0,0 +1,215 @@
+package caller
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+)
+
+// Sizes of the values retained by captures, for SizeBytes estimates.
+var (
+	stackSize       = int(reflect.TypeFor[Stack]().Size())
+	callerInfoSize  = int(reflect.TypeFor[callerInfo]().Size())
+	frameExtrasSize = int(reflect.TypeFor[frameExtras]().Size())
+	entrySize       = int(reflect.TypeFor[Entry]().Size())
+	initRecordSize  = int(reflect.TypeFor[InitRecord]().Size())
+	pointerSize     = int(reflect.TypeFor[uintptr]().Size())
+	sliceHeaderSize = int(reflect.TypeFor[[]byte]().Size())
+	listElementSize = int(reflect.TypeFor[list.Element]().Size())
+	keySize         = int(reflect.TypeFor[Key]().Size())
+)
+
+// syncMapEntrySize approximates the memory a sync.Map retains per entry
+// besides its key and value data.
+const syncMapEntrySize = 64
+
+// mapEntrySize approximates the memory a built-in map retains per entry
+// besides its key and value, for its control bytes and the slots left
+// free by its load factor.
+const mapEntrySize = 8
+
+// SizeBytes returns an estimate of the memory retained by the stack: its
+// frames, the strings they own, program counters and metadata. Strings
+// of frames captured live point into the executable and are not counted,
+// unless a FileMapper or decorator rewrote them; strings shared between
+// frames are counted once. Build metadata shared by every stack of the
+// process is not counted. It returns 0 for a nil stack.
+func (s *Stack) SizeBytes() int {
+	if s == nil {
+		return 0
+	}
+	var z sizer
+	z.stack(s)
+	return z.n
+}
+
+// SizeBytes returns an estimate of the memory retained by the Recorder:
+// its ring buffer, the callers and stacks it holds and their labels,
+// counted as by Stack.SizeBytes. A caller that is also a frame of the
+// stack of its entry is counted once.
+func (r *Recorder) SizeBytes() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	z := sizer{n: cap(r.entries) * entrySize}
+	for _, e := range r.entries {
+		z.n += len(e.Label)
+		z.stack(e.Stack)
+		if c, ok := e.Caller.(*callerInfo); ok {
+			z.caller(c)
+		}
+	}
+	return z.n
+}
+
+// SizeBytes returns an estimate of the memory retained by the arena: its
+// backing slices and the strings owned by the frames stored in them.
+func (a *FrameArena) SizeBytes() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	z := sizer{n: cap(a.chunks)*sliceHeaderSize + len(a.chunks)*arenaChunkSize*callerInfoSize}
+	for i := range a.n {
+		z.data(&a.chunks[i/arenaChunkSize][i%arenaChunkSize])
+	}
+	return z.n
+}
+
+// SizeBytes returns an estimate of the memory retained by the interner:
+// its index and the callers it holds, whose strings are shared with their
+// keys.
+func (in *Interner) SizeBytes() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	var z sizer
+	for e := in.order.Front(); e != nil; e = e.Next() {
+		z.n += mapEntrySize + keySize + pointerSize + listElementSize
+		if c, ok := e.Value.(*callerInfo); ok {
+			z.caller(c)
+		}
+	}
+	return z.n
+}
+
+// sizer accumulates a SizeBytes estimate, counting each caller and each
+// owned string once.
+type sizer struct {
+	n       int
+	callers map[*callerInfo]struct{}
+	strs    map[string]struct{}
+}
+
+// stack adds the size of s, which may be nil.
+func (z *sizer) stack(s *Stack) {
+	if s == nil {
+		return
+	}
+	z.n += stackSize + cap(s.frames)*pointerSize + cap(s.pcs)*pointerSize + len(s.buildID)
+	for _, f := range s.frames {
+		z.caller(f)
+	}
+}
+
+// caller adds the size of c and its data, unless c is nil or was
+// already counted.
+func (z *sizer) caller(c *callerInfo) {
+	if c == nil {
+		return
+	}
+	if _, ok := z.callers[c]; ok {
+		return
+	}
+	if z.callers == nil {
+		z.callers = make(map[*callerInfo]struct{})
+	}
+	z.callers[c] = struct{}{}
+	z.n += callerInfoSize
+	z.data(c)
+}
+
+// data adds the size of the strings c owns that were not counted yet,
+// and of its optional data.
+func (z *sizer) data(c *callerInfo) {
+	if c.has(flagOwnsFile) {
+		z.str(c.file)
+	}
+	if c.has(flagOwnsFunc) {
+		z.str(c.fn)
+	}
+	if c.extra != nil {
+		z.n += frameExtrasSize
+		for _, l := range c.extra.labels {
+			z.n += len(l)
+		}
+		if c.extra.recap != nil {
+			z.n += cap(c.extra.recap.pcs) * pointerSize
+		}
+	}
+}
+
+// str adds the size of s, unless it was already counted.
+func (z *sizer) str(s string) {
+	if _, ok := z.strs[s]; ok {
+		return
+	}
+	if z.strs == nil {
+		z.strs = make(map[string]struct{})
+	}
+	z.strs[s] = struct{}{}
+	z.n += len(s)
+}
+
+// RetainedBytes returns an estimate of the memory retained by the
+// package-wide diagnostic state: the Recorder installed with SetRecorder,
+// the per-call-site state of Suppress and Deprecated, the records of
+// TrackInit and the paths cached by ResolveSymlinks. Services that keep
+// many captures can export it as a gauge to monitor and bound the cost of
+// diagnostics; stacks they keep themselves are measured with
+// Stack.SizeBytes.
+func RetainedBytes() int {
+	n := 0
+	if r := recorder.Load(); r != nil {
+		n += r.SizeBytes()
+	}
+	n += suppressions.sizeBytes()
+	n += deprecatedSites.sizeBytes()
+	n += syncMapSize(&deprecations, func(any, any) int {
+		return int(reflect.TypeFor[deprecationKey]().Size())
+	})
+	n += syncMapSize(&resolvedFiles, func(k, v any) int {
+		file, _ := k.(string)
+		resolved, _ := v.(string)
+		return len(file) + len(resolved)
+	})
+
+	initMu.Lock()
+	z := sizer{n: cap(initRecords) * initRecordSize}
+	for _, r := range initRecords {
+		if c, ok := r.Caller.(*callerInfo); ok {
+			z.caller(c)
+		}
+	}
+	initMu.Unlock()
+	return n + z.n
+}
+
+// sizeBytes returns an estimate of the memory retained by s.
+func (s *siteMap[T]) sizeBytes() int {
+	valueSize := int(reflect.TypeFor[T]().Size())
+	n := syncMapSize(&s.byPC, func(any, any) int { return pointerSize })
+	// The strings of the keys come from the runtime and point into the
+	// executable
+	n += syncMapSize(&s.bySite, func(any, any) int {
+		return int(reflect.TypeFor[siteKey]().Size()) + valueSize
+	})
+	return n
+}
+
+// syncMapSize returns an estimate of the memory retained by m, with the
+// size of the data of each entry reported by size.
+func syncMapSize(m *sync.Map, size func(k, v any) int) int {
+	n := 0
+	m.Range(func(k, v any) bool {
+		n += syncMapEntrySize + size(k, v)
+		return true
+	})
+	return n
+}
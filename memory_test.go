@@ -0,0 +1,82 @@
+package caller
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestStack_SizeBytes tests that the estimate grows with the frames,
+// counts shared strings once, and leaves out strings that point into the
+// executable.
+func TestStack_SizeBytes(t *testing.T) {
+	t.Parallel()
+
+	long := "/src/" + strings.Repeat("x", 1000) + ".go"
+	frame := func(file string) *callerInfo {
+		return &callerInfo{file: file, line: 1, fn: "pkg.F", dotIdx: 3, flags: flagOwnsStrings}
+	}
+	empty := (&Stack{}).SizeBytes()
+	one := (&Stack{frames: []*callerInfo{frame(long)}}).SizeBytes()
+	shared := (&Stack{frames: []*callerInfo{frame(long), frame(long)}}).SizeBytes()
+	distinct := (&Stack{frames: []*callerInfo{frame(long), frame(long + "2")}}).SizeBytes()
+
+	if empty <= 0 || one < empty+len(long) {
+		t.Errorf("SizeBytes() = %d for an empty stack and %d for one frame, want the frame's path counted", empty, one)
+	}
+	if shared >= one+len(long) {
+		t.Errorf("SizeBytes() = %d with a shared path, want it counted once (one frame: %d)", shared, one)
+	}
+	if distinct < one+len(long) {
+		t.Errorf("SizeBytes() = %d with distinct paths, want both counted (one frame: %d)", distinct, one)
+	}
+	if got := (*Stack)(nil).SizeBytes(); got != 0 {
+		t.Errorf("SizeBytes() of a nil stack = %d, want 0", got)
+	}
+	live := stackHelper(0, KeepAllFrames())
+	if got := live.SizeBytes(); got <= empty {
+		t.Errorf("SizeBytes() of a live stack = %d, want more than %d", got, empty)
+	}
+	frames := (&Stack{frames: live.frames}).SizeBytes() - empty
+	if want := live.Len()*callerInfoSize + cap(live.frames)*pointerSize; frames != want {
+		t.Errorf("SizeBytes() of live frames = %d, want %d without their strings", frames, want)
+	}
+}
+
+// TestRecorder_SizeBytes tests that a caller recorded with its stack is
+// counted once.
+func TestRecorder_SizeBytes(t *testing.T) {
+	t.Parallel()
+
+	c := &callerInfo{file: "/src/a.go", line: 1, fn: "pkg.A", dotIdx: 3, flags: flagOwnsStrings}
+	s := &Stack{frames: []*callerInfo{c}}
+
+	withCaller, withBoth := NewRecorder(1), NewRecorder(1)
+	withCaller.Record(c, "")
+	withBoth.add(Entry{Caller: c, Stack: s})
+	if got, want := withBoth.SizeBytes(), withCaller.SizeBytes()+s.SizeBytes()-callerInfoSize-len(c.file)-len(c.fn); got != want {
+		t.Errorf("SizeBytes() = %d, want %d with the caller counted once", got, want)
+	}
+}
+
+// TestRetainedBytes tests that the package-wide estimate includes the
+// installed Recorder.
+func TestRetainedBytes(t *testing.T) {
+	restoreGlobal(t, &recorder)
+
+	before := RetainedBytes()
+	r := NewRecorder(10)
+	SetRecorder(r)
+	empty := r.SizeBytes()
+	if got := RetainedBytes(); got != before+empty {
+		t.Errorf("RetainedBytes() = %d, want %d plus the empty Recorder's %d", got, before, empty)
+	}
+
+	r.Record(&callerInfo{file: "/src/a.go", line: 1, fn: "pkg.A", dotIdx: 3}, "label")
+	if got := r.SizeBytes(); got <= empty {
+		t.Errorf("Recorder.SizeBytes() = %d after recording, want more than %d", got, empty)
+	}
+	if Suppress(time.Hour) && RetainedBytes() <= before+r.SizeBytes() {
+		t.Error("RetainedBytes() does not include the state of Suppress")
+	}
+}
@@ -0,0 +1,103 @@
+package caller
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// buildModules returns the paths of the main module and its dependencies
+// recorded in the build information of the running executable, which are
+// read once. It returns nil if the executable was not built in module
+// mode.
+var buildModules = sync.OnceValue(func() []string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	paths := make([]string, 0, len(bi.Deps)+1)
+	if bi.Main.Path != "" {
+		paths = append(paths, bi.Main.Path)
+	}
+	for _, dep := range bi.Deps {
+		paths = append(paths, dep.Path)
+	}
+	return paths
+})
+
+// mainModule returns the path of the main module recorded in the build
+// information of the running executable, which is read once, or the
+// empty string if there is none.
+var mainModule = sync.OnceValue(func() string {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		return bi.Main.Path
+	}
+	return ""
+})
+
+// resolveMain returns pkg, or the path of the main module if pkg is the
+// main package, whose import path "main" names no module.
+func resolveMain(pkg string) string {
+	if pkg == "main" {
+		if m := mainModule(); m != "" {
+			return m
+		}
+	}
+	return pkg
+}
+
+// FirstExternalCaller returns the first caller above the function calling
+// FirstExternalCaller that belongs to a different module, as recorded in
+// the build information of the executable, so that a library can identify
+// the code that triggered it however deep inside the library it asks:
+//
+//	func (c *Client) do(req *Request) error {
+//		if err := c.send(req); err != nil {
+//			return fmt.Errorf("request from %v: %w", caller.FirstExternalCaller(), err)
+//		}
+//		...
+//	}
+//
+// Called from the main module, it returns the first caller outside it;
+// the main package counts as part of the main module. Packages without a
+// module, such as those of the standard library, and all packages of
+// executables built without module information count as modules of their
+// own.
+// It returns nil if there is no such caller.
+func FirstExternalCaller() Caller {
+	frames := runtime.CallersFrames(callers(1))
+
+	frame, more := frames.Next()
+	if frame.Function == "" && frame.File == "" {
+		return nil
+	}
+	modules := buildModules()
+	mod := moduleOf(frameCallerInfo(frame).Package(), modules)
+
+	for more {
+		frame, more = frames.Next()
+		c := frameCallerInfo(frame)
+		if moduleOf(c.Package(), modules) != mod {
+			return captured(c, "")
+		}
+	}
+	return nil
+}
+
+// moduleOf returns the path of the module among modules that provides
+// pkg, which is the longest one that is pkg itself or a path prefix of
+// it, or pkg if none does. The main package belongs to the main module.
+func moduleOf(pkg string, modules []string) string {
+	pkg = resolveMain(pkg)
+	best := ""
+	for _, m := range modules {
+		if len(m) > len(best) && (pkg == m || strings.HasPrefix(pkg, m+"/")) {
+			best = m
+		}
+	}
+	if best == "" {
+		return pkg
+	}
+	return best
+}
@@ -0,0 +1,52 @@
+package caller
+
+import "testing"
+
+// moduleHelper calls FirstExternalCaller from a nested function of this
+// module, as library code would.
+func moduleHelper() Caller {
+	return func() Caller {
+		return FirstExternalCaller()
+	}()
+}
+
+// TestFirstExternalCaller tests that the walk leaves every frame of this
+// module behind.
+func TestFirstExternalCaller(t *testing.T) {
+	t.Parallel()
+
+	if got := moduleHelper(); got == nil || got.FullFunction() != "testing.tRunner" {
+		t.Errorf("FirstExternalCaller() = %v, want testing.tRunner", got)
+	}
+}
+
+// TestModuleOf tests matching packages to the modules providing them.
+func TestModuleOf(t *testing.T) {
+	t.Parallel()
+
+	modules := []string{"example.com/app", "example.com/app/sdk", "golang.org/x/text"}
+	tests := []struct {
+		pkg  string
+		want string
+	}{
+		{"example.com/app", "example.com/app"},
+		{"example.com/app/internal/db", "example.com/app"},
+		{"example.com/app/sdk/client", "example.com/app/sdk"},
+		{"example.com/application", "example.com/application"},
+		{"golang.org/x/text/unicode/norm", "golang.org/x/text"},
+		{"net/http", "net/http"},
+		{"main", thisPackage},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pkg, func(t *testing.T) {
+			t.Parallel()
+			if got := moduleOf(tt.pkg, modules); got != tt.want {
+				t.Errorf("moduleOf(%q) = %q, want %q", tt.pkg, got, tt.want)
+			}
+		})
+	}
+	if got := moduleOf("example.com/app/x", nil); got != "example.com/app/x" {
+		t.Errorf("moduleOf() without modules = %q, want the package", got)
+	}
+}
@@ -0,0 +1,256 @@
+package caller
+
+import (
+	"runtime"
+	"time"
+)
+
+// Option configures a capture made with NewWith, NewStack or CaptureStack.
+type Option func(*captureConfig)
+
+// captureConfig holds the settings applied by Option values.
+type captureConfig struct {
+	skip      []Matcher // Frames to pass over
+	timestamp bool      // Whether to record the capture time
+	minDepth  int       // Minimum number of stack frames required
+	keepAll   bool      // Whether stack captures keep noise frames
+	recapture bool      // Whether to keep the stack for Recapture
+	noFunc    bool      // Whether to leave out the function name
+	goid      bool      // Whether stack captures record the goroutine ID
+	buildID   string    // Build ID recorded by stack captures
+
+	inApp      *InAppRules        // Rules classifying frames as in-app, if any
+	decorators []func(*FrameInfo) // Decorators run on every resolved frame
+}
+
+// SkipFrames makes a capture pass over frames matching any of matchers,
+// as if they were not on the stack. Combine it with MatchPackage to skip
+// wrapper packages, or with MatchFunctionSuffix to skip closures and
+// other noise frames:
+//
+//	c := caller.NewWith(0, caller.SkipFrames(
+//		caller.MatchPackage("example.com/app/internal/log/..."),
+//		caller.MatchFunctionSuffix(`\.func\d+`),
+//	))
+func SkipFrames(matchers ...Matcher) Option {
+	return func(cfg *captureConfig) {
+		cfg.skip = append(cfg.skip, matchers...)
+	}
+}
+
+// WithTimestamp makes a capture record the time it was taken, including
+// a monotonic clock reading, for callers that are queued or stored and
+// whose age matters. Read it back through the Timestamped interface.
+func WithTimestamp() Option {
+	return func(cfg *captureConfig) {
+		cfg.timestamp = true
+	}
+}
+
+// RequireDepth makes a stack capture fail unless at least n frames
+// remain after skipping: CaptureStack then returns a *DepthError, and
+// NewStack returns nil. It detects misconfigured skips, or captures made
+// too close to the root of a goroutine, that would otherwise silently
+// yield short stacks. It has no effect on single-caller captures.
+func RequireDepth(n int) Option {
+	return func(cfg *captureConfig) {
+		cfg.minDepth = n
+	}
+}
+
+// WithRecapture makes a capture keep the program counters of the stack it
+// was taken from, so that the result can be shifted to another frame
+// later with its Recapture method; see Recapturable. It costs one stack
+// walk and the memory of the program counters per capture.
+func WithRecapture() Option {
+	return func(cfg *captureConfig) {
+		cfg.recapture = true
+	}
+}
+
+// WithoutFunction makes a capture record the file and line only, for
+// users who never render function names and want the cheapest possible
+// capture: the Caller it returns has an empty Function, FullFunction and
+// Package. Unless frames are skipped with SkipFrames, kept for Recapture,
+// classified with WithInAppRules or seen by decorators, which all need
+// the function name, its name is never resolved. It has no effect on
+// stack captures.
+func WithoutFunction() Option {
+	return func(cfg *captureConfig) {
+		cfg.noFunc = true
+	}
+}
+
+// KeepAllFrames makes a stack capture keep the frames it skips by
+// default: those of the runtime package, including runtime.goexit at the
+// root of every goroutine, and testing.tRunner. It has no effect on
+// single-caller captures, which never skip them.
+func KeepAllFrames() Option {
+	return func(cfg *captureConfig) {
+		cfg.keepAll = true
+	}
+}
+
+// WithGoroutineID makes a stack capture record the ID of the goroutine
+// it was taken on, read back with Stack.GoroutineID and written in the
+// header of Stack.Traceback. The runtime exposes the ID only in its own
+// traceback, so recording it costs a call to runtime.Stack per capture.
+// It has no effect on single-caller captures.
+func WithGoroutineID() Option {
+	return func(cfg *captureConfig) {
+		cfg.goid = true
+	}
+}
+
+// WithBuildID makes a stack capture record id as the build ID of the
+// executable it was taken in, carried in its JSON and wire encodings so
+// that out-of-process symbolization can check that it resolves program
+// counters against the matching executable. Pass the ID reported by
+// package callersym, which reads it from the executable once:
+//
+//	s := caller.NewStack(0, caller.WithBuildID(callersym.BuildID()))
+//
+// It has no effect on single-caller captures.
+func WithBuildID(id string) Option {
+	return func(cfg *captureConfig) {
+		cfg.buildID = id
+	}
+}
+
+// isNoiseFrame reports whether c is a frame that stack captures skip
+// unless KeepAllFrames is given.
+func isNoiseFrame(c Caller) bool {
+	return c.Package() == "runtime" || c.FullFunction() == "testing.tRunner"
+}
+
+// NewWith returns a new Caller like New, configured by opts.
+// The skip parameter has the same meaning as for New and is applied
+// before any frames are skipped by options.
+// It returns nil if the skip is invalid or no matching caller is found.
+func NewWith(skip int, opts ...Option) Caller {
+	if skip < 0 {
+		return nil
+	}
+	cfg := newCaptureConfig(opts)
+	if cfg.noFunc && !cfg.recapture && len(cfg.skip) == 0 && cfg.inApp == nil && len(cfg.decorators) == 0 {
+		return newFileLine(skip+1, cfg)
+	}
+
+	var found *callerInfo
+	if cfg.recapture {
+		// Start at the function calling NewWith, which Recapture can
+		// shift back to, and skip it as New would
+		found = findRecapturable(callers(1), skip+1, cfg)
+	} else {
+		// Skip callers itself, NewWith, and the function calling NewWith
+		cfg.walk(callers(skip+skipAdjust), func(c *callerInfo, _ uintptr) bool {
+			found = c
+			return false
+		})
+	}
+	if found == nil {
+		return nil
+	}
+	if cfg.noFunc {
+		found.fn, found.dotIdx = "", -1
+	}
+	return captured(found, "")
+}
+
+// newFileLine returns a Caller with the file and line only of the
+// function skip frames above the caller of newFileLine, as New would,
+// without resolving its function name.
+func newFileLine(skip int, cfg captureConfig) Caller {
+	// Skip runtime.Callers, newFileLine, and the function calling
+	// newFileLine, and resolve the return address directly as Capture does
+	var pcs [1]uintptr
+	if runtime.Callers(skip+skipAdjust+1, pcs[:]) == 0 {
+		return nil
+	}
+	pc := pcs[0] - 1
+	f := runtime.FuncForPC(pc)
+	if f == nil {
+		return nil
+	}
+	file, line := f.FileLine(pc)
+	c := newCallerInfo(file, line, "")
+	c.setCapturedAt(cfg.now())
+	return captured(c, "")
+}
+
+// NewSkippingUntil returns a new Caller for the first frame above the
+// function named fullFunc, as returned by FullFunction, on the stack of
+// the calling goroutine. It lets a framework attribute a call to whatever
+// called its public API, however many internal calls lie in between:
+//
+//	func (r *Router) Handle(pattern string, h Handler) {
+//		r.register(pattern, h, caller.NewSkippingUntil("example.com/web.(*Router).Handle"))
+//	}
+//
+// Consecutive frames of fullFunc, as in recursion, are passed over
+// together. It returns nil if fullFunc is not on the stack or nothing
+// lies above it.
+func NewSkippingUntil(fullFunc string) Caller {
+	var found *callerInfo
+	inside := false
+	// Start at the function calling NewSkippingUntil, which may itself be
+	// fullFunc
+	forEachFrame(callers(1), func(_ int, c *callerInfo) bool {
+		if c.fn == fullFunc {
+			inside = true
+			return true
+		}
+		if !inside {
+			return true
+		}
+		found = c
+		return false
+	})
+	if found == nil {
+		return nil
+	}
+	return captured(found, "")
+}
+
+// newCaptureConfig applies opts to a fresh captureConfig.
+func newCaptureConfig(opts []Option) captureConfig {
+	var cfg captureConfig
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	return cfg
+}
+
+// walk resolves pcs into frames, innermost first, and calls yield with
+// each frame the configuration does not skip and its call-site program
+// counter, until yield returns false.
+func (cfg captureConfig) walk(pcs []uintptr, yield func(*callerInfo, uintptr) bool) {
+	at := cfg.now()
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.File != "" || frame.Function != "" {
+			c := frameCallerInfo(frame)
+			c.setCapturedAt(at)
+			c.setFlag(flagInlined, frame.Func == nil)
+			cfg.decorate(c)
+			if !c.hidden() && !matchAny(cfg.skip, c) && !yield(c, frame.PC) {
+				return
+			}
+		}
+		if !more {
+			return
+		}
+	}
+}
+
+// now returns the current time if cfg records capture times, or the zero
+// time otherwise.
+func (cfg captureConfig) now() time.Time {
+	if cfg.timestamp {
+		return time.Now()
+	}
+	return time.Time{}
+}
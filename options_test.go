@@ -0,0 +1,166 @@
+package caller
+
+import (
+	"runtime"
+	"testing"
+)
+
+// TestNewWith tests that NewWith matches New without options and passes
+// over frames matched by SkipFrames.
+func TestNewWith(t *testing.T) {
+	t.Parallel()
+
+	if c := NewWith(-1); c != nil {
+		t.Errorf("NewWith(-1) = %v, want nil", c)
+	}
+
+	wrap := func(opts ...Option) Caller {
+		return NewWith(0, opts...)
+	}
+	c := wrap()
+	_, file, line, _ := runtime.Caller(0)
+	if c.File() != file || c.Line() != line-1 {
+		t.Errorf("NewWith(0) = %q, want %s:%d", c.Location(), file, line-1)
+	}
+
+	// The closure calling wrap is itself skipped
+	func() {
+		c = wrap(SkipFrames(MatchFunctionSuffix(`\.func\d+`)), nil)
+	}()
+	if got, want := c.Function(), "TestNewWith"; got != want {
+		t.Errorf("NewWith(0, SkipFrames(closures)).Function() = %q, want %q", got, want)
+	}
+
+	c = wrap(SkipFrames(MatchPackage("github.com/balinomad/go-caller/v2")))
+	if got, want := c.FullFunction(), "testing.tRunner"; got != want {
+		t.Errorf("NewWith(0, SkipFrames(this package)).FullFunction() = %q, want %q", got, want)
+	}
+
+	if c = wrap(SkipFrames(func(Caller) bool { return true })); c != nil {
+		t.Errorf("NewWith(0, SkipFrames(all)) = %v, want nil", c)
+	}
+}
+
+// TestNewWith_WithoutFunction tests that WithoutFunction captures the
+// same location without a function name, alone and with other options.
+func TestNewWith_WithoutFunction(t *testing.T) {
+	t.Parallel()
+
+	wrap := func(opts ...Option) Caller {
+		return NewWith(0, append([]Option{WithoutFunction()}, opts...)...)
+	}
+	alone := wrap()
+	timestamped := wrap(WithTimestamp())
+	recapturable := wrap(WithRecapture())
+	var skipped Caller
+	func() {
+		skipped = wrap(SkipFrames(MatchFunctionSuffix(`\.func\d+`)))
+	}()
+	_, file, line, _ := runtime.Caller(0)
+
+	tests := []struct {
+		name string
+		c    Caller
+		line int
+	}{
+		{"alone", alone, line - 7},
+		{"with timestamp", timestamped, line - 6},
+		{"with recapture", recapturable, line - 5},
+		{"with skipped frames", skipped, line - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if tt.c == nil || tt.c.File() != file || tt.c.Line() != tt.line {
+				t.Fatalf("NewWith(0, WithoutFunction()) = %v, want %s:%d", tt.c, file, tt.line)
+			}
+			if tt.c.Function() != "" || tt.c.FullFunction() != "" || tt.c.Package() != "" {
+				t.Errorf("NewWith(0, WithoutFunction()) kept function %q", tt.c.FullFunction())
+			}
+		})
+	}
+
+	if at := capturedAt(timestamped); at.IsZero() {
+		t.Error("NewWith(0, WithoutFunction(), WithTimestamp()) did not record the time")
+	}
+	if c := NewWith(-1, WithoutFunction()); c != nil {
+		t.Errorf("NewWith(-1, WithoutFunction()) = %v, want nil", c)
+	}
+	if c := NewWith(10000, WithoutFunction()); c != nil {
+		t.Errorf("NewWith(10000, WithoutFunction()) = %v, want nil", c)
+	}
+}
+
+// TestNewStack_SkipFrames tests that NewStack drops frames matched by
+// SkipFrames.
+func TestNewStack_SkipFrames(t *testing.T) {
+	t.Parallel()
+
+	var s *Stack
+	func() {
+		s = NewStack(0, SkipFrames(MatchFunctionSuffix(`\.func\d+`)))
+	}()
+	if got, want := s.Caller0().Function(), "TestNewStack_SkipFrames"; got != want {
+		t.Errorf("Caller0().Function() = %q, want %q", got, want)
+	}
+	for _, f := range s.Frames() {
+		if MatchFunctionSuffix(`\.func\d+`)(f) {
+			t.Errorf("Frames() contains skipped frame %q", f.FullFunction())
+		}
+	}
+
+	if s = NewStack(0, SkipFrames(func(Caller) bool { return true })); s != nil {
+		t.Errorf("NewStack(0, SkipFrames(all)) = %v, want nil", s)
+	}
+}
+
+// TestWithBuildID tests that stack captures record the build ID they are
+// given, and none otherwise.
+func TestWithBuildID(t *testing.T) {
+	t.Parallel()
+
+	if got := stackHelper(0, WithBuildID("abc/def")).BuildID(); got != "abc/def" {
+		t.Errorf("BuildID() = %q, want %q", got, "abc/def")
+	}
+	if got := stackHelper(0).BuildID(); got != "" {
+		t.Errorf("BuildID() without WithBuildID = %q, want empty", got)
+	}
+}
+
+// skipUntilAPI stands in for a public API whose callers are attributed
+// with NewSkippingUntil, through internal helpers of varying depth.
+func skipUntilAPI(depth int) Caller {
+	if depth > 0 {
+		return skipUntilAPI(depth - 1)
+	}
+	return skipUntilHelper()
+}
+
+// skipUntilHelper is an internal helper of skipUntilAPI.
+func skipUntilHelper() Caller {
+	return NewSkippingUntil("github.com/balinomad/go-caller/v2.skipUntilAPI")
+}
+
+// TestNewSkippingUntil tests that NewSkippingUntil returns the frame
+// above the named function, regardless of the call depth below it.
+func TestNewSkippingUntil(t *testing.T) {
+	t.Parallel()
+
+	for _, depth := range []int{0, 3} {
+		c := skipUntilAPI(depth)
+		_, file, line, _ := runtime.Caller(0)
+		if c.Function() != "TestNewSkippingUntil" || c.File() != file || c.Line() != line-1 {
+			t.Errorf("NewSkippingUntil() at depth %d = %v, want %s:%d", depth, c, file, line-1)
+		}
+	}
+
+	if got := skipUntilHelper(); got != nil {
+		t.Errorf("NewSkippingUntil() without the function on the stack = %v, want nil", got)
+	}
+	if got := NewSkippingUntil("runtime.goexit"); got != nil {
+		t.Errorf("NewSkippingUntil(runtime.goexit) = %v, want nil with nothing above", got)
+	}
+	if got, want := NewSkippingUntil("github.com/balinomad/go-caller/v2.TestNewSkippingUntil").FullFunction(), "testing.tRunner"; got != want {
+		t.Errorf("NewSkippingUntil(calling function) = %q, want %q", got, want)
+	}
+}
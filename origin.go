@@ -0,0 +1,78 @@
+package caller
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// Origin is the location a generated source line was produced from, such
+// as a line of a .proto, .templ or interface definition file.
+type Origin struct {
+	File string // Path of the source the line was generated from
+	Line int    // Line number in that source, or 0 if unknown
+}
+
+// String returns the origin formatted as file:line, or just the file if
+// the line is unknown.
+func (o Origin) String() string {
+	if o.Line <= 0 {
+		return o.File
+	}
+	return o.File + ":" + strconv.Itoa(o.Line)
+}
+
+// OriginMapper translates a location in a generated file, such as one
+// written by protoc, mockgen or templ, back to the source it was
+// generated from. It reports false for locations it does not recognize.
+// It must be safe for concurrent use.
+type OriginMapper func(file string, line int) (Origin, bool)
+
+// originMapper holds the package-wide OriginMapper, or nil if none is set.
+var originMapper atomic.Pointer[OriginMapper]
+
+// SetOriginMapper installs m as the package-wide OriginMapper consulted
+// by OriginOf. Combine mappers for several generators with
+// ChainOriginMappers. Passing nil removes any previously installed mapper.
+func SetOriginMapper(m OriginMapper) {
+	if m == nil {
+		originMapper.Store(nil)
+		return
+	}
+	originMapper.Store(&m)
+}
+
+// ChainOriginMappers returns an OriginMapper that tries each of mappers
+// in order and returns the first origin found. Nil mappers are skipped.
+func ChainOriginMappers(mappers ...OriginMapper) OriginMapper {
+	return func(file string, line int) (Origin, bool) {
+		for _, m := range mappers {
+			if m == nil {
+				continue
+			}
+			if o, ok := m(file, line); ok {
+				return o, true
+			}
+		}
+		return Origin{}, false
+	}
+}
+
+// OriginOf returns the source location that the location of c was
+// generated from, as reported by the OriginMapper installed with
+// SetOriginMapper, so that reports can show both the generated location
+// and the file developers actually edit:
+//
+//	if o, ok := caller.OriginOf(c); ok {
+//		msg += " (generated from " + o.String() + ")"
+//	}
+//
+// It reports false if c is nil or has no file, no mapper is installed,
+// or the mapper does not recognize the location.
+func OriginOf(c Caller) (Origin, bool) {
+	m := originMapper.Load()
+	file := File(c)
+	if m == nil || file == "" {
+		return Origin{}, false
+	}
+	return (*m)(file, Line(c))
+}
@@ -0,0 +1,87 @@
+package caller
+
+import (
+	"strings"
+	"testing"
+)
+
+// templOrigin maps lines of generated x_templ.go files to x.templ, with
+// line numbers offset by 10.
+func templOrigin(file string, line int) (Origin, bool) {
+	base, ok := strings.CutSuffix(file, "_templ.go")
+	if !ok {
+		return Origin{}, false
+	}
+	return Origin{File: base + ".templ", Line: line - 10}, true
+}
+
+// protoOrigin maps generated .pb.go files to their .proto file, with the
+// line unknown.
+func protoOrigin(file string, _ int) (Origin, bool) {
+	base, ok := strings.CutSuffix(file, ".pb.go")
+	if !ok {
+		return Origin{}, false
+	}
+	return Origin{File: base + ".proto"}, true
+}
+
+// TestChainOriginMappers tests that chained mappers are tried in order.
+func TestChainOriginMappers(t *testing.T) {
+	t.Parallel()
+
+	m := ChainOriginMappers(nil, templOrigin, protoOrigin)
+	tests := []struct {
+		file   string
+		line   int
+		want   Origin
+		wantOK bool
+	}{
+		{"/src/views/page_templ.go", 42, Origin{File: "/src/views/page.templ", Line: 32}, true},
+		{"/src/api/user.pb.go", 7, Origin{File: "/src/api/user.proto"}, true},
+		{"/src/main.go", 3, Origin{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			t.Parallel()
+			got, ok := m(tt.file, tt.line)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("mapper(%q, %d) = %+v, %v, want %+v, %v", tt.file, tt.line, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	if got := (Origin{File: "a.templ", Line: 3}).String(); got != "a.templ:3" {
+		t.Errorf("String() = %q, want %q", got, "a.templ:3")
+	}
+	if got := (Origin{File: "a.proto"}).String(); got != "a.proto" {
+		t.Errorf("String() = %q, want %q", got, "a.proto")
+	}
+}
+
+// TestOriginOf tests resolving origins through the installed mapper.
+func TestOriginOf(t *testing.T) {
+	restoreGlobal(t, &originMapper)
+
+	c := &callerInfo{file: "/src/views/page_templ.go", line: 42, fn: "views.Page", dotIdx: 5}
+	if _, ok := OriginOf(c); ok {
+		t.Error("OriginOf() reported true with no mapper installed")
+	}
+
+	SetOriginMapper(templOrigin)
+	if got, ok := OriginOf(c); !ok || got != (Origin{File: "/src/views/page.templ", Line: 32}) {
+		t.Errorf("OriginOf() = %+v, %v, want page.templ:32", got, ok)
+	}
+	if c.File() != "/src/views/page_templ.go" {
+		t.Errorf("File() = %q, want the generated file unchanged", c.File())
+	}
+	for _, other := range []Caller{nil, &callerInfo{line: 1}, &callerInfo{file: "/src/main.go", line: 1}} {
+		if got, ok := OriginOf(other); ok {
+			t.Errorf("OriginOf(%v) = %+v, want false", other, got)
+		}
+	}
+
+	SetOriginMapper(nil)
+	if _, ok := OriginOf(c); ok {
+		t.Error("OriginOf() reported true after removing the mapper")
+	}
+}
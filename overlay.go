@@ -0,0 +1,84 @@
+package caller
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Overlay maps file paths substituted by a `go build -overlay` file back
+// to the on-disk paths they replace. Build systems such as Bazel and
+// gopls-driven builds compile from overlay copies, so the paths recorded
+// in the binary point at temporary files rather than at the developer's
+// checkout.
+//
+// An Overlay is immutable once created and safe for concurrent use.
+// Its Map method satisfies FileMapper:
+//
+//	ov, err := caller.LoadOverlay("overlay.json")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	caller.SetFileMapper(ov.Map)
+type Overlay struct {
+	originals map[string]string // Overlay path -> on-disk path
+}
+
+// LoadOverlay reads and parses the overlay file at name.
+func LoadOverlay(name string) (*Overlay, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("read overlay: %w", err)
+	}
+	return ParseOverlay(data)
+}
+
+// ParseOverlay parses overlay data in the format accepted by the go
+// command's -overlay flag: a JSON object with a single "Replace" field
+// mapping on-disk paths to the paths that replace them.
+// Entries with an empty replacement delete the file from the build and
+// are ignored.
+func ParseOverlay(data []byte) (*Overlay, error) {
+	var aux struct {
+		Replace map[string]string
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return nil, fmt.Errorf("JSON unmarshal: %w", err)
+	}
+
+	o := &Overlay{originals: make(map[string]string, len(aux.Replace))}
+	for original, replacement := range aux.Replace {
+		if replacement == "" {
+			continue
+		}
+		o.originals[overlayKey(replacement)] = filepath.ToSlash(filepath.Clean(original))
+	}
+	return o, nil
+}
+
+// Map returns the on-disk path that file replaced in the build, or file
+// unchanged if it is not an overlay path.
+func (o *Overlay) Map(file string) string {
+	if o == nil || file == "" {
+		return file
+	}
+	if original, ok := o.originals[overlayKey(file)]; ok {
+		return original
+	}
+	return file
+}
+
+// Len returns the number of replacements in the overlay.
+func (o *Overlay) Len() int {
+	if o == nil {
+		return 0
+	}
+	return len(o.originals)
+}
+
+// overlayKey normalizes a path for lookup. Runtime file paths always use
+// forward slashes, while overlay files may be written with either.
+func overlayKey(path string) string {
+	return filepath.ToSlash(filepath.Clean(path))
+}
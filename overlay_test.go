@@ -0,0 +1,87 @@
+package caller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestParseOverlay tests that ParseOverlay builds a reverse mapping from
+// replacement paths to on-disk paths and ignores deletions.
+func TestParseOverlay(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{"Replace":{
+		"/home/dev/repo/main.go": "/tmp/overlay/main.go",
+		"/home/dev/repo/gone.go": "",
+		"/home/dev/repo/./util.go": "/tmp/overlay//util.go"
+	}}`)
+	o, err := ParseOverlay(data)
+	if err != nil {
+		t.Fatalf("ParseOverlay() error = %v", err)
+	}
+	if got, want := o.Len(), 2; got != want {
+		t.Errorf("Len() = %d, want %d", got, want)
+	}
+
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{"replaced file", "/tmp/overlay/main.go", "/home/dev/repo/main.go"},
+		{"unclean paths", "/tmp/overlay/util.go", "/home/dev/repo/util.go"},
+		{"unknown file", "/tmp/overlay/other.go", "/tmp/overlay/other.go"},
+		{"original path", "/home/dev/repo/main.go", "/home/dev/repo/main.go"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := o.Map(tt.file); got != tt.want {
+				t.Errorf("Map(%q) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseOverlay_Invalid tests that malformed overlay data is rejected.
+func TestParseOverlay_Invalid(t *testing.T) {
+	t.Parallel()
+	if _, err := ParseOverlay([]byte(`{"Replace":`)); err == nil {
+		t.Error("ParseOverlay() expected an error, but got nil")
+	}
+}
+
+// TestLoadOverlay tests loading an overlay from disk, including a missing file.
+func TestLoadOverlay(t *testing.T) {
+	t.Parallel()
+
+	name := filepath.Join(t.TempDir(), "overlay.json")
+	if err := os.WriteFile(name, []byte(`{"Replace":{"/a/b.go":"/c/b.go"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	o, err := LoadOverlay(name)
+	if err != nil {
+		t.Fatalf("LoadOverlay() error = %v", err)
+	}
+	if got, want := o.Map("/c/b.go"), "/a/b.go"; got != want {
+		t.Errorf("Map() = %q, want %q", got, want)
+	}
+
+	if _, err := LoadOverlay(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadOverlay() of a missing file expected an error, but got nil")
+	}
+}
+
+// TestOverlay_Nil tests that a nil Overlay is usable.
+func TestOverlay_Nil(t *testing.T) {
+	t.Parallel()
+	var o *Overlay
+	if got := o.Map("/a.go"); got != "/a.go" {
+		t.Errorf("Map() = %q, want %q", got, "/a.go")
+	}
+	if got := o.Len(); got != 0 {
+		t.Errorf("Len() = %d, want 0", got)
+	}
+}
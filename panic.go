@@ -0,0 +1,173 @@
+package caller
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// gopanicFunc is the runtime function that runs deferred calls while a
+// goroutine panics.
+const gopanicFunc = "runtime.gopanic"
+
+// PanicError is an error wrapping a value recovered from a panic, with
+// the location of the panic and the stack that led to it, for recovery
+// middleware:
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			err = caller.NewPanicError(r)
+//		}
+//	}()
+type PanicError struct {
+	Value  any    // Value passed to panic, or its string form if decoded from JSON
+	Caller Caller // Function that panicked; nil if unknown
+	Stack  *Stack // Stack at the panic, starting at Caller; nil if unknown
+}
+
+// NewPanicError returns a PanicError for the value recovered from a
+// panic. It must be called from the deferred function that recovered the
+// value, or from a function that it calls directly, so that the stack of
+// the panicking goroutine is still available: Caller and Stack then
+// start at the function that panicked, leaving out the deferred function
+// and the runtime's panic machinery. Called anywhere else, they start
+// at the function calling NewPanicError.
+func NewPanicError(recovered any) *PanicError {
+	e := &PanicError{Value: recovered}
+	s := NewStack(0, KeepAllFrames(), WithGoroutineID())
+	if s == nil {
+		return e
+	}
+
+	if i := panickingFrame(s.frames); i >= 0 {
+		s = s.from(i)
+	}
+	s = s.withoutNoise()
+	e.Stack = s
+	if s.Len() > 0 {
+		e.Caller = s.Caller0()
+	}
+	return e
+}
+
+// panickingFrame returns the index of the frame that panicked, following
+// the runtime's panic machinery, or -1 if the frames are not those of a
+// panicking goroutine.
+func panickingFrame(frames []*callerInfo) int {
+	i := slices.IndexFunc(frames, func(f *callerInfo) bool { return f.fn == gopanicFunc })
+	if i < 0 {
+		return -1
+	}
+	// Runtime errors pass through further runtime frames, such as
+	// runtime.panicmem and runtime.sigpanic, on their way to gopanic
+	i++
+	for i < len(frames) && strings.HasPrefix(frames[i].fn, "runtime.") {
+		i++
+	}
+	return i
+}
+
+// withoutNoise returns a copy of s without the frames that a stack
+// capture skips by default, keeping the same capture metadata.
+func (s *Stack) withoutNoise() *Stack {
+	out := *s
+	out.frames = nil
+	out.pcs = nil
+	for i, f := range s.frames {
+		if isNoiseFrame(f) {
+			continue
+		}
+		out.frames = append(out.frames, f)
+		if i < len(s.pcs) {
+			out.pcs = append(out.pcs, s.pcs[i])
+		}
+	}
+	return &out
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	msg := "panic: " + e.value()
+	if isNil(e.Caller) {
+		return msg
+	}
+	return msg + " [" + e.Caller.FullFunction() + " (" + e.Caller.ShortLocation() + ")]"
+}
+
+// Unwrap returns the recovered value if it is an error, or nil otherwise.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// value returns the recovered value as a string.
+func (e *PanicError) value() string {
+	if err, ok := e.Value.(error); ok {
+		return err.Error()
+	}
+	return fmt.Sprint(e.Value)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The recovered
+// value is encoded in its string form, under "value".
+func (e *PanicError) MarshalJSON() ([]byte, error) {
+	if e == nil {
+		return []byte("null"), nil
+	}
+	b, err := json.Marshal(struct {
+		Value  string `json:"value"`
+		Caller Caller `json:"caller,omitempty"`
+		Stack  *Stack `json:"stack,omitempty"`
+	}{
+		Value:  e.value(),
+		Caller: e.Caller,
+		Stack:  e.Stack,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("JSON marshal: %w", err)
+	}
+	return b, nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The decoded
+// Value is the string form of the original value.
+func (e *PanicError) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		Value  string          `json:"value"`
+		Caller json.RawMessage `json:"caller"`
+		Stack  *Stack          `json:"stack"`
+	}{}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return fmt.Errorf("JSON unmarshal: %w", err)
+	}
+
+	e.Value, e.Caller, e.Stack = aux.Value, nil, aux.Stack
+	if len(aux.Caller) > 0 && string(aux.Caller) != "null" {
+		c := NewEmpty()
+		if err := c.UnmarshalJSON(aux.Caller); err != nil {
+			return err
+		}
+		e.Caller = c
+	}
+	return nil
+}
+
+// LogValue implements the slog.LogValuer interface, rendering the value,
+// the caller and the stack as a group.
+func (e *PanicError) LogValue() slog.Value {
+	if e == nil {
+		return slog.Value{}
+	}
+	attrs := []slog.Attr{slog.String("value", e.value())}
+	if !isNil(e.Caller) {
+		attrs = append(attrs, slog.Any("caller", e.Caller))
+	}
+	if e.Stack != nil {
+		attrs = append(attrs, slog.Any("stack", e.Stack))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,115 @@
+package caller
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// errBoom is the value panicked with by panicWith.
+var errBoom = errors.New("boom")
+
+// panicWith panics with v, or dereferences a nil pointer if v is nil.
+//
+//go:noinline
+func panicWith(v any) {
+	if v == nil {
+		var p *int
+		_ = *p
+	}
+	panic(v)
+}
+
+// recoverPanic calls panicWith(v) and returns the recovered PanicError.
+func recoverPanic(v any) (err *PanicError) {
+	defer func() {
+		err = NewPanicError(recover())
+	}()
+	panicWith(v)
+	return nil
+}
+
+// TestNewPanicError tests that the caller and stack start at the
+// panicking function, for explicit panics and runtime errors.
+func TestNewPanicError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		value     any
+		wantError string
+		wantWrap  bool
+	}{
+		{"error", errBoom, "panic: boom", true},
+		{"string", "oops", "panic: oops", false},
+		{"runtime error", nil, "panic: runtime error: invalid memory address or nil pointer dereference", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := recoverPanic(tt.value)
+			if got := err.Caller.FullFunction(); !strings.HasSuffix(got, ".panicWith") {
+				t.Errorf("Caller = %q, want panicWith", got)
+			}
+			if got := err.Stack.Frame(1).FullFunction(); !strings.HasSuffix(got, ".recoverPanic") {
+				t.Errorf("Stack.Frame(1) = %q, want recoverPanic", got)
+			}
+			if got := err.Stack.Frame(err.Stack.Len() - 1).FullFunction(); !strings.HasSuffix(got, ".func1") {
+				t.Errorf("outermost frame = %q, want the subtest, without runtime or test runner frames", got)
+			}
+			if got := err.Error(); !strings.HasPrefix(got, tt.wantError+" [") || !strings.Contains(got, "panic_test.go:") {
+				t.Errorf("Error() = %q, want %q with the location", got, tt.wantError)
+			}
+			if got := err.Unwrap(); (got != nil) != tt.wantWrap {
+				t.Errorf("Unwrap() = %v, want an error: %v", got, tt.wantWrap)
+			}
+		})
+	}
+
+	if err := recoverPanic(errBoom); !errors.Is(err, errBoom) {
+		t.Errorf("errors.Is(%v, errBoom) = false, want true", err)
+	}
+
+	outside := NewPanicError("not recovered")
+	if got := outside.Caller.Function(); got != "TestNewPanicError" {
+		t.Errorf("Caller outside recovery = %q, want TestNewPanicError", got)
+	}
+}
+
+// TestPanicError_JSON tests encoding, decoding and logging.
+func TestPanicError_JSON(t *testing.T) {
+	t.Parallel()
+
+	err := recoverPanic(errBoom)
+	data, mErr := json.Marshal(err)
+	if mErr != nil {
+		t.Fatalf("json.Marshal() error = %v", mErr)
+	}
+
+	var got PanicError
+	if uErr := json.Unmarshal(data, &got); uErr != nil {
+		t.Fatalf("json.Unmarshal() error = %v", uErr)
+	}
+	if got.Value != "boom" || !got.Caller.Equal(err.Caller) || got.Stack.Len() != err.Stack.Len() {
+		t.Errorf("round trip = %+v, want %+v", got, err)
+	}
+
+	var bare PanicError
+	if uErr := json.Unmarshal([]byte(`{"value":"x","caller":null}`), &bare); uErr != nil || bare.Caller != nil || bare.Error() != "panic: x" {
+		t.Errorf("json.Unmarshal() = %+v, %v, want value only", bare, uErr)
+	}
+	for _, data := range []string{`[`, `{"caller":{"line":-1}}`} {
+		if uErr := json.Unmarshal([]byte(data), &bare); uErr == nil {
+			t.Errorf("json.Unmarshal(%s) expected an error, but got nil", data)
+		}
+	}
+	if got := mustMarshal(t, (*PanicError)(nil)); got != "null" {
+		t.Errorf("json.Marshal(nil) = %s, want null", got)
+	}
+
+	attrs := err.LogValue().Group()
+	if len(attrs) != 3 || attrs[0].Value.String() != "boom" || attrs[1].Key != "caller" || attrs[2].Key != "stack" {
+		t.Errorf("LogValue() = %v, want value, caller and stack", attrs)
+	}
+}
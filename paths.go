@@ -0,0 +1,204 @@
+package caller
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// FileMapper rewrites a captured file path, for example to translate a
+// build-time path into the path a developer sees on disk.
+// It must be safe for concurrent use and should return its input
+// unchanged for paths it does not recognize.
+type FileMapper func(file string) string
+
+// fileMapper holds the package-wide FileMapper, or nil if none is set.
+var fileMapper atomic.Pointer[FileMapper]
+
+// SetFileMapper installs m as the package-wide FileMapper applied to the
+// file path of every Caller captured from a live stack frame.
+// Callers decoded from JSON are not affected.
+// Passing nil removes any previously installed mapper.
+func SetFileMapper(m FileMapper) {
+	if m == nil {
+		fileMapper.Store(nil)
+		return
+	}
+	fileMapper.Store(&m)
+}
+
+// mapFile applies the package-wide FileMapper to file, if one is set.
+func mapFile(file string) string {
+	if m := fileMapper.Load(); m != nil && file != "" {
+		return (*m)(file)
+	}
+	return file
+}
+
+// Path profile names accepted by PathProfile.
+const (
+	ProfileBazel         = "bazel"          // Bazel execroot and sandbox paths
+	ProfileDocker        = "docker"         // Conventional Docker build context directories
+	ProfileGitHubActions = "github-actions" // GitHub Actions runner workspaces
+	ProfileGomobile      = "gomobile"       // gomobile work directories and mobile toolchains
+)
+
+// pathProfiles maps profile names to their FileMapper.
+var pathProfiles = map[string]FileMapper{
+	ProfileBazel:         BazelPaths,
+	ProfileDocker:        DockerPaths,
+	ProfileGitHubActions: GitHubActionsPaths,
+	ProfileGomobile:      GomobilePaths,
+}
+
+// PathProfile returns a FileMapper that applies the named canonicalization
+// profiles in order, so that the selection can come straight from
+// configuration:
+//
+//	m, err := caller.PathProfile(strings.Split(os.Getenv("CALLER_PATH_PROFILES"), ",")...)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	caller.SetFileMapper(m)
+//
+// Empty names are ignored. It returns an error for an unknown name.
+func PathProfile(names ...string) (FileMapper, error) {
+	mappers := make([]FileMapper, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		m, ok := pathProfiles[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown path profile: %q", name)
+		}
+		mappers = append(mappers, m)
+	}
+	return ChainFileMappers(mappers...), nil
+}
+
+// ChainFileMappers returns a FileMapper that applies each of mappers in
+// order, feeding the output of one into the next. Nil mappers are skipped.
+func ChainFileMappers(mappers ...FileMapper) FileMapper {
+	return func(file string) string {
+		for _, m := range mappers {
+			if m != nil {
+				file = m(file)
+			}
+		}
+		return file
+	}
+}
+
+// BazelPaths maps paths inside a Bazel execroot, including sandboxed
+// execroots and generated files under bazel-out, to workspace-relative
+// paths. For example,
+// "/home/u/.cache/bazel/_bazel_u/0123/execroot/_main/pkg/server/handler.go"
+// becomes "pkg/server/handler.go".
+func BazelPaths(file string) string {
+	rel, ok := stripThrough(file, "/execroot/", 1)
+	if !ok {
+		return file
+	}
+	// Generated sources live under bazel-out/<config>/bin/
+	if strings.HasPrefix(rel, "bazel-out/") {
+		if gen, ok := dropSegments(rel, 3); ok {
+			return gen
+		}
+	}
+	return rel
+}
+
+// dockerRoots lists the build context directories conventionally used as
+// the WORKDIR of Go images, most specific first.
+var dockerRoots = []string{"/usr/src/app/", "/app/", "/src/", "/build/", "/workspace/"}
+
+// DockerPaths maps paths under conventional Docker build context
+// directories (/app, /src, /build, /workspace, /usr/src/app, and
+// GOPATH-style /go/src/<host>/<owner>/<repo>) to repository-relative paths.
+func DockerPaths(file string) string {
+	if rest, ok := strings.CutPrefix(file, "/go/src/"); ok {
+		if rel, ok := dropSegments(rest, 3); ok {
+			return rel
+		}
+		return file
+	}
+	for _, root := range dockerRoots {
+		if rel, ok := strings.CutPrefix(file, root); ok && rel != "" {
+			return rel
+		}
+	}
+	return file
+}
+
+// GitHubActionsPaths maps paths inside a GitHub Actions runner workspace
+// (<runner>/work/<repo>/<repo>/ on hosted Linux and macOS runners,
+// <drive>:/a/<repo>/<repo>/ on hosted Windows runners, and
+// <runner>/_work/<repo>/<repo>/ on self-hosted runners) to
+// repository-relative paths.
+func GitHubActionsPaths(file string) string {
+	for _, marker := range []string{"/runner/work/", "/_work/"} {
+		if rel, ok := stripThrough(file, marker, 2); ok {
+			return rel
+		}
+	}
+	if len(file) > 5 && file[1] == ':' && file[2:5] == "/a/" {
+		if rel, ok := dropSegments(file[5:], 2); ok {
+			return rel
+		}
+	}
+	return file
+}
+
+// GomobilePaths maps paths produced by gomobile builds for Android and
+// iOS to the paths a developer recognizes: sources under a gomobile
+// work directory (<tmp>/gomobile-work-<id>/src/ or
+// <tmp>/gomobile-work-<id>/src-<target>/) become relative to it, as do
+// the generated gobind bindings kept there, and headers of the Android
+// NDK toolchain (<ndk>/toolchains/llvm/prebuilt/<host>/sysroot/) and of
+// Xcode platform SDKs (<platform>.platform/.../<sdk>.sdk/) become
+// relative to their sysroot, such as "usr/include/stdlib.h".
+func GomobilePaths(file string) string {
+	if rest, ok := stripThrough(file, "/gomobile-work-", 1); ok {
+		if dir, rel, ok := strings.Cut(rest, "/"); ok && strings.HasPrefix(dir, "src") && rel != "" {
+			return rel
+		}
+		return rest
+	}
+	if rel, ok := stripThrough(file, "/toolchains/llvm/prebuilt/", 1); ok {
+		if sys, ok := strings.CutPrefix(rel, "sysroot/"); ok && sys != "" {
+			return sys
+		}
+		return rel
+	}
+	if i := strings.Index(file, ".sdk/"); i >= 0 && strings.Contains(file[:i], ".platform/") {
+		if rel := file[i+len(".sdk/"):]; rel != "" {
+			return rel
+		}
+	}
+	return file
+}
+
+// stripThrough returns the part of file after the first occurrence of
+// marker with a further n leading path segments removed.
+func stripThrough(file, marker string, n int) (string, bool) {
+	i := strings.Index(file, marker)
+	if i < 0 {
+		return "", false
+	}
+	return dropSegments(file[i+len(marker):], n)
+}
+
+// dropSegments removes n leading slash-separated segments from path.
+// It reports false if path does not have anything left afterwards.
+func dropSegments(path string, n int) (string, bool) {
+	for range n {
+		i := strings.IndexByte(path, '/')
+		if i < 0 {
+			return "", false
+		}
+		path = path[i+1:]
+	}
+	return path, path != ""
+}
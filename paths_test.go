@@ -0,0 +1,112 @@
+package caller
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSetFileMapper tests that an installed FileMapper is applied to
+// captured callers and that passing nil removes it again.
+func TestSetFileMapper(t *testing.T) {
+	restoreGlobal(t, &fileMapper)
+
+	SetFileMapper(func(file string) string { return "mapped/" + file[strings.LastIndexByte(file, '/')+1:] })
+
+	if got, want := Immediate().File(), "mapped/paths_test.go"; got != want {
+		t.Errorf("File() with mapper = %q, want %q", got, want)
+	}
+
+	SetFileMapper(nil)
+
+	if got := Immediate().File(); !strings.HasSuffix(got, "/paths_test.go") || strings.HasPrefix(got, "mapped/") {
+		t.Errorf("File() after removing mapper = %q, want the original path", got)
+	}
+}
+
+// TestMapFile tests that mapFile leaves empty paths alone.
+func TestMapFile(t *testing.T) {
+	restoreGlobal(t, &fileMapper)
+
+	SetFileMapper(func(string) string { return "x" })
+	if got := mapFile(""); got != "" {
+		t.Errorf("mapFile(\"\") = %q, want empty string", got)
+	}
+}
+
+// TestPathProfiles tests the built-in path canonicalization profiles.
+func TestPathProfiles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		mapper FileMapper
+		file   string
+		want   string
+	}{
+		{"bazel execroot", BazelPaths, "/home/u/.cache/bazel/_bazel_u/0123/execroot/_main/pkg/server/handler.go", "pkg/server/handler.go"},
+		{"bazel sandbox", BazelPaths, "/tmp/bazel/sandbox/linux-sandbox/7/execroot/ws/cmd/main.go", "cmd/main.go"},
+		{"bazel generated", BazelPaths, "/b/execroot/ws/bazel-out/k8-fastbuild/bin/api/api.pb.go", "api/api.pb.go"},
+		{"bazel outside execroot", BazelPaths, "/home/u/repo/main.go", "/home/u/repo/main.go"},
+		{"bazel execroot only", BazelPaths, "/b/execroot/ws", "/b/execroot/ws"},
+		{"docker app", DockerPaths, "/app/internal/db/db.go", "internal/db/db.go"},
+		{"docker usr src app", DockerPaths, "/usr/src/app/main.go", "main.go"},
+		{"docker gopath", DockerPaths, "/go/src/github.com/user/repo/pkg/x.go", "pkg/x.go"},
+		{"docker gopath too short", DockerPaths, "/go/src/github.com/user/x.go", "/go/src/github.com/user/x.go"},
+		{"docker other", DockerPaths, "/opt/app/main.go", "/opt/app/main.go"},
+		{"actions linux", GitHubActionsPaths, "/home/runner/work/repo/repo/pkg/x.go", "pkg/x.go"},
+		{"actions macos", GitHubActionsPaths, "/Users/runner/work/repo/repo/x.go", "x.go"},
+		{"actions windows", GitHubActionsPaths, "D:/a/repo/repo/pkg/x.go", "pkg/x.go"},
+		{"actions self-hosted", GitHubActionsPaths, "/opt/actions-runner/_work/repo/repo/x.go", "x.go"},
+		{"actions other", GitHubActionsPaths, "/home/dev/repo/x.go", "/home/dev/repo/x.go"},
+		{"gomobile work src", GomobilePaths, "/tmp/gomobile-work-3141592/src/example.com/app/mobile/api.go", "example.com/app/mobile/api.go"},
+		{"gomobile work target", GomobilePaths, "/var/folders/x/T/gomobile-work-27/src-android-arm64/gobind/go_mobilemain.go", "gobind/go_mobilemain.go"},
+		{"gomobile work other", GomobilePaths, "/tmp/gomobile-work-27/gen/main.go", "gen/main.go"},
+		{"gomobile ndk sysroot", GomobilePaths, "/Users/u/Library/Android/sdk/ndk/26.1.10909125/toolchains/llvm/prebuilt/darwin-x86_64/sysroot/usr/include/stdlib.h", "usr/include/stdlib.h"},
+		{"gomobile ndk clang", GomobilePaths, "/opt/ndk/toolchains/llvm/prebuilt/linux-x86_64/lib/clang/17/include/stddef.h", "lib/clang/17/include/stddef.h"},
+		{"gomobile xcode sdk", GomobilePaths, "/Applications/Xcode.app/Contents/Developer/Platforms/iPhoneOS.platform/Developer/SDKs/iPhoneOS17.2.sdk/usr/include/stdlib.h", "usr/include/stdlib.h"},
+		{"gomobile sdk outside platform", GomobilePaths, "/home/dev/cloud.sdk/x.go", "/home/dev/cloud.sdk/x.go"},
+		{"gomobile other", GomobilePaths, "/home/dev/repo/x.go", "/home/dev/repo/x.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.mapper(tt.file); got != tt.want {
+				t.Errorf("mapper(%q) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPathProfile tests selecting and chaining profiles by name.
+func TestPathProfile(t *testing.T) {
+	t.Parallel()
+
+	m, err := PathProfile(ProfileBazel, " ", ProfileGitHubActions)
+	if err != nil {
+		t.Fatalf("PathProfile() error = %v", err)
+	}
+	if got, want := m("/home/runner/work/r/r/x.go"), "x.go"; got != want {
+		t.Errorf("mapper() = %q, want %q", got, want)
+	}
+	if got, want := m("/b/execroot/ws/y.go"), "y.go"; got != want {
+		t.Errorf("mapper() = %q, want %q", got, want)
+	}
+
+	if _, err := PathProfile("nope"); err == nil {
+		t.Error("PathProfile(\"nope\") expected an error, but got nil")
+	}
+}
+
+// TestChainFileMappers tests that mappers run in order and nil entries are skipped.
+func TestChainFileMappers(t *testing.T) {
+	t.Parallel()
+
+	m := ChainFileMappers(
+		func(f string) string { return f + "a" },
+		nil,
+		func(f string) string { return f + "b" },
+	)
+	if got, want := m("x"), "xab"; got != want {
+		t.Errorf("ChainFileMappers() = %q, want %q", got, want)
+	}
+}
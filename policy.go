@@ -0,0 +1,104 @@
+package caller
+
+import (
+	"runtime"
+	"strconv"
+)
+
+// Policy is a set of rules restricting which packages may call which,
+// checked against the current stack by Enforce. It can be declared in
+// code or decoded from a configuration file:
+//
+//	{"rules": [
+//		{"name": "db layering", "callee": "example.com/app/internal/db/...",
+//		 "allow": ["example.com/app/internal/store/..."]},
+//		{"callee": "example.com/app/internal/billing", "deny": ["example.com/app/handlers/..."]}
+//	]}
+//
+// Enforce sees only the stack of the code calling it, so a rule fires
+// only while a function of its callee package is on that stack: the
+// protected packages must call Enforce themselves, typically at their
+// entry points. Calls into packages that do not, such as os/exec, cannot
+// be restricted by a Policy.
+type Policy struct {
+	Rules  []PolicyRule `json:"rules"`
+	Strict bool         `json:"strict,omitempty"` // Whether Enforce panics with violations instead of returning them
+}
+
+// PolicyRule restricts the packages that may call into the packages
+// matching Callee. Patterns use the syntax of MatchPackage.
+type PolicyRule struct {
+	Name   string   `json:"name,omitempty"`  // Name reported in violations
+	Callee string   `json:"callee"`          // Package pattern of the protected code
+	Allow  []string `json:"allow,omitempty"` // If set, only packages matching one of these may call Callee
+	Deny   []string `json:"deny,omitempty"`  // Packages that must not call Callee
+}
+
+// PolicyError reports a call between packages that violates a rule of a
+// Policy.
+type PolicyError struct {
+	Rule   PolicyRule // Violated rule
+	Callee Caller     // Frame in the protected package
+	Caller Caller     // Frame in the calling package
+}
+
+// Error implements the error interface.
+func (e *PolicyError) Error() string {
+	rule := e.Rule.Name
+	if rule == "" {
+		rule = e.Rule.Callee
+	}
+	return "policy " + strconv.Quote(rule) + " violated: " + e.Caller.FullFunction() + " (" + e.Caller.ShortLocation() + ") calls " + e.Callee.FullFunction()
+}
+
+// Unwrap returns ErrDisallowedCaller.
+func (e *PolicyError) Unwrap() error {
+	return ErrDisallowedCaller
+}
+
+// Enforce checks every call between two packages on the stack of the
+// function calling it against the rules of p, and returns a *PolicyError
+// for the innermost violation, or panics with it if p is Strict. Calls
+// within a package are not checked.
+func Enforce(p Policy) error {
+	if len(p.Rules) == 0 {
+		return nil
+	}
+
+	// Skip callers itself and Enforce
+	frames := runtime.CallersFrames(callers(1))
+	var callee *callerInfo
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			c := frameCallerInfo(frame)
+			if callee != nil && c.Package() != callee.Package() {
+				if rule, ok := p.violated(c.Package(), callee.Package()); ok {
+					err := &PolicyError{Rule: rule, Callee: callee, Caller: c}
+					if p.Strict {
+						panic(err) //nolint:forbidigo // a strict policy is an explicit opt-in to panicking
+					}
+					return err
+				}
+			}
+			callee = c
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// violated returns the first rule of p that forbids package caller from
+// calling package callee.
+func (p Policy) violated(caller, callee string) (PolicyRule, bool) {
+	for _, r := range p.Rules {
+		if !matchPackagePattern(r.Callee, callee) {
+			continue
+		}
+		if matchPackagePatterns(r.Deny, caller) || (len(r.Allow) > 0 && !matchPackagePatterns(r.Allow, caller)) {
+			return r, true
+		}
+	}
+	return PolicyRule{}, false
+}
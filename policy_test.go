@@ -0,0 +1,94 @@
+package caller
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// thisPackage is the import path of the package under test.
+const thisPackage = "github.com/balinomad/go-caller/v2"
+
+// enforceHelper calls Enforce from a function of this package, which is
+// called from testing.tRunner.
+func enforceHelper(p Policy) error {
+	return Enforce(p)
+}
+
+// TestEnforce tests which calls between packages on the stack violate a
+// policy.
+func TestEnforce(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		rules    []PolicyRule
+		wantRule string
+	}{
+		{"no rules", nil, ""},
+		{"allowed", []PolicyRule{{Name: "r", Callee: thisPackage, Allow: []string{"testing"}}}, ""},
+		{"not allowed", []PolicyRule{{Name: "r", Callee: thisPackage, Allow: []string{"example.com/..."}}}, "r"},
+		{"denied", []PolicyRule{{Name: "r", Callee: "github.com/balinomad/...", Deny: []string{"testing"}}}, "r"},
+		{"not denied", []PolicyRule{{Callee: thisPackage, Deny: []string{"example.com/app"}}}, ""},
+		{"other callee", []PolicyRule{{Callee: "example.com/app", Allow: []string{"example.com/app/..."}}}, ""},
+		{"outer call", []PolicyRule{{Name: "outer", Callee: "testing", Deny: []string{"runtime"}}}, "outer"},
+		{"first rule wins", []PolicyRule{{Name: "a", Callee: thisPackage, Deny: []string{"testing"}}, {Name: "b", Callee: thisPackage, Deny: []string{"testing"}}}, "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := enforceHelper(Policy{Rules: tt.rules})
+			if tt.wantRule == "" {
+				if err != nil {
+					t.Errorf("Enforce() error = %v, want nil", err)
+				}
+				return
+			}
+			var pe *PolicyError
+			if !errors.As(err, &pe) || !errors.Is(err, ErrDisallowedCaller) {
+				t.Fatalf("Enforce() error = %v, want a *PolicyError", err)
+			}
+			if pe.Rule.Name != tt.wantRule {
+				t.Errorf("Rule.Name = %q, want %q", pe.Rule.Name, tt.wantRule)
+			}
+			if pe.Callee.Package() == pe.Caller.Package() {
+				t.Errorf("violation within package %q, want a call between packages", pe.Callee.Package())
+			}
+		})
+	}
+}
+
+// TestPolicy_JSON tests loading a policy from a configuration file.
+func TestPolicy_JSON(t *testing.T) {
+	t.Parallel()
+
+	var p Policy
+	data := `{"rules": [{"name": "tests only", "callee": "github.com/balinomad/...", "allow": ["example.com/app/..."]}]}`
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	err := enforceHelper(p)
+	var pe *PolicyError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Enforce() error = %v, want a *PolicyError", err)
+	}
+	want := `policy "tests only" violated: testing.tRunner (`
+	if msg := pe.Error(); !strings.HasPrefix(msg, want) || !strings.HasSuffix(msg, ".TestPolicy_JSON") {
+		t.Errorf("Error() = %q, want %q... calls TestPolicy_JSON", msg, want)
+	}
+}
+
+// TestEnforce_Strict tests that violations of a strict policy panic.
+func TestEnforce_Strict(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		r := recover()
+		if _, ok := r.(*PolicyError); !ok {
+			t.Errorf("recover() = %v, want a *PolicyError", r)
+		}
+	}()
+	_ = enforceHelper(Policy{Rules: []PolicyRule{{Callee: thisPackage, Deny: []string{"testing"}}}, Strict: true})
+	t.Error("Enforce() of a strict policy should have panicked")
+}
@@ -0,0 +1,121 @@
+package caller
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"maps"
+	"sync"
+)
+
+// ErrEmptyKey is returned by NewPseudonymizer for an empty key, which
+// would let anyone recompute tokens for known inputs.
+var ErrEmptyKey = errors.New("empty pseudonymization key")
+
+// tokenEncoding renders pseudonym tokens. Lower-case base32 without
+// padding contains neither dots nor slashes, so tokens never disturb
+// package/function splitting.
+var tokenEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// tokenBytes is the number of HMAC bytes kept in a token (16 characters).
+const tokenBytes = 10
+
+// Pseudonymizer replaces file paths and function names with stable,
+// opaque tokens derived from a secret key with HMAC-SHA256, so callers
+// can be exported to untrusted sinks without revealing source layout.
+//
+// The same input always yields the same token for a given key, so
+// pseudonymized callers can still be grouped and correlated. Each
+// Pseudonymizer also keeps a local table of the tokens it has issued,
+// which lets the key holder reverse them with Reveal.
+//
+// A Pseudonymizer must be created with NewPseudonymizer; the zero value
+// has no key and panics when used. A Pseudonymizer is safe for
+// concurrent use.
+type Pseudonymizer struct {
+	key []byte
+
+	mu    sync.RWMutex
+	table map[string]string // Token -> original value
+}
+
+// NewPseudonymizer returns a Pseudonymizer using key as the HMAC secret.
+// The key is copied. Anyone holding the key can recompute tokens for
+// known inputs, so it must be kept as secret as the data it protects.
+// It returns ErrEmptyKey if key is empty.
+func NewPseudonymizer(key []byte) (*Pseudonymizer, error) {
+	if len(key) == 0 {
+		return nil, ErrEmptyKey
+	}
+	return &Pseudonymizer{
+		key:   append([]byte(nil), key...),
+		table: make(map[string]string),
+	}, nil
+}
+
+// Pseudonymize returns a copy of c whose file, package and function name
+// are replaced by tokens. The line number is kept as is.
+// It returns nil if c is nil.
+func (p *Pseudonymizer) Pseudonymize(c Caller) Caller {
+	if isNil(c) {
+		return nil
+	}
+
+	var fn string
+	switch pkg, name := c.Package(), c.Function(); {
+	case pkg != "" && name != "":
+		fn = p.Token(pkg) + "." + p.Token(name)
+	case pkg != "":
+		fn = p.Token(pkg) + "."
+	default:
+		fn = p.Token(c.FullFunction())
+	}
+
+	return &callerInfo{
+		file:   p.Token(c.File()),
+		line:   c.Line(),
+		fn:     fn,
+		dotIdx: functionNameIndex(fn),
+		flags:  flagOwnsStrings,
+	}
+}
+
+// Token returns the stable token for s and records it in the local
+// mapping table. The empty string maps to itself.
+func (p *Pseudonymizer) Token(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(s))
+	token := tokenEncoding.EncodeToString(mac.Sum(nil)[:tokenBytes])
+
+	p.mu.RLock()
+	_, known := p.table[token]
+	p.mu.RUnlock()
+	if !known {
+		p.mu.Lock()
+		p.table[token] = s
+		p.mu.Unlock()
+	}
+	return token
+}
+
+// Reveal returns the original value for a token previously issued by this
+// Pseudonymizer. It reports false for unknown tokens.
+func (p *Pseudonymizer) Reveal(token string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	s, ok := p.table[token]
+	return s, ok
+}
+
+// Mapping returns a copy of the local token table, keyed by token.
+// It can be persisted to reverse exported data later.
+func (p *Pseudonymizer) Mapping() map[string]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return maps.Clone(p.table)
+}
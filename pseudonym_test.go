@@ -0,0 +1,110 @@
+package caller
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// mustPseudonymizer returns a Pseudonymizer using key.
+func mustPseudonymizer(t *testing.T, key string) *Pseudonymizer {
+	t.Helper()
+	p, err := NewPseudonymizer([]byte(key))
+	if err != nil {
+		t.Fatalf("NewPseudonymizer() error = %v", err)
+	}
+	return p
+}
+
+// TestNewPseudonymizer_EmptyKey tests that an empty key is rejected.
+func TestNewPseudonymizer_EmptyKey(t *testing.T) {
+	t.Parallel()
+
+	for _, key := range [][]byte{nil, {}} {
+		if p, err := NewPseudonymizer(key); p != nil || !errors.Is(err, ErrEmptyKey) {
+			t.Errorf("NewPseudonymizer(%q) = %v, %v, want %v", key, p, err, ErrEmptyKey)
+		}
+	}
+}
+
+// TestPseudonymizer_Pseudonymize tests that pseudonymized callers hide
+// their source details, keep their line, and can be revealed again.
+func TestPseudonymizer_Pseudonymize(t *testing.T) {
+	t.Parallel()
+
+	p := mustPseudonymizer(t, "secret")
+	c := &callerInfo{file: "/src/app/db.go", line: 42, fn: "example.com/app.(*DB).Query", dotIdx: functionNameIndex("example.com/app.(*DB).Query")}
+
+	got := p.Pseudonymize(c)
+	if got.Line() != 42 {
+		t.Errorf("Line() = %d, want 42", got.Line())
+	}
+	for _, s := range []string{got.File(), got.Package(), got.Function()} {
+		if s == "" || strings.ContainsAny(s, "/.()*") {
+			t.Errorf("token %q is empty or leaks structure", s)
+		}
+	}
+	if strings.Contains(got.FullFunction(), "DB") {
+		t.Errorf("FullFunction() = %q leaks the original name", got.FullFunction())
+	}
+
+	for token, want := range map[string]string{
+		got.File():     "/src/app/db.go",
+		got.Package():  "example.com/app",
+		got.Function(): "(*DB).Query",
+	} {
+		if orig, ok := p.Reveal(token); !ok || orig != want {
+			t.Errorf("Reveal(%q) = %q, %v, want %q, true", token, orig, ok, want)
+		}
+	}
+	if got, want := len(p.Mapping()), 3; got != want {
+		t.Errorf("len(Mapping()) = %d, want %d", got, want)
+	}
+
+	if !p.Pseudonymize(c).Equal(got) {
+		t.Error("Pseudonymize() should be deterministic")
+	}
+	if mustPseudonymizer(t, "other").Pseudonymize(c).Equal(got) {
+		t.Error("Pseudonymize() with a different key should produce different tokens")
+	}
+}
+
+// TestPseudonymizer_PartialCallers tests callers missing parts of their
+// function name, and nil callers.
+func TestPseudonymizer_PartialCallers(t *testing.T) {
+	t.Parallel()
+
+	p := mustPseudonymizer(t, "k")
+	tests := []struct {
+		name        string
+		c           Caller
+		wantPackage bool
+		wantFunc    bool
+	}{
+		{"no package", &callerInfo{file: "a.go", fn: "main", dotIdx: -1}, false, false},
+		{"no function name", &callerInfo{file: "a.go", fn: "pkg.", dotIdx: 3}, true, false},
+		{"no function", &callerInfo{file: "a.go", dotIdx: -1}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := p.Pseudonymize(tt.c)
+			if (got.Package() != "") != tt.wantPackage {
+				t.Errorf("Package() = %q, want non-empty %v", got.Package(), tt.wantPackage)
+			}
+			if (got.Function() != "") != tt.wantFunc {
+				t.Errorf("Function() = %q, want non-empty %v", got.Function(), tt.wantFunc)
+			}
+			if got.File() == "a.go" {
+				t.Error("File() was not pseudonymized")
+			}
+		})
+	}
+
+	if p.Pseudonymize(nil) != nil {
+		t.Error("Pseudonymize(nil) should return nil")
+	}
+	if _, ok := p.Reveal("unknown"); ok {
+		t.Error("Reveal() of an unknown token should report false")
+	}
+}
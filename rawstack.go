@@ -0,0 +1,70 @@
+package caller
+
+import "runtime"
+
+// rawStackDepth is the number of frames a RawStack holds.
+const rawStackDepth = 64
+
+// RawStack is a fixed-size buffer for the return addresses of up to 64
+// callers, for capturing stacks in constrained contexts, such as signal
+// handling goroutines, finalizers, or code running with a lock held in an
+// allocator or logger, where allocating or touching shared maps could
+// deadlock or re-enter the code being diagnosed. Capturing into a
+// RawStack performs no heap allocation, map access or locking, and runs
+// no Recorder, capture hooks or FileMapper; resolving it into file, line
+// and function information is deferred to Stack, which can run later,
+// outside the constrained context:
+//
+//	var raw caller.RawStack // preallocated, e.g. per worker
+//	...
+//	raw.Capture(0)
+//	...
+//	report(raw.Stack())
+//
+// The zero RawStack is empty. A RawStack must not be used concurrently.
+type RawStack struct {
+	pcs [rawStackDepth]uintptr
+	n   int
+}
+
+// Capture replaces the contents of r with the stack of the calling
+// goroutine, with the same skip semantics as New, and returns the number
+// of frames captured. Frames inlined by the compiler take an entry each,
+// as they do in a Stack, and frames beyond the 64th are dropped. A
+// negative skip empties r.
+func (r *RawStack) Capture(skip int) int {
+	r.n = 0
+	if skip < 0 {
+		return 0
+	}
+	// Skip runtime.Callers, Capture, and the function calling Capture
+	r.n = runtime.Callers(skip+skipAdjust+1, r.pcs[:])
+	return r.n
+}
+
+// Len returns the number of return addresses captured.
+func (r *RawStack) Len() int {
+	return r.n
+}
+
+// PCs returns the captured return addresses, as filled by
+// runtime.Callers. The slice refers to the storage of r and is only
+// valid until the next Capture.
+func (r *RawStack) PCs() []uintptr {
+	return r.pcs[:r.n]
+}
+
+// Stack resolves the captured return addresses into a Stack configured
+// by opts, as NewStack would have captured them. Unlike Capture, it
+// allocates and must not be called from the constrained context itself.
+// It returns nil if no frames remain.
+func (r *RawStack) Stack(opts ...Option) *Stack {
+	if r.n == 0 {
+		return nil
+	}
+	s := stackFromPCs(r.PCs(), newCaptureConfig(opts))
+	if s.Len() == 0 {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,59 @@
+package caller
+
+import (
+	"runtime"
+	"testing"
+)
+
+// rawHelper captures into r a stack whose first frame is its caller.
+func rawHelper(r *RawStack, skip int) int {
+	return r.Capture(skip)
+}
+
+// TestRawStack tests that a resolved RawStack matches NewStack.
+func TestRawStack(t *testing.T) {
+	t.Parallel()
+
+	var r RawStack
+	want := stackHelper(0, KeepAllFrames())
+	n := rawHelper(&r, 0)
+	_, file, line, _ := runtime.Caller(0)
+
+	if n == 0 || n != r.Len() || len(r.PCs()) != n {
+		t.Fatalf("Capture(0) = %d, Len() = %d, len(PCs()) = %d, want equal and positive", n, r.Len(), len(r.PCs()))
+	}
+	s := r.Stack(KeepAllFrames())
+	if got := s.Caller0(); got.File() != file || got.Line() != line-1 {
+		t.Errorf("Stack().Caller0() = %v, want %s:%d", got, file, line-1)
+	}
+	if s.Len() != want.Len() {
+		t.Errorf("Stack().Len() = %d, want %d", s.Len(), want.Len())
+	}
+	for i := 1; i < s.Len(); i++ {
+		if !s.Frame(i).Equal(want.Frame(i)) {
+			t.Errorf("Stack().Frame(%d) = %v, want %v", i, s.Frame(i), want.Frame(i))
+		}
+	}
+	if got := r.Stack(); got.Len() != 1 {
+		t.Errorf("Stack().Len() = %d, want 1 without noise frames", got.Len())
+	}
+
+	rawHelper(&r, 1)
+	if got := r.Stack(); got != nil {
+		t.Errorf("Stack() with only noise frames = %v, want nil", got)
+	}
+	for _, skip := range []int{-1, 10000} {
+		if got := rawHelper(&r, skip); got != 0 || r.Len() != 0 || r.Stack() != nil {
+			t.Errorf("Capture(%d) = %d, want an empty RawStack", skip, got)
+		}
+	}
+}
+
+// TestRawStack_Allocs tests that capturing into a RawStack does not
+// allocate.
+func TestRawStack_Allocs(t *testing.T) {
+	var r RawStack
+	if allocs := testing.AllocsPerRun(100, func() { r.Capture(0) }); allocs != 0 {
+		t.Errorf("Capture() allocated %v times per run, want 0", allocs)
+	}
+}
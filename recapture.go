@@ -0,0 +1,108 @@
+package caller
+
+import (
+	"runtime"
+	"slices"
+)
+
+// Recapturable is implemented by callers that can shift their attribution
+// to another frame of the stack they were captured from. Every Caller
+// returned by this package except Invalid() implements it; the stack is
+// only kept when requested with WithRecapture.
+//
+//	c := caller.NewWith(0, caller.WithRecapture())
+//	// ... later, on finding that c is an internal wrapper
+//	if r, ok := c.(caller.Recapturable); ok {
+//		c = r.Recapture(1)
+//	}
+type Recapturable interface {
+	// Recapture returns the frame delta frames above the caller in the
+	// stack it was captured from, or below it for a negative delta.
+	// It returns nil if the stack was not kept or has no such frame.
+	Recapture(delta int) Caller
+}
+
+// callerInfo implements the Recapturable interface.
+var _ Recapturable = (*callerInfo)(nil)
+
+// recaptureContext is the stack a caller was captured from, shared by
+// the callers recaptured from it.
+type recaptureContext struct {
+	pcs []uintptr // Return addresses, starting at the function that called the capturing function
+	idx int       // Index of the caller among the frames resolved from pcs
+}
+
+// Recapture returns the frame delta frames above c in the stack it was
+// captured from, as if the capture had been made with a skip larger by
+// delta, or nil if the stack was not kept or has no such frame. Frames
+// passed over by SkipFrames at capture count like any other. A negative
+// delta moves inwards, at most to the function that made the capture.
+// The result keeps the stack and the capture time of c.
+func (c *callerInfo) Recapture(delta int) Caller {
+	if c == nil {
+		return nil
+	}
+	recap := c.recapture()
+	if recap == nil {
+		return nil
+	}
+	i := recap.idx + delta
+	if i < 0 {
+		return nil
+	}
+	var found *callerInfo
+	forEachFrame(recap.pcs, func(j int, f *callerInfo) bool {
+		if j < i {
+			return true
+		}
+		found = f
+		return false
+	})
+	if found == nil {
+		return nil
+	}
+	found.extras().at = c.capturedAt()
+	found.extras().recap = &recaptureContext{pcs: recap.pcs, idx: i}
+	return found
+}
+
+// findRecapturable returns the first frame of pcs from index start on
+// that cfg does not skip, keeping pcs for Recapture, or nil if there is
+// none.
+func findRecapturable(pcs []uintptr, start int, cfg captureConfig) *callerInfo {
+	var found *callerInfo
+	at := cfg.now()
+	pcs = slices.Clip(pcs)
+	forEachFrame(pcs, func(i int, c *callerInfo) bool {
+		if i < start {
+			return true
+		}
+		cfg.decorate(c)
+		if c.hidden() || matchAny(cfg.skip, c) {
+			return true
+		}
+		c.extras().at = at
+		c.extras().recap = &recaptureContext{pcs: pcs, idx: i}
+		found = c
+		return false
+	})
+	return found
+}
+
+// forEachFrame calls yield with the index and information of each frame
+// resolved from pcs, innermost first, until yield returns false.
+func forEachFrame(pcs []uintptr, yield func(int, *callerInfo) bool) {
+	frames := runtime.CallersFrames(pcs)
+	for i := 0; ; {
+		frame, more := frames.Next()
+		if frame.File != "" || frame.Function != "" {
+			if !yield(i, frameCallerInfo(frame)) {
+				return
+			}
+			i++
+		}
+		if !more {
+			return
+		}
+	}
+}
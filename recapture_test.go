@@ -0,0 +1,91 @@
+package caller
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// recaptureWrapper captures its caller, as a logging wrapper would.
+func recaptureWrapper(opts ...Option) Caller {
+	return NewWith(0, opts...)
+}
+
+// recaptureOuter calls recaptureWrapper through one more wrapper.
+func recaptureOuter(opts ...Option) Caller {
+	return recaptureWrapper(opts...)
+}
+
+// recaptureOf returns c recaptured by delta, or nil if c does not
+// implement Recapturable.
+func recaptureOf(c Caller, delta int) Caller {
+	r, ok := c.(Recapturable)
+	if !ok {
+		return nil
+	}
+	return r.Recapture(delta)
+}
+
+// capturedAt returns the capture time of c, or the zero time if c does
+// not implement Timestamped.
+func capturedAt(c Caller) time.Time {
+	ts, ok := c.(Timestamped)
+	if !ok {
+		return time.Time{}
+	}
+	return ts.CapturedAt()
+}
+
+// TestRecapture tests shifting a capture to frames above and below it.
+func TestRecapture(t *testing.T) {
+	t.Parallel()
+
+	c := recaptureOuter(WithRecapture(), WithTimestamp())
+	_, file, line, _ := runtime.Caller(0)
+	if got := c.Function(); got != "recaptureOuter" {
+		t.Fatalf("NewWith(0).Function() = %q, want recaptureOuter", got)
+	}
+	up := recaptureOf(c, 1)
+	if up.Function() != "TestRecapture" || up.File() != file || up.Line() != line-1 {
+		t.Errorf("Recapture(1) = %v, want TestRecapture at %s:%d", up, file, line-1)
+	}
+	if at := capturedAt(up); at.IsZero() || !at.Equal(capturedAt(c)) {
+		t.Error("Recapture(1) did not keep the capture time")
+	}
+
+	tests := []struct {
+		name  string
+		c     Caller
+		delta int
+		want  string
+	}{
+		{"zero", c, 0, "recaptureOuter"},
+		{"inwards", c, -1, "recaptureWrapper"},
+		{"chained", up, -1, "recaptureOuter"},
+		{"chained twice", recaptureOf(up, -1), -1, "recaptureWrapper"},
+		{"after skipped frames", recaptureOuter(WithRecapture(), SkipFrames(MatchFunctionSuffix(`\.recaptureOuter`))), -1, "recaptureOuter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := recaptureOf(tt.c, tt.delta); got.Function() != tt.want {
+				t.Errorf("Recapture(%d) = %v, want %s", tt.delta, got, tt.want)
+			}
+		})
+	}
+
+	for name, got := range map[string]Caller{
+		"below the capture": recaptureOf(c, -2),
+		"beyond the stack":  recaptureOf(c, 10000),
+		"not kept":          recaptureOf(recaptureOuter(), 1),
+		"nil callerInfo":    (*callerInfo)(nil).Recapture(0),
+		"empty":             recaptureOf(NewEmpty(), 0),
+	} {
+		if got != nil {
+			t.Errorf("%s: Recapture() = %v, want nil", name, got)
+		}
+	}
+	if got := NewWith(10000, WithRecapture()); got != nil {
+		t.Errorf("NewWith(10000, WithRecapture()) = %v, want nil", got)
+	}
+}
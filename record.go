@@ -0,0 +1,42 @@
+package caller
+
+// FlatRecord is a Caller flattened into primitive fields, for generic
+// sinks such as BigQuery rows or audit events that cannot take nested
+// objects. Its field names and JSON keys match the Caller JSON encoding.
+type FlatRecord struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+	Package  string `json:"package"`
+}
+
+// Flatten returns the FlatRecord of c. A nil c yields the zero record.
+func Flatten(c Caller) FlatRecord {
+	return FlatRecord{
+		File:     File(c),
+		Line:     Line(c),
+		Function: Function(c),
+		Package:  Package(c),
+	}
+}
+
+// Map returns the record as a map from key to value, with every key
+// prefixed by prefix. Every key is present, even for empty values, so
+// that rows share a fixed schema:
+//
+//	caller.Flatten(c).Map("caller_")
+//	// map[caller_file:main.go caller_function:main caller_line:10 caller_package:main]
+func (r FlatRecord) Map(prefix string) map[string]any {
+	return map[string]any{
+		prefix + "file":     r.File,
+		prefix + "line":     r.Line,
+		prefix + "function": r.Function,
+		prefix + "package":  r.Package,
+	}
+}
+
+// ToRecord flattens c into a map of primitive values with keys prefixed
+// by prefix, as Flatten(c).Map(prefix).
+func ToRecord(c Caller, prefix string) map[string]any {
+	return Flatten(c).Map(prefix)
+}
@@ -0,0 +1,55 @@
+package caller
+
+import (
+	"encoding/json"
+	"maps"
+	"testing"
+)
+
+// TestToRecord tests flattening callers, including nil ones, with and
+// without a key prefix.
+func TestToRecord(t *testing.T) {
+	t.Parallel()
+
+	c := &callerInfo{file: "/src/app/main.go", line: 42, fn: "example.com/app.Run", dotIdx: 15}
+
+	tests := []struct {
+		name   string
+		c      Caller
+		prefix string
+		want   map[string]any
+	}{
+		{
+			name: "no prefix",
+			c:    c,
+			want: map[string]any{"file": "/src/app/main.go", "line": 42, "function": "Run", "package": "example.com/app"},
+		},
+		{
+			name:   "prefix",
+			c:      c,
+			prefix: "caller_",
+			want:   map[string]any{"caller_file": "/src/app/main.go", "caller_line": 42, "caller_function": "Run", "caller_package": "example.com/app"},
+		},
+		{
+			name:   "nil",
+			prefix: "c.",
+			want:   map[string]any{"c.file": "", "c.line": 0, "c.function": "", "c.package": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := ToRecord(tt.c, tt.prefix); !maps.Equal(got, tt.want) {
+				t.Errorf("ToRecord() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	b, err := json.Marshal(Flatten(c))
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if got, want := string(b), mustMarshal(t, c); got != want {
+		t.Errorf("json.Marshal(Flatten()) = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,159 @@
+package caller
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultRecorderSize is the capacity used by NewRecorder for a
+// non-positive size.
+const DefaultRecorderSize = 1000
+
+// Entry is a single capture kept by a Recorder.
+type Entry struct {
+	Caller Caller    // Captured caller
+	Time   time.Time // Time of the capture
+	Label  string    // Optional label supplied with the capture
+	Stack  *Stack    // Stack of the capture, if any, as recorded by StartSampler
+}
+
+// Recorder keeps the most recent captures in a fixed-size ring buffer,
+// so that a process can be asked which call sites it captured last when
+// something goes wrong. Once full, each new entry overwrites the oldest.
+//
+// The zero Recorder is ready to use and holds DefaultRecorderSize
+// entries. A Recorder is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int  // Index of the slot the next entry is written to
+	full    bool // Whether the buffer has wrapped around
+}
+
+// ErrorLabel labels the entries recorded for the errors created by
+// Annotate and Errorf, which the handler of package callerhttp lists as
+// error locations.
+const ErrorLabel = "error"
+
+// recorder holds the package-wide Recorder, or nil if none is set.
+var recorder atomic.Pointer[Recorder]
+
+// NewRecorder returns a Recorder holding up to size entries.
+// A non-positive size selects DefaultRecorderSize.
+func NewRecorder(size int) *Recorder {
+	if size <= 0 {
+		size = DefaultRecorderSize
+	}
+	return &Recorder{entries: make([]Entry, size)}
+}
+
+// SetRecorder installs r as the package-wide Recorder, which records every
+// Caller captured by New, Immediate and NewFromPC without a label.
+// Passing nil stops recording.
+func SetRecorder(r *Recorder) {
+	recorder.Store(r)
+}
+
+// CurrentRecorder returns the package-wide Recorder installed with
+// SetRecorder, or nil if none is set.
+func CurrentRecorder() *Recorder {
+	return recorder.Load()
+}
+
+// Record adds c to the buffer with the current time and label.
+// Nil callers are ignored.
+func (r *Recorder) Record(c Caller, label string) {
+	if isNil(c) {
+		return
+	}
+	r.add(Entry{Caller: c, Time: time.Now(), Label: label})
+}
+
+// Capture returns a Caller for the immediate caller of Capture, and
+// records it with label. If r is not the package-wide Recorder, the
+// capture is recorded there as well.
+// It returns nil if the caller cannot be determined.
+func (r *Recorder) Capture(label string) Caller {
+	pc, file, line, ok := runtime.Caller(1)
+	if !ok {
+		return nil
+	}
+
+	var fullFunc string
+	if f := runtime.FuncForPC(pc); f != nil {
+		fullFunc = f.Name()
+	}
+
+	c := captured(newCallerInfo(file, line, fullFunc), label)
+	if recorder.Load() != r {
+		r.Record(c, label)
+	}
+	return c
+}
+
+// Snapshot returns a copy of the recorded entries, oldest first.
+func (r *Recorder) Snapshot() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]Entry(nil), r.entries[:r.next]...)
+	}
+	out := make([]Entry, 0, len(r.entries))
+	out = append(out, r.entries[r.next:]...)
+	return append(out, r.entries[:r.next]...)
+}
+
+// Len returns the number of entries currently held.
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.full {
+		return len(r.entries)
+	}
+	return r.next
+}
+
+// Cap returns the maximum number of entries the Recorder holds.
+func (r *Recorder) Cap() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.entries == nil {
+		return DefaultRecorderSize
+	}
+	return len(r.entries)
+}
+
+// Reset discards all recorded entries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.entries)
+	r.next, r.full = 0, false
+}
+
+// add writes e into the next slot, overwriting the oldest entry when full.
+func (r *Recorder) add(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.entries == nil {
+		r.entries = make([]Entry, DefaultRecorderSize)
+	}
+	r.entries[r.next] = e
+	r.next++
+	if r.next == len(r.entries) {
+		r.next, r.full = 0, true
+	}
+}
+
+// captured passes a freshly captured caller to the package-wide
+// Recorder and capture hooks, and returns it as a Caller.
+func captured(c *callerInfo, label string) Caller {
+	if r := recorder.Load(); r != nil {
+		r.add(Entry{Caller: c, Time: time.Now(), Label: label})
+	}
+	runHooks(c)
+	return c
+}
@@ -0,0 +1,134 @@
+package caller
+
+import (
+	"strconv"
+	"testing"
+)
+
+// TestRecorder_RingBuffer tests that a Recorder keeps the most recent
+// entries in order once it wraps around.
+func TestRecorder_RingBuffer(t *testing.T) {
+	t.Parallel()
+
+	r := NewRecorder(3)
+	if got, want := r.Cap(), 3; got != want {
+		t.Errorf("Cap() = %d, want %d", got, want)
+	}
+	if got := len(r.Snapshot()); got != 0 {
+		t.Errorf("len(Snapshot()) of an empty recorder = %d, want 0", got)
+	}
+
+	for i := 1; i <= 5; i++ {
+		r.Record(&callerInfo{file: "f.go", line: i}, strconv.Itoa(i))
+	}
+	r.Record(nil, "ignored")
+
+	if got, want := r.Len(), 3; got != want {
+		t.Errorf("Len() = %d, want %d", got, want)
+	}
+	snap := r.Snapshot()
+	for i, want := range []int{3, 4, 5} {
+		if got := snap[i].Caller.Line(); got != want {
+			t.Errorf("Snapshot()[%d].Caller.Line() = %d, want %d", i, got, want)
+		}
+		if got := snap[i].Label; got != strconv.Itoa(want) {
+			t.Errorf("Snapshot()[%d].Label = %q, want %q", i, got, strconv.Itoa(want))
+		}
+		if snap[i].Time.IsZero() {
+			t.Errorf("Snapshot()[%d].Time is zero", i)
+		}
+	}
+
+	r.Reset()
+	if got := r.Len(); got != 0 {
+		t.Errorf("Len() after Reset() = %d, want 0", got)
+	}
+}
+
+// TestRecorder_PartialFill tests Snapshot before the buffer wraps.
+func TestRecorder_PartialFill(t *testing.T) {
+	t.Parallel()
+
+	r := NewRecorder(0)
+	if got, want := r.Cap(), DefaultRecorderSize; got != want {
+		t.Errorf("Cap() = %d, want %d", got, want)
+	}
+	r.Record(&callerInfo{file: "a.go"}, "")
+	r.Record(&callerInfo{file: "b.go"}, "")
+	snap := r.Snapshot()
+	if len(snap) != 2 || snap[0].Caller.File() != "a.go" || snap[1].Caller.File() != "b.go" {
+		t.Errorf("Snapshot() = %v, want entries for a.go and b.go", snap)
+	}
+}
+
+// TestRecorder_Zero tests that the zero Recorder records with the default
+// capacity.
+func TestRecorder_Zero(t *testing.T) {
+	t.Parallel()
+
+	var r Recorder
+	if got, want := r.Cap(), DefaultRecorderSize; got != want {
+		t.Errorf("Cap() = %d, want %d", got, want)
+	}
+	if got := r.Snapshot(); len(got) != 0 {
+		t.Errorf("Snapshot() = %v, want empty", got)
+	}
+	r.Record(&callerInfo{file: "a.go", line: 1}, "first")
+	if snap := r.Snapshot(); len(snap) != 1 || snap[0].Label != "first" || r.Cap() != DefaultRecorderSize {
+		t.Errorf("Snapshot() = %v, Cap() = %d, want one entry of %d", snap, r.Cap(), DefaultRecorderSize)
+	}
+}
+
+// TestRecorder_Capture tests that Capture returns the immediate caller and
+// records it with its label.
+func TestRecorder_Capture(t *testing.T) {
+	t.Parallel()
+
+	r := NewRecorder(4)
+	c := r.Capture("boot")
+	if got, want := c.Function(), "TestRecorder_Capture"; got != want {
+		t.Errorf("Function() = %q, want %q", got, want)
+	}
+	snap := r.Snapshot()
+	if len(snap) != 1 || !snap[0].Caller.Equal(c) || snap[0].Label != "boot" {
+		t.Errorf("Snapshot() = %v, want one entry for %v labeled boot", snap, c)
+	}
+}
+
+// TestSetRecorder tests that the package-wide Recorder sees captures made
+// through the constructors, and records labeled captures only once.
+func TestSetRecorder(t *testing.T) {
+	restoreGlobal(t, &recorder)
+
+	r := NewRecorder(8)
+	SetRecorder(r)
+
+	c := Immediate()
+	r.Capture("labeled")
+	SetRecorder(nil)
+	Immediate()
+
+	snap := r.Snapshot()
+	if got, want := len(snap), 2; got != want {
+		t.Fatalf("len(Snapshot()) = %d, want %d", got, want)
+	}
+	if !snap[0].Caller.Equal(c) || snap[0].Label != "" {
+		t.Errorf("Snapshot()[0] = %v, want unlabeled %v", snap[0], c)
+	}
+	if snap[1].Label != "labeled" {
+		t.Errorf("Snapshot()[1].Label = %q, want %q", snap[1].Label, "labeled")
+	}
+}
+
+// TestErrorLabel tests that errors are recorded under ErrorLabel.
+func TestErrorLabel(t *testing.T) {
+	restoreGlobal(t, &recorder)
+	r := NewRecorder(4)
+	SetRecorder(r)
+
+	err := Errorf("failed")
+	snap := r.Snapshot()
+	if len(snap) != 1 || snap[0].Label != ErrorLabel || !snap[0].Caller.Equal(CallerFromError(err)) {
+		t.Errorf("Snapshot() = %v, want the location of the error under %q", snap, ErrorLabel)
+	}
+}
@@ -0,0 +1,204 @@
+package caller
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxReportDump bounds the size of the all-goroutine traceback read for a
+// DiagnosticReport.
+const maxReportDump = 64 << 20
+
+// DiagnosticReport bundles what a bug report needs to describe the state
+// of a process: the stack of the reporting goroutine and optionally of
+// all goroutines, the build, deployment and process metadata, and
+// metadata of the application's own. It encodes to JSON with
+// encoding/json, and renders as text with WriteText, for attaching to
+// issues or support tickets:
+//
+//	r := caller.NewDiagnosticReport(0, caller.ReportAllGoroutines(),
+//		caller.ReportMetadata("request_id", id))
+//	r.WriteText(os.Stderr)
+type DiagnosticReport struct {
+	Time       time.Time         `json:"time"`                 // Time the report was created
+	Stack      *Stack            `json:"stack,omitempty"`      // Stack of the goroutine that created the report
+	Goroutines []*Goroutine      `json:"goroutines,omitempty"` // Every goroutine, if requested
+	Build      BuildInfo         `json:"build"`                // Toolchain, platform and main module
+	BuildID    string            `json:"build_id,omitempty"`   // Build ID given with ReportBuildID
+	Deployment *Deployment       `json:"deployment,omitempty"` // Metadata set with SetDeployment, if any
+	Process    ProcessInfo       `json:"process"`              // Process and runtime state
+	Metadata   map[string]string `json:"metadata,omitempty"`   // Application metadata
+}
+
+// ProcessInfo describes the process and Go runtime a DiagnosticReport
+// was created in.
+type ProcessInfo struct {
+	PID          int    `json:"pid"`                // Process ID
+	Hostname     string `json:"hostname,omitempty"` // Host name, if known
+	NumCPU       int    `json:"num_cpu"`            // Number of logical CPUs
+	GOMAXPROCS   int    `json:"gomaxprocs"`         // Value of GOMAXPROCS
+	NumGoroutine int    `json:"num_goroutine"`      // Number of goroutines
+}
+
+// ReportOption configures a DiagnosticReport made with
+// NewDiagnosticReport.
+type ReportOption func(*reportConfig)
+
+// reportConfig holds the settings applied by ReportOption values.
+type reportConfig struct {
+	allGoroutines bool              // Whether to include every goroutine
+	metadata      map[string]string // Application metadata
+	buildID       string            // Build ID of the executable
+}
+
+// ReportAllGoroutines makes a report include the traceback of every
+// goroutine, as a SIGQUIT dump would, which stops the world while it is
+// taken.
+func ReportAllGoroutines() ReportOption {
+	return func(cfg *reportConfig) {
+		cfg.allGoroutines = true
+	}
+}
+
+// ReportMetadata adds the key and value to the metadata of a report,
+// replacing any earlier value of the key.
+func ReportMetadata(key, value string) ReportOption {
+	return func(cfg *reportConfig) {
+		if cfg.metadata == nil {
+			cfg.metadata = make(map[string]string)
+		}
+		cfg.metadata[key] = value
+	}
+}
+
+// ReportBuildID records id as the build ID of the executable in the
+// report and in its stacks, as WithBuildID does for stack captures.
+func ReportBuildID(id string) ReportOption {
+	return func(cfg *reportConfig) {
+		cfg.buildID = id
+	}
+}
+
+// NewDiagnosticReport returns a report of the state of the process,
+// configured by opts. Its Stack holds the frames NewStack would capture
+// with the same skip, or is nil if skip is negative or too large.
+func NewDiagnosticReport(skip int, opts ...ReportOption) *DiagnosticReport {
+	var cfg reportConfig
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	r := &DiagnosticReport{
+		Time:       time.Now(),
+		Build:      CurrentBuild(),
+		BuildID:    cfg.buildID,
+		Deployment: deployment.Load(),
+		Process: ProcessInfo{
+			PID:          os.Getpid(),
+			NumCPU:       runtime.NumCPU(),
+			GOMAXPROCS:   runtime.GOMAXPROCS(0),
+			NumGoroutine: runtime.NumGoroutine(),
+		},
+		Metadata: cfg.metadata,
+	}
+	r.Process.Hostname, _ = os.Hostname()
+	if skip >= 0 {
+		// A stack that falls short has no frames worth reporting
+		r.Stack, _ = captureStack(skip, captureConfig{goid: true, buildID: cfg.buildID})
+	}
+	if cfg.allGoroutines {
+		r.Goroutines = allGoroutines()
+	}
+	return r
+}
+
+// allGoroutines returns the goroutines of the process, parsed from a
+// runtime traceback of all of them.
+func allGoroutines() []*Goroutine {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxReportDump {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	// The runtime's own traceback is trusted and already bounded in size
+	gs, _ := ParseGoroutines(buf, ParseLimits{MaxGoroutines: math.MaxInt, MaxLineLength: maxReportDump})
+	return gs
+}
+
+// WriteText writes the report to w as text for people to read: a summary
+// of its metadata, followed by the stack and goroutines in runtime
+// traceback format.
+func (r *DiagnosticReport) WriteText(w io.Writer) error {
+	b := append([]byte("Diagnostic report at "), r.Time.Format(time.RFC3339)...)
+	b = append(b, "\n\nBuild:       "...)
+	b = append(b, r.Build.GoVersion...)
+	b = append(b, ' ')
+	b = append(b, r.Build.GOOS...)
+	b = append(b, '/')
+	b = append(b, r.Build.GOARCH...)
+	if r.Build.Module != "" {
+		b = append(b, ", "...)
+		b = append(b, r.Build.Module...)
+		if r.Build.ModuleVersion != "" {
+			b = append(b, ' ')
+			b = append(b, r.Build.ModuleVersion...)
+		}
+	}
+	if r.BuildID != "" {
+		b = append(b, ", build ID "...)
+		b = append(b, r.BuildID...)
+	}
+	if d := r.Deployment; d != nil {
+		b = append(b, "\nDeployment:  "...)
+		b = append(b, strings.Join(slices.DeleteFunc([]string{d.Service, d.Version, d.Environment}, func(s string) bool {
+			return s == ""
+		}), " ")...)
+	}
+	b = append(b, "\nProcess:     pid "...)
+	b = strconv.AppendInt(b, int64(r.Process.PID), 10)
+	if r.Process.Hostname != "" {
+		b = append(b, " on "...)
+		b = append(b, r.Process.Hostname...)
+	}
+	b = append(b, ", "...)
+	b = strconv.AppendInt(b, int64(r.Process.NumCPU), 10)
+	b = append(b, " CPUs, GOMAXPROCS "...)
+	b = strconv.AppendInt(b, int64(r.Process.GOMAXPROCS), 10)
+	b = append(b, ", "...)
+	b = strconv.AppendInt(b, int64(r.Process.NumGoroutine), 10)
+	b = append(b, " goroutines\n"...)
+	for _, k := range slices.Sorted(maps.Keys(r.Metadata)) {
+		b = append(b, "Metadata:    "...)
+		b = append(b, k...)
+		b = append(b, " = "...)
+		b = append(b, r.Metadata[k]...)
+		b = append(b, '\n')
+	}
+
+	if r.Stack != nil {
+		b = append(b, '\n')
+		b = r.Stack.appendTraceback(b)
+	}
+	for _, g := range r.Goroutines {
+		b = append(b, '\n')
+		b = g.appendTraceback(b)
+	}
+	if _, err := w.Write(b); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package caller
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// reportHelper returns a report made by its caller's callee.
+//
+//go:noinline
+func reportHelper(skip int, opts ...ReportOption) *DiagnosticReport {
+	return NewDiagnosticReport(skip, opts...)
+}
+
+// TestNewDiagnosticReport tests the contents of a report.
+func TestNewDiagnosticReport(t *testing.T) {
+	t.Parallel()
+
+	r := reportHelper(0, ReportMetadata("a", "1"), ReportMetadata("b", "2"), ReportMetadata("a", "3"), ReportBuildID("abc/def"), nil)
+	if r.Stack == nil || r.Stack.Caller0().Function() != "TestNewDiagnosticReport" {
+		t.Errorf("Stack = %v, want it to start at TestNewDiagnosticReport", r.Stack.Frames())
+	}
+	if r.Goroutines != nil {
+		t.Errorf("Goroutines = %v, want none unless requested", r.Goroutines)
+	}
+	if r.Build != CurrentBuild() || r.BuildID != "abc/def" || r.Stack.BuildID() != "abc/def" || r.Time.IsZero() {
+		t.Errorf("report = %+v, want the current build, the build ID and time", r)
+	}
+	if r.Process.PID != os.Getpid() || r.Process.NumCPU != runtime.NumCPU() || r.Process.NumGoroutine == 0 {
+		t.Errorf("Process = %+v, want the current process", r.Process)
+	}
+	if len(r.Metadata) != 2 || r.Metadata["a"] != "3" || r.Metadata["b"] != "2" {
+		t.Errorf("Metadata = %v, want a=3 and b=2", r.Metadata)
+	}
+	if r := reportHelper(-1); r.Stack != nil {
+		t.Errorf("NewDiagnosticReport(-1) Stack = %v, want nil", r.Stack.Frames())
+	}
+}
+
+// TestNewDiagnosticReport_AllGoroutines tests that a report can include
+// every goroutine.
+func TestNewDiagnosticReport_AllGoroutines(t *testing.T) {
+	t.Parallel()
+
+	r := reportHelper(0, ReportAllGoroutines())
+	if len(r.Goroutines) == 0 {
+		t.Fatal("Goroutines is empty, want every goroutine")
+	}
+	for _, g := range r.Goroutines {
+		if g.Stack.hasFunction("github.com/balinomad/go-caller/v2.TestNewDiagnosticReport_AllGoroutines") {
+			return
+		}
+	}
+	t.Error("Goroutines does not include the reporting goroutine")
+}
+
+// TestDiagnosticReport_JSON tests the JSON encoding of a report.
+func TestDiagnosticReport_JSON(t *testing.T) {
+	t.Parallel()
+
+	r := reportHelper(0, ReportAllGoroutines(), ReportMetadata("ticket", "42"))
+	var got map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(mustMarshal(t, r)), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	for _, key := range []string{"time", "stack", "goroutines", "build", "process", "metadata"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("JSON = %v, want key %q", got, key)
+		}
+	}
+	if string(got["metadata"]) != `{"ticket":"42"}` {
+		t.Errorf("JSON metadata = %s, want the report metadata", got["metadata"])
+	}
+}
+
+// TestDiagnosticReport_WriteText tests the text rendering of a report.
+func TestDiagnosticReport_WriteText(t *testing.T) {
+	t.Parallel()
+
+	r := reportHelper(0, ReportMetadata("ticket", "42"))
+	r.Deployment = &Deployment{Service: "checkout", Environment: "production"}
+	r.Goroutines = []*Goroutine{{
+		ID: 7, State: "chan receive", Wait: 5 * time.Minute, Locked: true, Elided: true,
+		Stack:     &Stack{frames: []*callerInfo{{file: "/src/app/a.go", line: 3, fn: "app.wait", dotIdx: 3}}, goid: 7},
+		CreatedBy: &callerInfo{file: "/src/app/main.go", line: 9, fn: "app.main", dotIdx: 3},
+	}}
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		"Diagnostic report at ",
+		"\nBuild:       " + r.Build.GoVersion + " " + runtime.GOOS + "/" + runtime.GOARCH,
+		"\nDeployment:  checkout production\n",
+		"\nMetadata:    ticket = 42\n",
+		"\n\n" + r.Stack.Traceback(),
+		"\n\ngoroutine 7 [chan receive, 5 minutes, locked to thread]:\napp.wait(...)\n\t/src/app/a.go:3\n" +
+			"...additional frames elided...\ncreated by app.main\n\t/src/app/main.go:9\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteText() = %q, want it to contain %q", got, want)
+		}
+	}
+
+	gs, err := ParseGoroutines([]byte(got), ParseLimits{})
+	if err != nil || len(gs) != 2 || gs[1].ID != 7 || gs[1].Wait != r.Goroutines[0].Wait || !gs[1].Locked || !gs[1].Elided || gs[1].CreatedBy == nil {
+		t.Errorf("ParseGoroutines(WriteText()) = %v, %v, want the rendered goroutines", gs, err)
+	}
+
+	if err := r.WriteText(failingWriter{}); !errors.Is(err, errWrite) {
+		t.Errorf("WriteText() error = %v, want %v", err, errWrite)
+	}
+}
@@ -0,0 +1,128 @@
+package caller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Sink receives the messages of a Reporter.
+type Sink interface {
+	// Report handles a message at level, reported from site. The site
+	// is nil if it could not be determined.
+	Report(level slog.Level, site Caller, msg string)
+}
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(level slog.Level, site Caller, msg string)
+
+// Report calls f.
+func (f SinkFunc) Report(level slog.Level, site Caller, msg string) {
+	f(level, site, msg)
+}
+
+// slogSink is a Sink writing to a slog.Logger.
+type slogSink struct {
+	logger *slog.Logger // Logger to write to, or nil for slog.Default()
+}
+
+// SlogSink returns a Sink that writes each message to logger, with the
+// call site as an attribute under DefaultKey. A nil logger selects
+// slog.Default() at the time of each message.
+func SlogSink(logger *slog.Logger) Sink {
+	return slogSink{logger: logger}
+}
+
+// Report implements the Sink interface.
+func (s slogSink) Report(level slog.Level, site Caller, msg string) {
+	l := s.logger
+	if l == nil {
+		l = slog.Default()
+	}
+	ctx := context.Background()
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	// The record has no program counter, as the site is attached instead
+	r := slog.NewRecord(time.Now(), level, msg, 0)
+	if !isNil(site) {
+		r.AddAttrs(slog.Any(DefaultKey, site))
+	}
+	// A sink has nowhere to report a failing handler, like slog.Logger
+	_ = l.Handler().Handle(ctx, r)
+}
+
+// Reporter is a minimal facade for warnings and errors that always
+// carries the location they were reported from, for small tools that want
+// location-annotated messages without adopting a logging framework:
+//
+//	var report caller.Reporter
+//	...
+//	report.Warnf("skipping %s: %v", name, err)
+//
+// which logs with slog.Default() as
+//
+//	level=WARN msg="skipping a.txt: not found" caller.file=/src/tool/main.go caller.line=42 ...
+//
+// The zero Reporter writes to slog.Default(); NewReporter sends messages
+// to another Sink and corrects the call site past logging helpers. A
+// Reporter is safe for concurrent use if its Sink is.
+type Reporter struct {
+	sink Sink
+	skip int
+	opts []Option
+}
+
+// NewReporter returns a Reporter sending messages to sink, or to
+// slog.Default() if sink is nil. The call site of each message is found
+// as NewWith finds it with opts, so that SkipFrames can pass over helpers
+// that wrap the Reporter:
+//
+//	r := caller.NewReporter(nil, caller.SkipFrames(caller.MatchPackage("example.com/tool/internal/logx/...")))
+func NewReporter(sink Sink, opts ...Option) *Reporter {
+	return &Reporter{sink: sink, opts: opts}
+}
+
+// WithSkip returns a copy of r that skips skip more frames when finding
+// the call site of a message, for a helper function that reports on
+// behalf of its caller. A negative skip is treated as 0.
+func (r *Reporter) WithSkip(skip int) *Reporter {
+	c := &Reporter{}
+	if r != nil {
+		*c = *r
+	}
+	c.skip += max(skip, 0)
+	return c
+}
+
+// Warnf reports a warning formatted like fmt.Sprintf.
+func (r *Reporter) Warnf(format string, args ...any) {
+	r.report(slog.LevelWarn, format, args)
+}
+
+// Errorf reports an error formatted like fmt.Sprintf. Unlike the
+// package-level Errorf, it does not return an error.
+func (r *Reporter) Errorf(format string, args ...any) {
+	r.report(slog.LevelError, format, args)
+}
+
+// report sends a message at level to the sink of r, with the call site of
+// the Reporter method calling report. A nil Reporter behaves like the
+// zero Reporter.
+func (r *Reporter) report(level slog.Level, format string, args []any) {
+	var (
+		sink Sink
+		skip int
+		opts []Option
+	)
+	if r != nil {
+		sink, skip, opts = r.sink, r.skip, r.opts
+	}
+	if sink == nil {
+		sink = SlogSink(nil)
+	}
+	// Skip report and the Reporter method calling it
+	site := NewWith(skip+1, opts...)
+	sink.Report(level, site, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,106 @@
+package caller
+
+import (
+	"bytes"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// sinkMessage is a message received by a recording Sink.
+type sinkMessage struct {
+	level slog.Level
+	site  Caller
+	msg   string
+}
+
+// recordingSink returns a Sink appending the messages it receives to
+// msgs.
+func recordingSink(msgs *[]sinkMessage) Sink {
+	return SinkFunc(func(level slog.Level, site Caller, msg string) {
+		*msgs = append(*msgs, sinkMessage{level, site, msg})
+	})
+}
+
+// reportVia reports a warning through r on behalf of its caller.
+func reportVia(r *Reporter, msg string) {
+	r.WithSkip(1).Warnf("%s", msg)
+}
+
+// reportFromHelper reports a warning through r from a helper that r is
+// configured to skip.
+func reportFromHelper(r *Reporter, msg string) {
+	r.Warnf("%s", msg)
+}
+
+// TestReporter tests that a Reporter sends formatted messages to its
+// sink with the corrected call site.
+func TestReporter(t *testing.T) {
+	t.Parallel()
+
+	var msgs []sinkMessage
+	r := NewReporter(recordingSink(&msgs))
+	r.Warnf("skipping %s", "a.txt")
+	want := Immediate()
+	r.Errorf("failed: %d", 3)
+	reportVia(r, "via")
+
+	skipping := NewReporter(recordingSink(&msgs), SkipFrames(MatchFunction("github.com/balinomad/go-caller/v2.reportFromHelper")))
+	reportFromHelper(skipping, "helper")
+	helperSite := Immediate()
+
+	if len(msgs) != 4 {
+		t.Fatalf("sink received %d messages, want 4", len(msgs))
+	}
+	tests := []struct {
+		level    slog.Level
+		msg      string
+		line     int
+		function string
+	}{
+		{slog.LevelWarn, "skipping a.txt", want.Line() - 1, "TestReporter"},
+		{slog.LevelError, "failed: 3", want.Line() + 1, "TestReporter"},
+		{slog.LevelWarn, "via", want.Line() + 2, "TestReporter"},
+		{slog.LevelWarn, "helper", helperSite.Line() - 1, "TestReporter"},
+	}
+	for i, tt := range tests {
+		m := msgs[i]
+		if m.level != tt.level || m.msg != tt.msg || Function(m.site) != tt.function || Line(m.site) != tt.line {
+			t.Errorf("message %d = %v %q from %v:%d, want %v %q from %s line %d", i, m.level, m.msg, m.site, Line(m.site), tt.level, tt.msg, tt.function, tt.line)
+		}
+	}
+
+	if c := (*Reporter)(nil).WithSkip(-1); c == nil || c.sink != nil || c.skip != 0 {
+		t.Errorf("nil WithSkip(-1) = %+v, want a zero Reporter", c)
+	}
+}
+
+// TestSlogSink tests that SlogSink writes enabled messages with the call
+// site attached.
+func TestSlogSink(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelError}))
+	r := NewReporter(SlogSink(logger))
+
+	r.Warnf("filtered")
+	if buf.Len() != 0 {
+		t.Errorf("SlogSink wrote %q below the logger level, want nothing", buf.String())
+	}
+	r.Errorf("failed %s", "here")
+	site := Immediate()
+	got := buf.String()
+	for _, want := range []string{"level=ERROR", `msg="failed here"`, "caller.function=TestSlogSink", "caller.line=" + strconv.Itoa(site.Line()-1)} {
+		if !strings.Contains(got, want) {
+			t.Errorf("SlogSink wrote %q, want %q", got, want)
+		}
+	}
+
+	buf.Reset()
+	SlogSink(logger).Report(slog.LevelError, nil, "no site")
+	if got := buf.String(); !strings.Contains(got, `msg="no site"`) || strings.Contains(got, DefaultKey) {
+		t.Errorf("SlogSink wrote %q, want the message without a call site", got)
+	}
+}
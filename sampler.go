@@ -0,0 +1,138 @@
+package caller
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSampleInterval is the time between samples used by
+	// StartSampler for a non-positive SamplerConfig.Interval.
+	DefaultSampleInterval = 10 * time.Second
+
+	// DefaultSampleGoroutines is the goroutine limit used by StartSampler
+	// for a non-positive SamplerConfig.MaxGoroutines.
+	DefaultSampleGoroutines = 1000
+
+	// DefaultSampleLabel labels the entries recorded by StartSampler when
+	// SamplerConfig.Label is empty.
+	DefaultSampleLabel = "goroutine"
+)
+
+// SamplerConfig configures the background sampler started by
+// StartSampler.
+type SamplerConfig struct {
+	Recorder      *Recorder     // Destination of the samples; nil selects the package-wide Recorder at each sample
+	Interval      time.Duration // Time between samples; non-positive selects DefaultSampleInterval
+	MaxGoroutines int           // Samples are skipped while more goroutines run; non-positive selects DefaultSampleGoroutines
+	Label         string        // Label of the recorded entries; empty selects DefaultSampleLabel
+}
+
+// StartSampler starts a goroutine that periodically takes a snapshot of
+// the stacks of all goroutines and adds it to a Recorder, one Entry per
+// goroutine, so that when a service wedges a recent history of its
+// stacks is already at hand:
+//
+//	r := caller.NewRecorder(10000)
+//	stop := caller.StartSampler(caller.SamplerConfig{Recorder: r, Interval: 30 * time.Second})
+//	defer stop()
+//
+// Each Entry carries the stack of one goroutine, as runtime.GoroutineProfile
+// reports its innermost 32 frames, with runtime frames left out as by
+// NewStack; its Caller is the first frame of that stack, and all entries
+// of a snapshot share the same Time. The sampler's own goroutine is not
+// recorded. A snapshot briefly stops the world, in proportion to the
+// number of goroutines, so snapshots are skipped while more than
+// MaxGoroutines run. Size the Recorder to hold a few snapshots, or give
+// the sampler a Recorder of its own so that it does not push out other
+// captures.
+//
+// It returns a function that stops the sampler and waits for its
+// goroutine to exit; calling it more than once has no further effect.
+func StartSampler(cfg SamplerConfig) func() {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = DefaultSampleInterval
+	}
+	limit := cfg.MaxGoroutines
+	if limit <= 0 {
+		limit = DefaultSampleGoroutines
+	}
+	label := cfg.Label
+	if label == "" {
+		label = DefaultSampleLabel
+	}
+
+	done, exited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				r := cfg.Recorder
+				if r == nil {
+					r = recorder.Load()
+				}
+				if r != nil {
+					sampleGoroutines(r, label, limit)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-exited
+	}
+}
+
+// sampleGoroutines adds the stack of every goroutine but the calling one
+// to r, and returns the number of entries added. It adds nothing if more
+// than limit goroutines run.
+func sampleGoroutines(r *Recorder, label string, limit int) int {
+	n := runtime.NumGoroutine()
+	if n > limit {
+		return 0
+	}
+	// Leave room for goroutines started in the meantime
+	records := make([]runtime.StackRecord, n+n/4+8)
+	n, ok := runtime.GoroutineProfile(records)
+	if !ok || n > limit {
+		return 0
+	}
+
+	var self string
+	if pc, _, _, ok := runtime.Caller(0); ok {
+		if f := runtime.FuncForPC(pc); f != nil {
+			self = f.Name()
+		}
+	}
+
+	now, added := time.Now(), 0
+	for _, rec := range records[:n] {
+		s := stackFromPCs(rec.Stack(), captureConfig{})
+		if len(s.frames) == 0 || s.hasFunction(self) {
+			continue
+		}
+		r.add(Entry{Caller: s.frames[0], Time: now, Label: label, Stack: s})
+		added++
+	}
+	return added
+}
+
+// hasFunction reports whether any frame of s is in the function with the
+// given full name.
+func (s *Stack) hasFunction(fullFunc string) bool {
+	for _, f := range s.frames {
+		if f.fn == fullFunc {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package caller
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// samplerBlocked blocks until ch is closed, as a wedged goroutine would.
+func samplerBlocked(ch chan struct{}) {
+	<-ch
+}
+
+// findSampled returns the first entry of r whose stack has a frame in a
+// function with the given name suffix, or false if there is none.
+func findSampled(r *Recorder, suffix string) (Entry, bool) {
+	for _, e := range r.Snapshot() {
+		for _, f := range e.Stack.Frames() {
+			if strings.HasSuffix(f.FullFunction(), suffix) {
+				return e, true
+			}
+		}
+	}
+	return Entry{}, false
+}
+
+// TestSampleGoroutines tests that a snapshot records the stacks of other
+// goroutines and honors the goroutine limit.
+func TestSampleGoroutines(t *testing.T) {
+	t.Parallel()
+
+	ch := make(chan struct{})
+	defer close(ch)
+	go samplerBlocked(ch)
+
+	r := NewRecorder(10000)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if n := sampleGoroutines(r, "snap", 1_000_000); n == 0 || n != r.Len() {
+			t.Fatalf("sampleGoroutines() = %d with %d entries recorded, want all goroutines", n, r.Len())
+		}
+		if _, ok := findSampled(r, ".samplerBlocked"); ok || time.Now().After(deadline) {
+			break
+		}
+		r.Reset()
+		time.Sleep(time.Millisecond)
+	}
+
+	e, ok := findSampled(r, ".samplerBlocked")
+	if !ok {
+		t.Fatal("Snapshot() has no entry for the blocked goroutine")
+	}
+	if e.Label != "snap" || e.Time.IsZero() || !e.Caller.Equal(e.Stack.Frame(0)) {
+		t.Errorf("entry = %+v, want label snap, a time, and the first frame as caller", e)
+	}
+	if _, ok := findSampled(r, ".sampleGoroutines"); ok {
+		t.Error("Snapshot() recorded the sampling goroutine")
+	}
+	if r.SizeBytes() < e.Stack.SizeBytes() {
+		t.Errorf("SizeBytes() = %d, want the sampled stacks counted", r.SizeBytes())
+	}
+
+	if n := sampleGoroutines(NewRecorder(10), "", 1); n != 0 {
+		t.Errorf("sampleGoroutines() over the limit = %d, want 0", n)
+	}
+}
+
+// TestStartSampler tests that the sampler records periodically until it
+// is stopped.
+func TestStartSampler(t *testing.T) {
+	t.Parallel()
+
+	r := NewRecorder(10000)
+	stop := StartSampler(SamplerConfig{Recorder: r, Interval: time.Millisecond, MaxGoroutines: 1_000_000})
+	deadline := time.Now().Add(5 * time.Second)
+	for r.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	stop()
+
+	n := r.Len()
+	if n == 0 {
+		t.Fatal("StartSampler() recorded nothing")
+	}
+	if got := r.Snapshot()[0].Label; got != DefaultSampleLabel {
+		t.Errorf("Label = %q, want %q", got, DefaultSampleLabel)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if got := r.Len(); got != n {
+		t.Errorf("Len() after stop = %d, want %d", got, n)
+	}
+}
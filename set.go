@@ -0,0 +1,128 @@
+package caller
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+// CallerSet is a set of callers keyed on their semantic identity, the Key
+// of each caller, for coverage-like analysis such as comparing the call
+// sites that fired in one test run with those of the last:
+//
+//	added := current.Difference(previous)
+//	for c := range added.All() {
+//		fmt.Println("new call site:", c)
+//	}
+//
+// Of callers equal by EqualCallers, the set keeps the first one added.
+// The zero CallerSet is empty and ready to use. A CallerSet is not safe
+// for concurrent use.
+type CallerSet struct {
+	m map[Key]Caller
+}
+
+// NewCallerSet returns a set holding cs.
+func NewCallerSet(cs ...Caller) *CallerSet {
+	s := &CallerSet{}
+	s.Add(cs...)
+	return s
+}
+
+// Add adds each of cs to the set. Nil callers are ignored.
+func (s *CallerSet) Add(cs ...Caller) {
+	for _, c := range cs {
+		if isNil(c) {
+			continue
+		}
+		k := KeyOf(c)
+		if _, ok := s.m[k]; ok {
+			continue
+		}
+		if s.m == nil {
+			s.m = make(map[Key]Caller)
+		}
+		s.m[k] = c
+	}
+}
+
+// Remove removes the caller equal to c from the set, if any.
+func (s *CallerSet) Remove(c Caller) {
+	if s != nil {
+		delete(s.m, KeyOf(c))
+	}
+}
+
+// Contains reports whether the set holds a caller equal to c.
+func (s *CallerSet) Contains(c Caller) bool {
+	if s == nil || isNil(c) {
+		return false
+	}
+	_, ok := s.m[KeyOf(c)]
+	return ok
+}
+
+// Len returns the number of callers in the set.
+func (s *CallerSet) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.m)
+}
+
+// All returns an iterator over the callers of the set, sorted by
+// CompareCallers.
+func (s *CallerSet) All() iter.Seq[Caller] {
+	return func(yield func(Caller) bool) {
+		if s == nil {
+			return
+		}
+		for _, k := range slices.SortedFunc(maps.Keys(s.m), compareKeys) {
+			if !yield(s.m[k]) {
+				return
+			}
+		}
+	}
+}
+
+// Union returns a new set holding the callers of s and other. Where both
+// hold equal callers, the one of s is kept.
+func (s *CallerSet) Union(other *CallerSet) *CallerSet {
+	out := s.filter(func(Caller) bool { return true })
+	if other != nil {
+		for _, c := range other.m {
+			out.Add(c)
+		}
+	}
+	return out
+}
+
+// Intersect returns a new set holding the callers of s that other also
+// holds.
+func (s *CallerSet) Intersect(other *CallerSet) *CallerSet {
+	return s.filter(other.Contains)
+}
+
+// Difference returns a new set holding the callers of s that other does
+// not hold.
+func (s *CallerSet) Difference(other *CallerSet) *CallerSet {
+	return s.filter(func(c Caller) bool { return !other.Contains(c) })
+}
+
+// filter returns a new set holding the callers of s for which keep
+// reports true.
+func (s *CallerSet) filter(keep func(Caller) bool) *CallerSet {
+	out := &CallerSet{}
+	if s == nil {
+		return out
+	}
+	for k, c := range s.m {
+		if keep(c) {
+			if out.m == nil {
+				out.m = make(map[Key]Caller)
+			}
+			out.m[k] = c
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package caller
+
+import (
+	"slices"
+	"testing"
+)
+
+// setCallers returns the callers of s in iteration order.
+func setCallers(s *CallerSet) []Caller {
+	return slices.Collect(s.All())
+}
+
+// TestCallerSet tests adding, removing and looking up callers by
+// semantic identity.
+func TestCallerSet(t *testing.T) {
+	t.Parallel()
+
+	f := newCompactFixture()
+	var s CallerSet
+	if s.Contains(f.a1) || s.Len() != 0 || len(setCallers(&s)) != 0 {
+		t.Fatal("zero CallerSet is not empty")
+	}
+	s.Add(f.all...)
+	if got, want := setCallers(&s), []Caller{f.a1, f.b, f.c}; !slices.Equal(got, want) {
+		t.Errorf("All() = %v, want %v", got, want)
+	}
+	if !s.Contains(f.a2) || s.Contains(nil) || s.Contains(&callerInfo{file: "/src/z.go"}) {
+		t.Error("Contains() does not match by semantic identity")
+	}
+
+	s.Remove(f.a2)
+	s.Remove(nil)
+	if s.Contains(f.a1) || s.Len() != 2 {
+		t.Errorf("after Remove(), set = %v, want a1 removed", setCallers(&s))
+	}
+
+	for range s.All() {
+		break
+	}
+
+	var nilSet *CallerSet
+	nilSet.Remove(f.a1)
+	if nilSet.Len() != 0 || nilSet.Contains(f.a1) || len(setCallers(nilSet)) != 0 {
+		t.Error("nil CallerSet is not empty")
+	}
+}
+
+// TestCallerSet_Algebra tests union, intersection and difference.
+func TestCallerSet_Algebra(t *testing.T) {
+	t.Parallel()
+
+	f := newCompactFixture()
+	ab := NewCallerSet(f.a1, f.b)
+	bc := NewCallerSet(f.b, f.c, f.a2)
+	bc.Remove(f.a2)
+
+	tests := []struct {
+		name string
+		got  *CallerSet
+		want []Caller
+	}{
+		{"union", ab.Union(bc), []Caller{f.a1, f.b, f.c}},
+		{"intersect", ab.Intersect(bc), []Caller{f.b}},
+		{"difference", ab.Difference(bc), []Caller{f.a1}},
+		{"reverse difference", bc.Difference(ab), []Caller{f.c}},
+		{"union with nil", ab.Union(nil), []Caller{f.a1, f.b}},
+		{"nil union", (*CallerSet)(nil).Union(bc), []Caller{f.b, f.c}},
+		{"intersect with nil", ab.Intersect(nil), nil},
+		{"difference with nil", ab.Difference(nil), []Caller{f.a1, f.b}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := setCallers(tt.got); !slices.Equal(got, tt.want) {
+				t.Errorf("set = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Where both sets hold equal callers, the receiver's is kept
+	if got := setCallers(NewCallerSet(f.a2).Union(NewCallerSet(f.a1))); len(got) != 1 || got[0] != f.a2 {
+		t.Errorf("Union() = %v, want the receiver's caller", got)
+	}
+	if ab.Len() != 2 || bc.Len() != 2 {
+		t.Error("set operations modified their operands")
+	}
+}
@@ -0,0 +1,75 @@
+package caller
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ShortLocationDepth returns the location of c like ShortLocation, but
+// keeping the last n path segments of its file: 3 gives
+// "pkg/server/handler.go:42" rather than "handler.go:42", which stays
+// unambiguous in large repositories where many files share a name.
+// A value of n below 1 means 1. It returns an empty string if c is nil.
+func ShortLocationDepth(c Caller, n int) string {
+	return shortLocation(File(c), Line(c), n)
+}
+
+// shortLocation formats file:line, keeping the last n segments of file.
+func shortLocation(file string, line, n int) string {
+	if file == "" {
+		return ""
+	}
+	short := lastSegments(file, n)
+	if line <= 0 {
+		return short
+	}
+
+	var sb strings.Builder
+	sb.WriteString(short)
+	sb.WriteByte(':')
+	sb.WriteString(strconv.Itoa(line))
+	return sb.String()
+}
+
+// pathSeparators are the separators recognized in captured paths: the
+// runtime always uses forward slashes, while paths rewritten by a
+// FileMapper may use the native separator.
+const pathSeparators = "/" + string(os.PathSeparator)
+
+// lastSegments returns the last n segments of path. For n of 1 or less
+// it returns filepath.Base(path).
+func lastSegments(path string, n int) string {
+	if n <= 1 {
+		return filepath.Base(path)
+	}
+	path = strings.TrimRight(path, pathSeparators)
+	end := len(path)
+	for range n {
+		i := strings.LastIndexAny(path[:end], pathSeparators)
+		if i < 0 {
+			return path
+		}
+		end = i
+	}
+	return path[end+1:]
+}
+
+// PackageFile returns the package name and file name of c, as in
+// "caller/caller.go", a compact location that stays unambiguous across
+// packages for log lines and metrics labels. It returns just the file
+// name if the package is unknown, and an empty string if c is nil or
+// has no file.
+func PackageFile(c Caller) string {
+	file := File(c)
+	if file == "" {
+		return ""
+	}
+	base := filepath.Base(file)
+	pkg := PackageName(c)
+	if pkg == "" {
+		return base
+	}
+	return pkg + "/" + base
+}
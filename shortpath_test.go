@@ -0,0 +1,64 @@
+package caller
+
+import (
+	"math"
+	"testing"
+)
+
+// TestShortLocationDepth tests that ShortLocationDepth keeps the requested
+// number of trailing path segments.
+func TestShortLocationDepth(t *testing.T) {
+	t.Parallel()
+
+	c := &callerInfo{file: "/src/app/pkg/server/handler.go", line: 42, fn: "app.Serve", dotIdx: 3}
+
+	tests := []struct {
+		name string
+		c    Caller
+		n    int
+		want string
+	}{
+		{"depth 1", c, 1, "handler.go:42"},
+		{"depth 0", c, 0, "handler.go:42"},
+		{"negative depth", c, -2, "handler.go:42"},
+		{"depth 3", c, 3, "pkg/server/handler.go:42"},
+		{"depth beyond path", c, 10, "/src/app/pkg/server/handler.go:42"},
+		{"huge depth", c, math.MaxInt, "/src/app/pkg/server/handler.go:42"},
+		{"relative path", &callerInfo{file: "server/handler.go", line: 7}, 5, "server/handler.go:7"},
+		{"no line", &callerInfo{file: "/src/pkg/a.go"}, 2, "pkg/a.go"},
+		{"no file", &callerInfo{line: 3}, 2, ""},
+		{"nil", nil, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := ShortLocationDepth(tt.c, tt.n); got != tt.want {
+				t.Errorf("ShortLocationDepth(%d) = %q, want %q", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPackageFile tests PackageFile with and without package information.
+func TestPackageFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		c    Caller
+		want string
+	}{
+		{"full", &callerInfo{file: "/src/caller/caller.go", line: 42, fn: "example.com/x/caller.New", dotIdx: 20}, "caller/caller.go"},
+		{"no package", &callerInfo{file: "/src/main.go", line: 1}, "main.go"},
+		{"no file", &callerInfo{fn: "example.com/x/caller.New", dotIdx: 20}, ""},
+		{"nil", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := PackageFile(tt.c); got != tt.want {
+				t.Errorf("PackageFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
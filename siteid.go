@@ -0,0 +1,50 @@
+package caller
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"iter"
+	"strings"
+)
+
+// siteIDEncoding renders site IDs in Crockford's base32 alphabet, which
+// leaves out I, L, O and U so that IDs read aloud or copied by hand are
+// not misread.
+var siteIDEncoding = base32.NewEncoding("0123456789ABCDEFGHJKMNPQRSTVWXYZ").WithPadding(base32.NoPadding)
+
+// siteIDBytes is the number of hash bytes kept in a site ID (8 characters).
+const siteIDBytes = 5
+
+// SiteID returns a short, stable token identifying the call site of c,
+// such as "7KQ2M3XA", for user-facing error codes that support teams can
+// pass on and engineers can map back to a location with LookupSiteID:
+//
+//	return fmt.Errorf("internal error (site %s)", caller.SiteID(caller.New(0)))
+//
+// It hashes the FunctionKey of c, so the ID is the same on every machine
+// and build, and survives line shifts, but is shared by all call sites
+// within one function. It returns an empty string if c is nil or carries
+// neither a file nor a function.
+func SiteID(c Caller) string {
+	key := FunctionKey(c)
+	if key == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	return siteIDEncoding.EncodeToString(sum[:siteIDBytes])
+}
+
+// LookupSiteID returns the callers of cs whose SiteID is id, such as the
+// callers of a CallerSet, a Recorder snapshot or decoded logs. The ID is
+// matched case-insensitively, and with O, I and L read as 0, 1 and 1, as
+// Crockford's base32 prescribes for IDs typed by hand.
+func LookupSiteID(id string, cs iter.Seq[Caller]) []Caller {
+	id = strings.NewReplacer("O", "0", "I", "1", "L", "1").Replace(strings.ToUpper(id))
+	var found []Caller
+	for c := range cs {
+		if !isNil(c) && SiteID(c) == id {
+			found = append(found, c)
+		}
+	}
+	return found
+}
@@ -0,0 +1,86 @@
+package caller
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestSiteID tests that IDs are short, stable across machines and lines,
+// and distinct between functions.
+func TestSiteID(t *testing.T) {
+	t.Parallel()
+
+	base := &callerInfo{file: "/home/a/src/app/server.go", line: 42, fn: "example.com/app.(*Server).Handle", dotIdx: 15}
+	tests := []struct {
+		name string
+		c    Caller
+		same bool
+	}{
+		{"other machine", &callerInfo{file: "/build/app/server.go", line: 42, fn: base.fn, dotIdx: 15}, true},
+		{"other line", &callerInfo{file: base.file, line: 50, fn: base.fn, dotIdx: 15}, true},
+		{"other function", &callerInfo{file: base.file, line: 42, fn: "example.com/app.(*Server).Close", dotIdx: 15}, false},
+	}
+	id := SiteID(base)
+	if len(id) != 8 || id != SiteID(base) {
+		t.Fatalf("SiteID() = %q, want a stable 8-character ID", id)
+	}
+	for _, r := range id {
+		if !('0' <= r && r <= '9' || 'A' <= r && r <= 'Z') || r == 'I' || r == 'L' || r == 'O' || r == 'U' {
+			t.Errorf("SiteID() = %q, want Crockford base32 characters", id)
+		}
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := SiteID(tt.c); (got == id) != tt.same {
+				t.Errorf("SiteID() = %q, base ID %q, want same %t", got, id, tt.same)
+			}
+		})
+	}
+
+	if got := SiteID(nil); got != "" {
+		t.Errorf("SiteID(nil) = %q, want empty", got)
+	}
+	if got := SiteID(&callerInfo{}); got != "" {
+		t.Errorf("SiteID() of an empty caller = %q, want empty", got)
+	}
+}
+
+// TestLookupSiteID tests mapping an ID back to callers, including IDs
+// typed by hand.
+func TestLookupSiteID(t *testing.T) {
+	t.Parallel()
+
+	f := newCompactFixture()
+	id := SiteID(f.a1)
+	typed := []rune(id)
+	for i, r := range typed {
+		switch r {
+		case '0':
+			typed[i] = 'o'
+		case '1':
+			typed[i] = 'l'
+		default:
+			typed[i] = r | 0x20 // Lower case
+		}
+	}
+
+	tests := []struct {
+		name string
+		id   string
+		want []Caller
+	}{
+		{"exact", id, []Caller{f.a1}},
+		{"typed", string(typed), []Caller{f.a1}},
+		{"other", SiteID(f.c), []Caller{f.c, f.c}},
+		{"unknown", "ZZZZZZZZ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := LookupSiteID(tt.id, slices.Values(f.all)); !slices.Equal(got, tt.want) {
+				t.Errorf("LookupSiteID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
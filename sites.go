@@ -0,0 +1,99 @@
+package caller
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// siteMap holds per-call-site state of type T, keyed by the program
+// counter of the call site. Program counters that resolve to the same
+// source position, as happens when the calling function is inlined in
+// several places, share one state. Entries are created on first use and
+// never removed; the number of call sites in a program is bounded.
+type siteMap[T any] struct {
+	byPC   sync.Map // map[uintptr]*T
+	bySite sync.Map // map[siteKey]*T
+}
+
+// siteKey identifies a call site by its source position.
+type siteKey struct {
+	file string
+	line int
+	fn   string
+}
+
+// get returns the state for pc, creating it if needed.
+func (s *siteMap[T]) get(pc uintptr) *T {
+	if v, ok := s.byPC.Load(pc); ok {
+		if state, ok := v.(*T); ok {
+			return state
+		}
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	v, _ := s.bySite.LoadOrStore(siteKey{frame.File, frame.Line, frame.Function}, new(T))
+	state, ok := v.(*T)
+	if !ok {
+		// Unreachable: bySite only ever holds *T values
+		state = new(T)
+	}
+	s.byPC.Store(pc, state)
+	return state
+}
+
+// reset discards all state.
+func (s *siteMap[T]) reset() {
+	s.byPC.Clear()
+	s.bySite.Clear()
+}
+
+// callSitePC returns the program counter identifying a call site.
+// The skip parameter follows runtime.Callers: 0 identifies the caller of
+// callSitePC. It returns 0 if the stack is not that deep.
+func callSitePC(skip int) uintptr {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return 0
+	}
+	return pcs[0]
+}
+
+// epoch anchors monotonic time measurements for per-site state.
+var epoch = time.Now()
+
+// suppressions records, per call site, when Suppress last returned true,
+// as nanoseconds since epoch plus one (zero means never).
+var suppressions siteMap[atomic.Int64]
+
+// Suppress reports whether the code at its call site should go ahead.
+// It returns true at most once per ttl for each distinct call site, and
+// false otherwise, which makes "warn at most once per hour from here"
+// a one-liner:
+//
+//	if caller.Suppress(time.Hour) {
+//		log.Println("cache disabled, falling back to slow path")
+//	}
+//
+// Call sites are told apart by source position, so a helper that calls
+// Suppress is limited as one site no matter how many places it is
+// inlined into. A non-positive ttl always returns true.
+func Suppress(ttl time.Duration) bool {
+	pc := callSitePC(1)
+	if pc == 0 || ttl <= 0 {
+		return true
+	}
+
+	now := int64(time.Since(epoch)) + 1
+	last := suppressions.get(pc)
+	for {
+		prev := last.Load()
+		if prev != 0 && now-prev < int64(ttl) {
+			return false
+		}
+		if last.CompareAndSwap(prev, now) {
+			return true
+		}
+	}
+}
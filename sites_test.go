@@ -0,0 +1,52 @@
+package caller
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSuppress tests that Suppress lets through one call per TTL per call
+// site and keeps separate call sites independent.
+func TestSuppress(t *testing.T) {
+	suppressions.reset()
+
+	allowed := 0
+	for range 5 {
+		if Suppress(time.Hour) {
+			allowed++
+		}
+	}
+	if allowed != 1 {
+		t.Errorf("Suppress(time.Hour) allowed %d calls from one site, want 1", allowed)
+	}
+
+	if !Suppress(time.Hour) {
+		t.Error("Suppress(time.Hour) from a new call site should return true")
+	}
+
+	for i := range 3 {
+		if !Suppress(0) {
+			t.Errorf("Suppress(0) call %d returned false, want true", i)
+		}
+	}
+}
+
+// TestSuppress_Expiry tests that a call site is let through again once its
+// TTL has elapsed.
+func TestSuppress_Expiry(t *testing.T) {
+	suppressions.reset()
+
+	// All calls must go through the same call site
+	check := func() bool { return Suppress(time.Millisecond) }
+
+	got := []bool{check(), check()}
+	time.Sleep(5 * time.Millisecond)
+	got = append(got, check())
+
+	want := []bool{true, false, true}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Suppress() call %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
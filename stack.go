@@ -0,0 +1,415 @@
+package caller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strconv"
+)
+
+// Stack is a sequence of call frames captured from a goroutine's stack,
+// innermost first: frame 0 is the deepest captured call and each
+// following frame is the caller of the one before it.
+//
+// The zero Stack is empty, and all methods treat a nil *Stack as empty.
+// A Stack is immutable once captured and safe for concurrent use, except
+// that UnmarshalJSON must not run concurrently with other methods.
+type Stack struct {
+	frames  []*callerInfo
+	pcs     []uintptr   // Call-site program counter of each frame, if captured live
+	buildID string      // Build ID of the executable the stack was captured in
+	build   *BuildInfo  // Build metadata of that executable, if known; shared, never modified
+	deploy  *Deployment // Deployment metadata set at capture time, if any; shared, never modified
+	goid    uint64      // ID of the goroutine the stack was captured on, if known
+}
+
+var (
+	// ErrInvalidSkip is returned by CaptureStack for a negative skip.
+	ErrInvalidSkip = errors.New("invalid negative skip")
+
+	// ErrShallowStack is matched by every DepthError.
+	ErrShallowStack = errors.New("stack shallower than required")
+)
+
+// DepthError reports that a stack capture found fewer frames than
+// required, usually because the skip was too large or the capture was
+// made too close to the root of the goroutine.
+type DepthError struct {
+	Want int // Minimum number of frames required
+	Got  int // Number of frames captured
+}
+
+// Error implements the error interface.
+func (e *DepthError) Error() string {
+	return "captured " + strconv.Itoa(e.Got) + " stack frames, want at least " + strconv.Itoa(e.Want)
+}
+
+// Unwrap returns ErrShallowStack.
+func (e *DepthError) Unwrap() error {
+	return ErrShallowStack
+}
+
+// NewStack captures the stack of the calling goroutine, configured by opts.
+// The skip parameter has the same meaning as for New: with 0, the first
+// frame is the caller of the function that calls NewStack.
+// At most 1024 frames are captured. Frames of the runtime package and
+// testing.tRunner are left out, unless KeepAllFrames is given. It
+// returns nil if skip is negative, no frames remain, or fewer remain
+// than required by RequireDepth; use CaptureStack to tell these cases
+// apart.
+func NewStack(skip int, opts ...Option) *Stack {
+	if skip < 0 {
+		return nil
+	}
+	s, err := captureStack(skip, newCaptureConfig(opts))
+	if err != nil {
+		return nil
+	}
+	return s
+}
+
+// CaptureStack is like NewStack, but reports why a capture fell short:
+// it returns ErrInvalidSkip for a negative skip, and a *DepthError if no
+// frames remain or fewer remain than required by RequireDepth, together
+// with the frames that were captured, if any.
+func CaptureStack(skip int, opts ...Option) (*Stack, error) {
+	if skip < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSkip, skip)
+	}
+	return captureStack(skip, newCaptureConfig(opts))
+}
+
+// captureStack captures the stack for NewStack and CaptureStack, with
+// skip counted from the caller of their caller.
+func captureStack(skip int, cfg captureConfig) (*Stack, error) {
+	// Skip callers itself, captureStack, the exported function calling
+	// it, and the function calling that
+	s := stackFromPCs(callers(skip+skipAdjust+1), cfg)
+	if cfg.goid {
+		s.goid = goroutineID()
+	}
+
+	want := max(cfg.minDepth, 1)
+	switch {
+	case len(s.frames) == 0:
+		return nil, &DepthError{Want: want}
+	case len(s.frames) < want:
+		return s, &DepthError{Want: want, Got: len(s.frames)}
+	default:
+		return s, nil
+	}
+}
+
+// stackFromPCs resolves the return addresses pcs into a Stack configured
+// by cfg, recording the metadata of the running executable.
+func stackFromPCs(pcs []uintptr, cfg captureConfig) *Stack {
+	if !cfg.keepAll {
+		cfg.skip = append(cfg.skip, isNoiseFrame)
+	}
+	s := &Stack{buildID: cfg.buildID, build: currentBuild(), deploy: deployment.Load()}
+	cfg.walk(pcs, func(c *callerInfo, pc uintptr) bool {
+		s.frames = append(s.frames, c)
+		s.pcs = append(s.pcs, pc)
+		return true
+	})
+	return s
+}
+
+// stringTable interns the strings of the frames of a single decoded
+// Stack, so that frames from the same file or function share one copy of
+// its path or name instead of one allocated per frame by the decoder.
+// Live captures need no table, as their strings point into the binary.
+type stringTable map[string]string
+
+// share replaces the file and function strings of c with their interned
+// copies.
+func (t stringTable) share(c *callerInfo) {
+	c.file = t.intern(c.file)
+	c.fn = t.intern(c.fn)
+}
+
+// intern returns the copy of s held by the table, adding s if needed.
+func (t stringTable) intern(s string) string {
+	if s == "" {
+		return s
+	}
+	if v, ok := t[s]; ok {
+		return v
+	}
+	t[s] = s
+	return s
+}
+
+// from returns a Stack holding the frames of s from index i on, with the
+// same capture metadata.
+func (s *Stack) from(i int) *Stack {
+	sub := *s
+	sub.frames = s.frames[min(i, len(s.frames)):]
+	if len(s.pcs) > 0 {
+		sub.pcs = s.pcs[min(i, len(s.pcs)):]
+	}
+	return &sub
+}
+
+// Callers captures up to n callers of the calling goroutine in a single
+// pass over the stack, innermost first, and returns them appended to
+// buf[:0], so that a caller-provided slice is reused and only grows when
+// it is too small. The skip parameter has the same meaning as for New.
+// It is meant for error types that capture a handful of frames per error
+// at high rates:
+//
+//	var buf [4]caller.Caller
+//	frames := caller.Callers(0, len(buf), buf[:0])
+//
+// Like NewStack, it does not feed the Recorder or capture hooks.
+// It returns an empty slice if skip is negative, n is not positive, or
+// the stack is not that deep.
+func Callers(skip, n int, buf []Caller) []Caller {
+	buf = buf[:0]
+	if skip < 0 || n <= 0 {
+		return buf
+	}
+	n = min(n, maxStackDepth)
+
+	// Small requests avoid allocating the program counter buffer
+	var arr [16]uintptr
+	pcs := arr[:]
+	if n > len(arr) {
+		pcs = make([]uintptr, n)
+	}
+
+	// Skip runtime.Callers, Callers, and the function calling Callers
+	pcs = pcs[:runtime.Callers(skip+skipAdjust+1, pcs[:n])]
+	if len(pcs) == 0 {
+		return buf
+	}
+
+	frames := runtime.CallersFrames(pcs)
+	for len(buf) < n {
+		frame, more := frames.Next()
+		if frame.File != "" || frame.Function != "" {
+			buf = append(buf, frameCallerInfo(frame))
+		}
+		if !more {
+			break
+		}
+	}
+	return buf
+}
+
+// BuildID returns the build ID of the executable the stack was captured
+// in, as given with WithBuildID, or an empty string if it is unknown.
+func (s *Stack) BuildID() string {
+	if s == nil {
+		return ""
+	}
+	return s.buildID
+}
+
+// Build returns the toolchain, platform and main module metadata of the
+// executable the stack was captured in, and reports whether it is known.
+func (s *Stack) Build() (BuildInfo, bool) {
+	if s == nil || s.build == nil {
+		return BuildInfo{}, false
+	}
+	return *s.build, true
+}
+
+// Deployment returns the deployment metadata set by SetDeployment when
+// the stack was captured, and reports whether any was set.
+func (s *Stack) Deployment() (Deployment, bool) {
+	if s == nil || s.deploy == nil {
+		return Deployment{}, false
+	}
+	return *s.deploy, true
+}
+
+// Len returns the number of frames in the stack.
+func (s *Stack) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.frames)
+}
+
+// Frame returns the frame at index i, where 0 is the innermost frame.
+// It returns nil if i is out of range.
+func (s *Stack) Frame(i int) Caller {
+	if i < 0 || i >= s.Len() {
+		return nil
+	}
+	return s.frames[i]
+}
+
+// Caller0 returns the innermost frame of the stack, the one that
+// corresponds to a Caller captured with the same skip.
+// It returns nil for an empty stack.
+func (s *Stack) Caller0() Caller {
+	return s.Frame(0)
+}
+
+// Frames returns the frames of the stack, innermost first.
+// The returned slice is a copy and may be modified freely.
+func (s *Stack) Frames() []Caller {
+	out := make([]Caller, s.Len())
+	for i := range out {
+		out[i] = s.frames[i]
+	}
+	return out
+}
+
+// All returns an iterator over the frames of the stack with their index,
+// innermost first, without copying them into a slice as Frames does:
+//
+//	for i, f := range s.All() {
+//		fmt.Printf("#%d %s\n", i, f)
+//	}
+func (s *Stack) All() iter.Seq2[int, Caller] {
+	return func(yield func(int, Caller) bool) {
+		for i := range s.Len() {
+			if !yield(i, s.frames[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Above returns the frame n levels above the first frame equal to c, that
+// is, the caller n calls further out. A negative n moves inwards instead.
+// It returns nil if c is not part of the stack or the result is out of
+// range.
+func (s *Stack) Above(c Caller, n int) Caller {
+	if s == nil || isNil(c) {
+		return nil
+	}
+	for i, f := range s.frames {
+		if f.Equal(c) {
+			return s.Frame(i + n)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// A Stack is encoded as an object with a "frames" array holding the
+// frames innermost first, each encoded like a Caller, a "build_id"
+// field with the build ID of the executable, a "build" object with its
+// toolchain, platform and main module metadata, and a "deployment" object
+// with the metadata set by SetDeployment, if known.
+func (s *Stack) MarshalJSON() ([]byte, error) {
+	return s.marshalJSON(0)
+}
+
+// marshalJSON encodes s with schema version v in the stack and each
+// frame, or without version fields if v is 0.
+func (s *Stack) marshalJSON(v int) ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+	var frames any = s.frames
+	switch {
+	case v != 0:
+		versioned := make([]versionedCaller, len(s.frames))
+		for i, f := range s.frames {
+			versioned[i] = versionedCaller{c: f}
+		}
+		frames = versioned
+	case s.frames == nil:
+		frames = []*callerInfo{}
+	}
+	b, err := json.Marshal(struct {
+		V       int         `json:"v,omitempty"`
+		BuildID string      `json:"build_id,omitempty"`
+		Build   *BuildInfo  `json:"build,omitempty"`
+		Deploy  *Deployment `json:"deployment,omitempty"`
+		Frames  any         `json:"frames"`
+	}{
+		V:       v,
+		BuildID: s.buildID,
+		Build:   s.build,
+		Deploy:  s.deploy,
+		Frames:  frames,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("JSON marshal: %w", err)
+	}
+	return b, nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// It accepts both plain and versioned payloads; see JSONSchemaVersion for
+// the decoding rules. Null frames are dropped.
+func (s *Stack) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		V       int           `json:"v"`
+		BuildID string        `json:"build_id"`
+		Build   *BuildInfo    `json:"build"`
+		Deploy  *Deployment   `json:"deployment"`
+		Frames  []*callerInfo `json:"frames"`
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return fmt.Errorf("JSON unmarshal: %w", err)
+	}
+	if err := checkSchemaVersion(aux.V); err != nil {
+		return err
+	}
+	s.setDecoded(aux.Frames, aux.BuildID, aux.Build, aux.Deploy)
+	return nil
+}
+
+// setDecoded replaces the contents of s with the fields of a decoded
+// stack payload, dropping null frames.
+func (s *Stack) setDecoded(frames []*callerInfo, buildID string, build *BuildInfo, deploy *Deployment) {
+	s.frames = slices.DeleteFunc(frames, func(c *callerInfo) bool { return c == nil })
+	if len(s.frames) > 1 {
+		strs := make(stringTable)
+		for _, f := range s.frames {
+			strs.share(f)
+		}
+	}
+	s.pcs = nil
+	s.buildID = buildID
+	s.build = build
+	s.deploy = deploy
+	s.goid = 0
+}
+
+// LogValue implements the slog.LogValuer interface, rendering the stack
+// in StackLogGroups mode. For a nil or empty stack, it returns an empty
+// slog.Value.
+func (s *Stack) LogValue() slog.Value {
+	return s.LogValueAs(StackLogGroups)
+}
+
+// LogValueAs renders the stack as a slog.Value in the given mode, for
+// log schemas that expect another form than LogValue renders:
+//
+//	logger.Error("request failed", "stack", s.LogValueAs(caller.StackLogStrings))
+//
+// Unknown modes behave like StackLogGroups. For a nil or empty stack, it
+// returns an empty slog.Value.
+func (s *Stack) LogValueAs(mode StackLogMode) slog.Value {
+	if s.Len() == 0 {
+		return slog.Value{}
+	}
+
+	switch mode {
+	case StackLogStrings:
+		locs := make([]string, len(s.frames))
+		for i, f := range s.frames {
+			locs[i] = f.ShortLocation()
+		}
+		return slog.AnyValue(locs)
+	default:
+		attrs := make([]slog.Attr, len(s.frames))
+		for i, f := range s.frames {
+			attrs[i] = slog.Attr{Key: "frame." + strconv.Itoa(i), Value: f.LogValue()}
+		}
+		return slog.GroupValue(attrs...)
+	}
+}
@@ -0,0 +1,344 @@
+package caller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// stackHelper captures a stack whose first frame is its caller.
+func stackHelper(skip int, opts ...Option) *Stack {
+	return NewStack(skip, opts...)
+}
+
+// TestNewStack tests that NewStack starts at the expected frame, honors
+// skip, matching New, and leaves out runtime and test runner frames
+// unless asked to keep them.
+func TestNewStack(t *testing.T) {
+	t.Parallel()
+
+	s := stackHelper(0)
+	_, file, line, _ := runtime.Caller(0)
+
+	if s.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", s.Len())
+	}
+	top := s.Caller0()
+	if got, want := top.Function(), "TestNewStack"; got != want {
+		t.Errorf("Caller0().Function() = %q, want %q", got, want)
+	}
+	if top.File() != file || top.Line() != line-1 {
+		t.Errorf("Caller0().Location() = %q, want %s:%d", top.Location(), file, line-1)
+	}
+
+	all := stackHelper(0, KeepAllFrames())
+	if got, want := all.Frame(1).FullFunction(), "testing.tRunner"; got != want {
+		t.Errorf("Frame(1).FullFunction() = %q, want %q", got, want)
+	}
+	if got, want := all.Frame(all.Len()-1).FullFunction(), goexitFunc; got != want {
+		t.Errorf("outermost FullFunction() = %q, want %q", got, want)
+	}
+
+	skipped := stackHelper(1, KeepAllFrames())
+	if got, want := skipped.Caller0().FullFunction(), "testing.tRunner"; got != want {
+		t.Errorf("NewStack(1).Caller0().FullFunction() = %q, want %q", got, want)
+	}
+	if got := stackHelper(1); got != nil {
+		t.Errorf("NewStack(1) = %v, want nil with only noise frames left", got)
+	}
+
+	if got := NewStack(-1); got != nil {
+		t.Errorf("NewStack(-1) = %v, want nil", got)
+	}
+	if got := NewStack(10000); got != nil {
+		t.Errorf("NewStack(10000) = %v, want nil", got)
+	}
+}
+
+// TestStack_Navigation tests Frame, Frames and Above, including out of
+// range requests and an empty Stack.
+func TestStack_Navigation(t *testing.T) {
+	t.Parallel()
+
+	a := &callerInfo{file: "a.go", line: 1, fn: "pkg.A", dotIdx: 3}
+	b := &callerInfo{file: "b.go", line: 2, fn: "pkg.B", dotIdx: 3}
+	c := &callerInfo{file: "c.go", line: 3, fn: "pkg.C", dotIdx: 3}
+	s := &Stack{frames: []*callerInfo{a, b, c}}
+
+	tests := []struct {
+		name string
+		got  Caller
+		want Caller
+	}{
+		{"Frame(0)", s.Frame(0), a},
+		{"Frame(2)", s.Frame(2), c},
+		{"Caller0()", s.Caller0(), a},
+		{"Above(a, 1)", s.Above(a, 1), b},
+		{"Above(a, 2)", s.Above(&callerInfo{file: "a.go", line: 1, fn: "pkg.A"}, 2), c},
+		{"Above(c, -2)", s.Above(c, -2), a},
+		{"Above(b, 0)", s.Above(b, 0), b},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if !tt.want.Equal(tt.got) {
+				t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+
+	for name, got := range map[string]Caller{
+		"Frame(-1)":         s.Frame(-1),
+		"Frame(3)":          s.Frame(3),
+		"Above(c, 1)":       s.Above(c, 1),
+		"Above(unknown, 1)": s.Above(&callerInfo{file: "x.go"}, 1),
+		"Above(nil, 1)":     s.Above(nil, 1),
+		"empty.Caller0()":   (&Stack{}).Caller0(),
+		"empty.Above(a, 0)": (&Stack{}).Above(a, 0),
+		"nil.Frame(0)":      (*Stack)(nil).Frame(0),
+		"nil.Above(a, 0)":   (*Stack)(nil).Above(a, 0),
+	} {
+		if got != nil {
+			t.Errorf("%s = %v, want nil", name, got)
+		}
+	}
+
+	frames := s.Frames()
+	frames[0] = nil
+	if s.Frame(0) == nil {
+		t.Error("modifying the result of Frames() changed the Stack")
+	}
+	if got := len((*Stack)(nil).Frames()); got != 0 {
+		t.Errorf("len(nil.Frames()) = %d, want 0", got)
+	}
+}
+
+// TestStack_All tests iterating over frames with their index, stopping
+// early and over empty stacks.
+func TestStack_All(t *testing.T) {
+	t.Parallel()
+
+	s := stackHelper(0, KeepAllFrames())
+	n := 0
+	for i, f := range s.All() {
+		if i != n || f != s.Frame(i) {
+			t.Errorf("All() yielded %d, %v, want %d, %v", i, f, n, s.Frame(n))
+		}
+		n++
+	}
+	if n != s.Len() {
+		t.Errorf("All() yielded %d frames, want %d", n, s.Len())
+	}
+
+	for i := range s.All() {
+		if i > 0 {
+			t.Fatal("All() continued after the loop ended")
+		}
+		break
+	}
+	for _, empty := range []*Stack{nil, {}} {
+		for i, f := range empty.All() {
+			t.Errorf("All() of an empty stack yielded %d, %v", i, f)
+		}
+	}
+}
+
+// TestStack_LogValue tests the default Stack rendering and empty stacks.
+func TestStack_LogValue(t *testing.T) {
+	t.Parallel()
+
+	s := &Stack{frames: []*callerInfo{
+		{file: "/src/a.go", line: 1, fn: "pkg.A", dotIdx: 3},
+		{file: "/src/b.go", line: 2, fn: "pkg.B", dotIdx: 3},
+	}}
+
+	got := s.LogValue()
+	if got.Kind() != slog.KindGroup {
+		t.Fatalf("LogValue() kind = %v, want %v", got.Kind(), slog.KindGroup)
+	}
+	attrs := got.Group()
+	if len(attrs) != 2 || attrs[0].Key != "frame.0" || attrs[1].Key != "frame.1" {
+		t.Fatalf("LogValue() = %v, want groups keyed frame.0 and frame.1", got)
+	}
+	if !attrs[1].Value.Equal(s.frames[1].LogValue()) {
+		t.Errorf("LogValue() frame 1 = %v, want %v", attrs[1].Value, s.frames[1].LogValue())
+	}
+
+	for _, empty := range []*Stack{nil, {}} {
+		if got := empty.LogValue(); got.Any() != nil {
+			t.Errorf("LogValue() of an empty stack = %v, want empty value", got)
+		}
+	}
+}
+
+// TestStack_LogValueAs tests both renderings through the text and JSON
+// handlers.
+func TestStack_LogValueAs(t *testing.T) {
+	t.Parallel()
+
+	s := &Stack{frames: []*callerInfo{
+		{file: "/src/a.go", line: 1, fn: "pkg.A", dotIdx: 3},
+		{file: "/src/b.go", line: 2, fn: "pkg.B", dotIdx: 3},
+	}}
+
+	tests := []struct {
+		name     string
+		mode     StackLogMode
+		wantText string
+		wantJSON string
+	}{
+		{"groups", StackLogGroups, "stack.frame.1.file=/src/b.go", `"stack":{"frame.0":{"file":"/src/a.go"`},
+		{"strings", StackLogStrings, `stack="[a.go:1 b.go:2]"`, `"stack":["a.go:1","b.go:2"]`},
+		{"unknown", StackLogMode(99), "stack.frame.0.line=1", `"frame.1":{"file":"/src/b.go"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var text, js bytes.Buffer
+			slog.New(slog.NewTextHandler(&text, nil)).Info("msg", "stack", s.LogValueAs(tt.mode))
+			slog.New(slog.NewJSONHandler(&js, nil)).Info("msg", "stack", s.LogValueAs(tt.mode))
+			if !strings.Contains(text.String(), tt.wantText) {
+				t.Errorf("text output = %q, want %q", text.String(), tt.wantText)
+			}
+			if !strings.Contains(js.String(), tt.wantJSON) {
+				t.Errorf("JSON output = %q, want %q", js.String(), tt.wantJSON)
+			}
+		})
+	}
+
+	if got := (*Stack)(nil).LogValueAs(StackLogStrings); got.Any() != nil {
+		t.Errorf("LogValueAs() of a nil stack = %v, want empty value", got)
+	}
+}
+
+// callersHelper captures callers whose first frame is its caller.
+func callersHelper(skip, n int, buf []Caller) []Caller {
+	return Callers(skip, n, buf)
+}
+
+// TestCallers tests that Callers starts at the same frame as NewStack,
+// honors n and reuses the provided buffer.
+func TestCallers(t *testing.T) {
+	t.Parallel()
+
+	want := stackHelper(0, KeepAllFrames())
+	buf := make([]Caller, 1, 8)
+	got := callersHelper(0, 2, buf)
+	if len(got) != 2 {
+		t.Fatalf("len(Callers(0, 2, buf)) = %d, want 2", len(got))
+	}
+	if &got[0] != &buf[0] {
+		t.Error("Callers() did not reuse the provided buffer")
+	}
+	if got[0].Function() != "TestCallers" || got[1].FullFunction() != want.Frame(1).FullFunction() {
+		t.Errorf("Callers() = %v, want frames starting at TestCallers", got)
+	}
+
+	if got := callersHelper(1, 1, nil); len(got) != 1 || got[0].FullFunction() != "testing.tRunner" {
+		t.Errorf("Callers(1, 1, nil) = %v, want [testing.tRunner]", got)
+	}
+	if got := callersHelper(0, 100, nil); len(got) != want.Len() {
+		t.Errorf("len(Callers(0, 100, nil)) = %d, want %d", len(got), want.Len())
+	}
+	for _, tt := range []struct{ skip, n int }{{-1, 1}, {0, 0}, {0, -1}, {10000, 1}} {
+		if got := callersHelper(tt.skip, tt.n, buf); len(got) != 0 {
+			t.Errorf("Callers(%d, %d, buf) = %v, want empty", tt.skip, tt.n, got)
+		}
+	}
+}
+
+// BenchmarkCallers benchmarks capturing a few frames into a reused buffer.
+func BenchmarkCallers(b *testing.B) {
+	buf := make([]Caller, 0, 4)
+	b.ReportAllocs()
+	for range b.N {
+		buf = Callers(0, 4, buf)
+	}
+}
+
+// TestCaptureStack tests the errors reported for invalid skips and
+// stacks shallower than required.
+func TestCaptureStack(t *testing.T) {
+	t.Parallel()
+
+	s, err := CaptureStack(0, KeepAllFrames())
+	if err != nil || s.Caller0().FullFunction() != "testing.tRunner" {
+		t.Errorf("CaptureStack(0) = %v, %v, want a stack from testing.tRunner", s, err)
+	}
+	if _, err := CaptureStack(-1); !errors.Is(err, ErrInvalidSkip) {
+		t.Errorf("CaptureStack(-1) error = %v, want %v", err, ErrInvalidSkip)
+	}
+
+	tests := []struct {
+		name      string
+		skip      int
+		opts      []Option
+		wantDepth *DepthError
+	}{
+		{"depth met", 0, []Option{KeepAllFrames(), RequireDepth(2)}, nil},
+		{"too shallow", 0, []Option{KeepAllFrames(), RequireDepth(100)}, &DepthError{Want: 100, Got: s.Len()}},
+		{"only noise frames", 0, nil, &DepthError{Want: 1}},
+		{"no frames", 10000, nil, &DepthError{Want: 1}},
+		{"no frames, requirement", 10000, []Option{RequireDepth(5)}, &DepthError{Want: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := CaptureStack(tt.skip, tt.opts...)
+			if tt.wantDepth == nil {
+				if err != nil {
+					t.Errorf("CaptureStack() error = %v, want nil", err)
+				}
+				return
+			}
+			var de *DepthError
+			if !errors.As(err, &de) || *de != *tt.wantDepth || !errors.Is(err, ErrShallowStack) {
+				t.Fatalf("CaptureStack() error = %v, want %+v", err, tt.wantDepth)
+			}
+			if got.Len() != tt.wantDepth.Got {
+				t.Errorf("CaptureStack() returned %d frames, want %d", got.Len(), tt.wantDepth.Got)
+			}
+			if NewStack(tt.skip, tt.opts...) != nil {
+				t.Error("NewStack() should return nil when CaptureStack fails")
+			}
+		})
+	}
+
+	if got, want := (&DepthError{Want: 3, Got: 1}).Error(), "captured 1 stack frames, want at least 3"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+// recurseStack captures a stack below depth nested calls of itself.
+func recurseStack(depth int, opts ...Option) *Stack {
+	if depth == 0 {
+		return NewStack(0, opts...)
+	}
+	return recurseStack(depth-1, opts...)
+}
+
+// TestStack_SharedStrings tests that frames of a decoded stack from the
+// same file share one copy of its path and function name.
+func TestStack_SharedStrings(t *testing.T) {
+	t.Parallel()
+
+	var s Stack
+	if err := json.Unmarshal([]byte(mustMarshal(t, recurseStack(3))), &s); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if s.Len() < 3 {
+		t.Fatalf("Len() = %d, want at least 3", s.Len())
+	}
+	first, second := s.frames[0], s.frames[1]
+	if first.file != second.file || unsafe.StringData(first.file) != unsafe.StringData(second.file) {
+		t.Errorf("frames from %q do not share the file path", first.file)
+	}
+	if unsafe.StringData(first.fn) != unsafe.StringData(second.fn) {
+		t.Errorf("frames of %q do not share the function name", first.fn)
+	}
+}
@@ -0,0 +1,208 @@
+package caller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// streamFlushSize is the buffered output size at which EncodeJSONTo
+// writes to its destination.
+const streamFlushSize = 32 * 1024
+
+// EncodeJSONTo writes stacks to w as a JSON array, producing the same
+// output as json.Marshal of the corresponding []*Stack, except that a nil
+// slice is written as an empty array. Frames are encoded straight into a
+// reused buffer, without building a value, slice or string for each one,
+// for crash collectors that serialize all-goroutine snapshots with
+// thousands of frames.
+func EncodeJSONTo(w io.Writer, stacks []Stack) error {
+	e := streamEncoder{w: w, buf: make([]byte, 0, streamFlushSize+1024)}
+	e.buf = append(e.buf, '[')
+	for i := range stacks {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		if err := e.stack(&stacks[i]); err != nil {
+			return err
+		}
+	}
+	e.buf = append(e.buf, ']')
+	return e.flush()
+}
+
+// streamEncoder buffers the output of EncodeJSONTo.
+type streamEncoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+// stack appends the encoding of s, flushing as the buffer fills.
+func (e *streamEncoder) stack(s *Stack) error {
+	e.buf = append(e.buf, '{')
+	if s.buildID != "" {
+		e.buf = append(e.buf, `"build_id":`...)
+		e.buf = appendJSONString(e.buf, s.buildID)
+		e.buf = append(e.buf, ',')
+	}
+	if s.build != nil {
+		if err := e.object("build", s.build); err != nil {
+			return err
+		}
+	}
+	if s.deploy != nil {
+		if err := e.object("deployment", s.deploy); err != nil {
+			return err
+		}
+	}
+
+	e.buf = append(e.buf, `"frames":[`...)
+	for i, f := range s.frames {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		e.buf = appendCallerJSON(e.buf, f)
+		if len(e.buf) >= streamFlushSize {
+			if err := e.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	e.buf = append(e.buf, "]}"...)
+	return nil
+}
+
+// object appends the field name with the json.Marshal encoding of v,
+// followed by a comma, for the small metadata objects of a Stack.
+func (e *streamEncoder) object(name string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("JSON marshal: %w", err)
+	}
+	e.buf = append(e.buf, '"')
+	e.buf = append(e.buf, name...)
+	e.buf = append(e.buf, `":`...)
+	e.buf = append(e.buf, b...)
+	e.buf = append(e.buf, ',')
+	return nil
+}
+
+// flush writes out and empties the buffer.
+func (e *streamEncoder) flush() error {
+	if len(e.buf) == 0 {
+		return nil
+	}
+	if _, err := e.w.Write(e.buf); err != nil {
+		return fmt.Errorf("write JSON: %w", err)
+	}
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// appendCallerJSON appends the JSON encoding of c to b, matching
+// callerInfo.MarshalJSON.
+func appendCallerJSON(b []byte, c *callerInfo) []byte {
+	if c == nil {
+		return append(b, "null"...)
+	}
+	start := len(b)
+	b = append(b, '{')
+	field := func(name string) {
+		if len(b) > start+1 {
+			b = append(b, ',')
+		}
+		b = append(b, '"')
+		b = append(b, name...)
+		b = append(b, `":`...)
+	}
+	if c.file != "" {
+		field("file")
+		b = appendJSONString(b, c.file)
+	}
+	if c.line != 0 {
+		field("line")
+		b = strconv.AppendInt(b, int64(c.line), 10)
+	}
+	if fn := c.Function(); fn != "" {
+		field("function")
+		b = appendJSONString(b, fn)
+	}
+	if pkg := c.Package(); pkg != "" {
+		field("package")
+		b = appendJSONString(b, pkg)
+	}
+	if c.has(flagInApp) {
+		field("in_app")
+		b = append(b, "true"...)
+	}
+	return append(b, '}')
+}
+
+// hexDigits is used to escape characters as \u00XX.
+const hexDigits = "0123456789abcdef"
+
+// jsonSafe reports whether an ASCII character can appear unescaped in a
+// JSON string written by json.Marshal, which also escapes <, > and &.
+var jsonSafe = func() [utf8.RuneSelf]bool {
+	var t [utf8.RuneSelf]bool
+	for c := 0x20; c < utf8.RuneSelf; c++ {
+		t[c] = true
+	}
+	for _, c := range `"\<>&` {
+		t[c] = false
+	}
+	return t
+}()
+
+// appendJSONString appends s to b as a JSON string, escaped the same way
+// as by json.Marshal, including its HTML-safe escaping.
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if !jsonSafe[c] {
+				b = appendASCIIEscape(append(b, s[start:i]...), c)
+				start = i + 1
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b = append(append(b, s[start:i]...), "\ufffd"...)
+			start = i + size
+		case r == '\u2028' || r == '\u2029':
+			b = append(append(b, s[start:i]...), '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+			start = i + size
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
+
+// appendASCIIEscape appends the JSON escape sequence for the ASCII
+// character c to b.
+func appendASCIIEscape(b []byte, c byte) []byte {
+	switch c {
+	case '"', '\\':
+		return append(b, '\\', c)
+	case '\b':
+		return append(b, '\\', 'b')
+	case '\f':
+		return append(b, '\\', 'f')
+	case '\n':
+		return append(b, '\\', 'n')
+	case '\r':
+		return append(b, '\\', 'r')
+	case '\t':
+		return append(b, '\\', 't')
+	default:
+		return append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+	}
+}
@@ -0,0 +1,86 @@
+package caller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+// TestEncodeJSONTo tests that streamed output matches json.Marshal,
+// including string escaping and output larger than the flush size.
+func TestEncodeJSONTo(t *testing.T) {
+	t.Parallel()
+
+	odd := []string{
+		`C:\src\a "quoted".go`, "<tag>&amp;", "tab\tnew\nline\r\b\f\x01\x1f",
+		"é日本\u2028\u2029", "bad\xffutf8", "",
+	}
+	weird := &Stack{buildID: "a/b", deploy: &Deployment{Service: "<svc>", Environment: "prod"}}
+	for i, s := range odd {
+		fn := "example.com/" + s + ".F" + s
+		weird.frames = append(weird.frames, &callerInfo{file: s, line: i, fn: fn, dotIdx: functionNameIndex(fn)})
+	}
+	weird.frames = append(weird.frames, &callerInfo{fn: "main", dotIdx: -1}, &callerInfo{file: "a.go", line: 1, fn: "main.main", dotIdx: 4, flags: flagInApp})
+
+	large := &Stack{}
+	for i := range 5000 {
+		large.frames = append(large.frames, &callerInfo{file: "/src/app/file" + strconv.Itoa(i) + ".go", line: i, fn: "example.com/app.F", dotIdx: 15})
+	}
+
+	tests := []struct {
+		name   string
+		stacks []Stack
+	}{
+		{"empty", []Stack{}},
+		{"live", []Stack{*stackHelper(0), *stackHelper(1, KeepAllFrames())}},
+		{"escaping", []Stack{*weird}},
+		{"large", []Stack{*large, {}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ptrs := make([]*Stack, len(tt.stacks))
+			for i := range tt.stacks {
+				ptrs[i] = &tt.stacks[i]
+			}
+			want, err := json.Marshal(ptrs)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := EncodeJSONTo(&buf, tt.stacks); err != nil {
+				t.Fatalf("EncodeJSONTo() error = %v", err)
+			}
+			if got := buf.Bytes(); !bytes.Equal(got, want) {
+				t.Errorf("EncodeJSONTo() = %.300s, want %.300s", got, want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeJSONTo(&buf, nil); err != nil || buf.String() != "[]" {
+		t.Errorf("EncodeJSONTo(nil) = %s, %v, want []", buf.String(), err)
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+var errWrite = errors.New("write failed")
+
+// Write implements io.Writer.
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
+
+// TestEncodeJSONTo_WriteError tests that write errors are returned.
+func TestEncodeJSONTo_WriteError(t *testing.T) {
+	t.Parallel()
+
+	if err := EncodeJSONTo(failingWriter{}, []Stack{*stackHelper(0)}); !errors.Is(err, errWrite) {
+		t.Errorf("EncodeJSONTo() error = %v, want %v", err, errWrite)
+	}
+}
@@ -0,0 +1,51 @@
+package caller
+
+import (
+	"strconv"
+	"strings"
+)
+
+// summaryUnknown is the package shown in a summary for frames whose
+// package is unknown.
+const summaryUnknown = "?"
+
+// Summary returns a condensed description of the stack, grouping
+// consecutive frames by package and listing the groups outermost first,
+// in the direction of the calls:
+//
+//	app/handlers (3) → pkg/db (2) → database/sql (4)
+//
+// It suits log lines where the full stack is too verbose. Frames of an
+// unknown package are shown as "?". It returns an empty string for a nil
+// or empty stack.
+func (s *Stack) Summary() string {
+	if s.Len() == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	pkg, n := "", 0
+	flush := func() {
+		if sb.Len() > 0 {
+			sb.WriteString(" → ")
+		}
+		sb.WriteString(pkg)
+		sb.WriteString(" (")
+		sb.WriteString(strconv.Itoa(n))
+		sb.WriteByte(')')
+	}
+	for i := len(s.frames) - 1; i >= 0; i-- {
+		p := s.frames[i].Package()
+		if p == "" {
+			p = summaryUnknown
+		}
+		if n > 0 && p != pkg {
+			flush()
+			n = 0
+		}
+		pkg = p
+		n++
+	}
+	flush()
+	return sb.String()
+}
@@ -0,0 +1,56 @@
+package caller
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestStack_Summary tests grouping consecutive frames by package.
+func TestStack_Summary(t *testing.T) {
+	t.Parallel()
+
+	frame := func(fn string) *callerInfo {
+		return &callerInfo{file: "/src/x.go", line: 1, fn: fn, dotIdx: functionNameIndex(fn)}
+	}
+
+	tests := []struct {
+		name string
+		s    *Stack
+		want string
+	}{
+		{"nil", nil, ""},
+		{"empty", &Stack{}, ""},
+		{"single", &Stack{frames: []*callerInfo{frame("app/handlers.Get")}}, "app/handlers (1)"},
+		{
+			name: "groups",
+			s: &Stack{frames: []*callerInfo{
+				frame("database/sql.(*DB).query"),
+				frame("database/sql.(*DB).QueryContext"),
+				frame("pkg/db.Find"),
+				frame("pkg/db.(*Store).Get"),
+				frame("app/handlers.get"),
+				frame("app/handlers.Get.func1"),
+				frame("app/handlers.Get"),
+			}},
+			want: "app/handlers (3) → pkg/db (2) → database/sql (2)",
+		},
+		{
+			name: "repeated package",
+			s:    &Stack{frames: []*callerInfo{frame("a.F"), frame("b.G"), frame("a.H")}},
+			want: "a (1) → b (1) → a (1)",
+		},
+		{"unknown package", &Stack{frames: []*callerInfo{{file: "/src/x.go"}, frame("main.main")}}, "main (1) → ? (1)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.s.Summary(); got != tt.want {
+				t.Errorf("Summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := stackHelper(0).Summary(); !strings.HasSuffix(got, "go-caller/v2 (1)") {
+		t.Errorf("Summary() of a live stack = %q, want this package", got)
+	}
+}
@@ -0,0 +1,45 @@
+package caller
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// resolvedFiles caches the resolution of each file path compared or
+// normalized with ResolveSymlinks, mapping the captured path to the
+// resolved one.
+var resolvedFiles sync.Map
+
+// ResolveSymlinks makes comparisons resolve symbolic links in file paths
+// with filepath.EvalSymlinks, so that callers captured under different
+// mount or symlink views of the same source tree still match. Normalize
+// rewrites the file path to the resolved one accordingly. Each distinct
+// path is resolved once, on first use, and the result is cached for the
+// lifetime of the process. Relative paths, such as those produced by a
+// FileMapper, and paths that cannot be resolved are left unchanged.
+func ResolveSymlinks() CompareOption {
+	return func(cfg *compareConfig) {
+		cfg.symlinks = true
+	}
+}
+
+// resolveFile returns file with its symbolic links resolved, or file
+// unchanged if it is relative or cannot be resolved.
+func resolveFile(file string) string {
+	if !filepath.IsAbs(file) {
+		return file
+	}
+	if v, ok := resolvedFiles.Load(file); ok {
+		if resolved, ok := v.(string); ok {
+			return resolved
+		}
+	}
+	resolved, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		resolved = file
+	}
+	// Runtime paths use forward slashes on every platform
+	resolved = filepath.ToSlash(resolved)
+	resolvedFiles.Store(file, resolved)
+	return resolved
+}
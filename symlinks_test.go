@@ -0,0 +1,59 @@
+package caller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestResolveSymlinks tests that callers captured through a symlinked
+// directory are equivalent to those captured through the real one.
+func TestResolveSymlinks(t *testing.T) {
+	t.Parallel()
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error = %v", err)
+	}
+	realDir := filepath.Join(dir, "src")
+	if err := os.Mkdir(realDir, 0o755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	realFile := filepath.ToSlash(filepath.Join(realDir, "main.go"))
+	if err := os.WriteFile(realFile, nil, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(realDir, link); err != nil {
+		t.Skipf("Symlink() error = %v", err)
+	}
+
+	viaLink := &callerInfo{file: filepath.ToSlash(filepath.Join(link, "main.go")), line: 7, fn: "main.main", dotIdx: 4}
+	direct := &callerInfo{file: realFile, line: 7, fn: "main.main", dotIdx: 4}
+	if Equivalent(viaLink, direct) || viaLink.Equal(direct) {
+		t.Fatal("Equivalent() = true without ResolveSymlinks, want false")
+	}
+
+	if !Equivalent(viaLink, direct, ResolveSymlinks()) || !Equivalent(direct, viaLink, ResolveSymlinks()) {
+		t.Error("Equivalent() = false, want callers through the symlink to match")
+	}
+	if !Equivalent(viaLink, direct, ResolveSymlinks(), IgnoreFileCase()) {
+		t.Error("Equivalent() with IgnoreFileCase = false, want callers through the symlink to match")
+	}
+	n := Normalize(viaLink, ResolveSymlinks())
+	if got := n.File(); got != realFile {
+		t.Errorf("Normalize().File() = %q, want %q", got, realFile)
+	}
+	if !n.Equal(direct) {
+		t.Error("Normalize().Equal() = false, want the resolved caller to match")
+	}
+	if got := viaLink.File(); got == realFile {
+		t.Error("resolution changed the captured path, want it kept")
+	}
+
+	for _, file := range []string{"pkg/main.go", "/does/not/exist.go"} {
+		if got := resolveFile(file); got != file {
+			t.Errorf("resolveFile(%q) = %q, want it unchanged", file, got)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package caller
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FormatTestFailure formats msg as reported at c exactly as go test
+// prints a failure or log message, so that test helpers and assertion
+// libraries built on this package produce output that editors and CI
+// tools already hyperlink:
+//
+//	handler_test.go:42: got 3, want 4
+//
+// As in go test, the first line is indented by four spaces and prefixed
+// with the file name and line, further lines of msg are indented by eight
+// spaces, a single trailing newline of msg is dropped, and the result
+// ends with a newline. A nil or invalid c is shown as "???:1", as go test
+// does when it cannot determine the location.
+func FormatTestFailure(c Caller, msg string) string {
+	file, line := "???", 1
+	if Valid(c) {
+		file, line = filepath.Base(c.File()), c.Line()
+	}
+
+	var sb strings.Builder
+	sb.WriteString("    ")
+	sb.WriteString(file)
+	sb.WriteByte(':')
+	sb.WriteString(strconv.Itoa(line))
+	sb.WriteString(": ")
+	lines := strings.Split(msg, "\n")
+	if n := len(lines); n > 1 && lines[n-1] == "" {
+		lines = lines[:n-1]
+	}
+	for i, l := range lines {
+		if i > 0 {
+			sb.WriteString("\n        ")
+		}
+		sb.WriteString(l)
+	}
+	sb.WriteByte('\n')
+	return sb.String()
+}
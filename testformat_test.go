@@ -0,0 +1,34 @@
+package caller
+
+import "testing"
+
+// TestFormatTestFailure tests that messages are formatted as go test
+// prints them.
+func TestFormatTestFailure(t *testing.T) {
+	t.Parallel()
+
+	c := &callerInfo{file: "/src/app/handler_test.go", line: 42, fn: "app.TestHandler", dotIdx: 3}
+
+	tests := []struct {
+		name string
+		c    Caller
+		msg  string
+		want string
+	}{
+		{"single line", c, "got 3, want 4", "    handler_test.go:42: got 3, want 4\n"},
+		{"trailing newline", c, "got 3\n", "    handler_test.go:42: got 3\n"},
+		{"multiple lines", c, "diff:\n-a\n+b", "    handler_test.go:42: diff:\n        -a\n        +b\n"},
+		{"empty message", c, "", "    handler_test.go:42: \n"},
+		{"blank last lines", c, "a\n\n", "    handler_test.go:42: a\n        \n"},
+		{"nil caller", nil, "boom", "    ???:1: boom\n"},
+		{"invalid caller", Invalid(), "boom", "    ???:1: boom\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := FormatTestFailure(tt.c, tt.msg); got != tt.want {
+				t.Errorf("FormatTestFailure() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package caller
+
+import (
+	"runtime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// testPrefixes are the function name prefixes recognized by go test.
+var testPrefixes = []string{"Test", "Benchmark", "Fuzz", "Example"}
+
+// TestOrigin returns the frame of the innermost TestXxx, BenchmarkXxx,
+// FuzzXxx or ExampleXxx function, or of a closure declared inside one
+// such as a subtest body, that transitively called TestOrigin. Shared test
+// helpers can use it to report failures against the test that invoked
+// them rather than against the helper itself.
+// It reports false if no such frame is found on the current goroutine.
+func TestOrigin() (Caller, bool) {
+	frames := runtime.CallersFrames(callers(1))
+	for {
+		frame, more := frames.Next()
+		if strings.HasSuffix(frame.File, "_test.go") {
+			c := frameCallerInfo(frame)
+			if isTestName(c.Function()) {
+				return c, true
+			}
+		}
+		if !more {
+			return nil, false
+		}
+	}
+}
+
+// isTestName reports whether fn, a function name without package prefix,
+// is a test, benchmark, fuzz test or example, or a closure within one.
+// As in go test, the character after the prefix must not be lower case.
+func isTestName(fn string) bool {
+	if i := strings.IndexByte(fn, '.'); i >= 0 {
+		fn = fn[:i]
+	}
+	for _, prefix := range testPrefixes {
+		rest, ok := strings.CutPrefix(fn, prefix)
+		if !ok {
+			continue
+		}
+		if rest == "" {
+			return true
+		}
+		r, _ := utf8.DecodeRuneInString(rest)
+		return !unicode.IsLower(r)
+	}
+	return false
+}
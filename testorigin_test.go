@@ -0,0 +1,81 @@
+package caller
+
+import "testing"
+
+// testOriginHelper stands in for a shared test helper a few calls deep.
+func testOriginHelper() (Caller, bool) {
+	return func() (Caller, bool) { return TestOrigin() }()
+}
+
+// TestTestOrigin tests that TestOrigin finds the test through helpers and
+// subtest closures.
+func TestTestOrigin(t *testing.T) {
+	t.Parallel()
+
+	c, ok := testOriginHelper()
+	if !ok {
+		t.Fatal("TestOrigin() reported no test frame")
+	}
+	if got, want := c.Function(), "TestTestOrigin"; got != want {
+		t.Errorf("Function() = %q, want %q", got, want)
+	}
+
+	t.Run("subtest", func(t *testing.T) {
+		t.Parallel()
+		c, ok := testOriginHelper()
+		if !ok {
+			t.Fatal("TestOrigin() reported no test frame")
+		}
+		if got, want := c.Function(), "TestTestOrigin.func1"; got != want {
+			t.Errorf("Function() = %q, want %q", got, want)
+		}
+	})
+}
+
+// reportTestOrigin sends whether TestOrigin found a test frame.
+func reportTestOrigin(done chan<- bool) {
+	_, ok := TestOrigin()
+	done <- ok
+}
+
+// TestTestOrigin_NotFound tests a goroutine without any test frame.
+func TestTestOrigin_NotFound(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan bool)
+	go reportTestOrigin(done)
+	if <-done {
+		t.Error("TestOrigin() in a goroutine started outside a test should report false")
+	}
+}
+
+// TestIsTestName tests the go test naming rules.
+func TestIsTestName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		fn   string
+		want bool
+	}{
+		{"Test", true},
+		{"TestFoo", true},
+		{"Test_foo", true},
+		{"TestFoo.func1.2", true},
+		{"BenchmarkFoo", true},
+		{"FuzzFoo", true},
+		{"ExampleFoo", true},
+		{"Testify", false},
+		{"testFoo", false},
+		{"(*T).TestFoo", false},
+		{"helper", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fn, func(t *testing.T) {
+			t.Parallel()
+			if got := isTestName(tt.fn); got != tt.want {
+				t.Errorf("isTestName(%q) = %v, want %v", tt.fn, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package caller
+
+import "time"
+
+// Timestamped is implemented by callers that can report when they were
+// captured. Every Caller returned by this package except Invalid()
+// implements it; the capture time is only recorded when requested with
+// WithTimestamp.
+//
+//	c := caller.NewWith(0, caller.WithTimestamp())
+//	// ... later
+//	if ts, ok := c.(caller.Timestamped); ok && ts.Since() > time.Minute {
+//		// stale context
+//	}
+type Timestamped interface {
+	// CapturedAt returns the time of the capture, or the zero time if
+	// it was not recorded.
+	CapturedAt() time.Time
+
+	// Since returns the time elapsed since the capture, measured with
+	// the monotonic clock, or 0 if the capture time was not recorded.
+	Since() time.Duration
+}
+
+// callerInfo implements the Timestamped interface.
+var _ Timestamped = (*callerInfo)(nil)
+
+// CapturedAt returns the time of the capture, or the zero time if it was
+// not recorded. The capture time is not part of the JSON encoding and is
+// ignored by Equal.
+func (c *callerInfo) CapturedAt() time.Time {
+	if c == nil {
+		return time.Time{}
+	}
+	return c.capturedAt()
+}
+
+// Since returns the time elapsed since the capture, or 0 if the capture
+// time was not recorded.
+func (c *callerInfo) Since() time.Duration {
+	if c == nil || c.capturedAt().IsZero() {
+		return 0
+	}
+	return time.Since(c.capturedAt())
+}
@@ -0,0 +1,67 @@
+package caller
+
+import (
+	"testing"
+	"time"
+)
+
+// TestWithTimestamp tests that the capture time is recorded only when
+// requested, and that it does not affect equality.
+func TestWithTimestamp(t *testing.T) {
+	t.Parallel()
+
+	before := time.Now()
+	c, ok := NewWith(0, WithTimestamp()).(Timestamped)
+	if !ok {
+		t.Fatal("NewWith() result does not implement Timestamped")
+	}
+	if at := c.CapturedAt(); at.Before(before) || at.After(time.Now()) {
+		t.Errorf("CapturedAt() = %v, want between %v and now", at, before)
+	}
+	if c.Since() < 0 {
+		t.Errorf("Since() = %v, want non-negative", c.Since())
+	}
+
+	plain, ok := NewWith(0).(Timestamped)
+	if !ok {
+		t.Fatal("NewWith() result does not implement Timestamped")
+	}
+	if !plain.CapturedAt().IsZero() || plain.Since() != 0 {
+		t.Errorf("without WithTimestamp: CapturedAt() = %v, Since() = %v, want zero", plain.CapturedAt(), plain.Since())
+	}
+
+	var nilInfo *callerInfo
+	if !nilInfo.CapturedAt().IsZero() || nilInfo.Since() != 0 {
+		t.Error("nil caller should report a zero capture time")
+	}
+
+	a := &callerInfo{file: "a.go", line: 1, extra: &frameExtras{at: before}}
+	b := &callerInfo{file: "a.go", line: 1, extra: &frameExtras{at: time.Now().Add(time.Hour)}}
+	if !a.Equal(b) {
+		t.Error("Equal() should ignore the capture time")
+	}
+}
+
+// TestNewStack_WithTimestamp tests that every frame of a stack shares
+// the capture time.
+func TestNewStack_WithTimestamp(t *testing.T) {
+	t.Parallel()
+
+	s := NewStack(0, WithTimestamp())
+	var at time.Time
+	for i, f := range s.Frames() {
+		ts, ok := f.(Timestamped)
+		if !ok {
+			t.Fatalf("Frame(%d) does not implement Timestamped", i)
+		}
+		if i == 0 {
+			at = ts.CapturedAt()
+			if at.IsZero() {
+				t.Fatal("Frame(0).CapturedAt() is zero, want the capture time")
+			}
+		}
+		if got := ts.CapturedAt(); !got.Equal(at) {
+			t.Errorf("Frame(%d).CapturedAt() = %v, want %v", i, got, at)
+		}
+	}
+}
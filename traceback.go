@@ -0,0 +1,132 @@
+package caller
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// goexitFunc is the function at the root of every goroutine's stack,
+// which runtime tracebacks leave out.
+const goexitFunc = "runtime.goexit"
+
+// GoroutineID returns the ID of the goroutine the stack was captured on,
+// as shown in runtime tracebacks, or 0 if it is unknown, such as for a
+// Stack captured without WithGoroutineID or decoded from JSON.
+func (s *Stack) GoroutineID() uint64 {
+	if s == nil {
+		return 0
+	}
+	return s.goid
+}
+
+// Traceback renders the stack in the format of the Go runtime traceback
+// that debug.Stack returns and panics print, so that tools and people
+// used to reading Go tracebacks can consume structured captures:
+//
+//	goroutine 7 [running]:
+//	example.com/app.(*Server).handle(...)
+//		/src/app/server.go:42 +0x1d
+//	example.com/app.main(...)
+//		/src/app/main.go:12 +0x25
+//
+// Arguments are always elided as (...). The goroutine header is only
+// written if the goroutine is known, and the +0x offsets only for frames
+// captured live. The offset of a frame inlined into its caller is taken
+// from the start of the function it was compiled into. The output can be
+// parsed back with ParseGoroutines.
+func (s *Stack) Traceback() string {
+	return string(s.appendTraceback(nil))
+}
+
+// ExceptionStacktrace renders the stack for the OpenTelemetry
+// exception.stacktrace attribute, which tracing backends display and
+// parse for Go services as a runtime traceback. It returns the same text
+// as Traceback, except that the goroutine header those parsers expect is
+// always written, as goroutine 0 if the goroutine is unknown. It returns
+// the empty string for a nil stack.
+func (s *Stack) ExceptionStacktrace() string {
+	if s == nil {
+		return ""
+	}
+	return string(s.appendFrames(appendGoroutineHeader(nil, s.goid)))
+}
+
+// appendTraceback appends the stack to b in Go runtime traceback format.
+func (s *Stack) appendTraceback(b []byte) []byte {
+	if s == nil {
+		return b
+	}
+	if s.goid != 0 {
+		b = appendGoroutineHeader(b, s.goid)
+	}
+	return s.appendFrames(b)
+}
+
+// appendGoroutineHeader appends the traceback header of a running
+// goroutine to b.
+func appendGoroutineHeader(b []byte, goid uint64) []byte {
+	b = append(b, "goroutine "...)
+	b = strconv.AppendUint(b, goid, 10)
+	return append(b, " [running]:\n"...)
+}
+
+// appendFrames appends the frames of the stack to b in Go runtime
+// traceback format, without a goroutine header.
+func (s *Stack) appendFrames(b []byte) []byte {
+	if s == nil {
+		return b
+	}
+	for i, f := range s.frames {
+		if f.fn == goexitFunc {
+			continue
+		}
+		fn := f.fn
+		if fn == "" {
+			fn = "?"
+		}
+		b = append(b, fn...)
+		b = append(b, "(...)\n\t"...)
+		b = append(b, f.Location()...)
+		if i < len(s.pcs) {
+			if off, ok := frameOffset(s.pcs[i]); ok {
+				b = append(b, " +0x"...)
+				b = strconv.AppendUint(b, uint64(off), 16)
+			}
+		}
+		b = append(b, '\n')
+	}
+	return b
+}
+
+// frameOffset returns the offset of the return address of the call at
+// pc from the entry of the physical function containing it, as printed
+// in runtime tracebacks. For pc within inlined code, that is the function
+// the code was inlined into. It reports false if pc is in no function.
+func frameOffset(pc uintptr) (uintptr, bool) {
+	f := runtime.FuncForPC(pc)
+	if f == nil || f.Entry() == 0 {
+		return 0, false
+	}
+	return pc + 1 - f.Entry(), true
+}
+
+// goroutineID returns the ID of the calling goroutine, read from the
+// header of its runtime traceback, or 0 if it cannot be determined.
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	rest, ok := bytes.CutPrefix(buf[:n], []byte("goroutine "))
+	if !ok {
+		return 0
+	}
+	end := bytes.IndexByte(rest, ' ')
+	if end < 0 {
+		return 0
+	}
+	id, err := strconv.ParseUint(string(rest[:end]), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
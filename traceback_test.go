@@ -0,0 +1,105 @@
+package caller
+
+import (
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+// tracebackPattern matches a goroutine traceback as printed by the Go
+// runtime, with elided arguments.
+var tracebackPattern = regexp.MustCompile(`^goroutine [1-9]\d* \[running\]:\n(\S+\(\.\.\.\)\n\t\S+:\d+( \+0x[0-9a-f]+)?\n)+$`)
+
+// TestStack_ExceptionStacktrace tests that the rendering follows the
+// runtime traceback format and agrees with debug.Stack on shared frames.
+func TestStack_ExceptionStacktrace(t *testing.T) {
+	t.Parallel()
+
+	s, runtimeTrace := stackHelper(0, KeepAllFrames(), WithGoroutineID()), string(debug.Stack())
+	got := s.ExceptionStacktrace()
+	if !tracebackPattern.MatchString(got) {
+		t.Fatalf("ExceptionStacktrace() = %q, want runtime traceback format", got)
+	}
+	if strings.Contains(got, goexitFunc) {
+		t.Errorf("ExceptionStacktrace() = %q, want %s left out", got, goexitFunc)
+	}
+
+	// The frame of the test runner is shared by both tracebacks
+	i := strings.Index(runtimeTrace, "testing.tRunner(")
+	if i < 0 {
+		t.Fatalf("debug.Stack() = %q, want a testing.tRunner frame", runtimeTrace)
+	}
+	_, location, _ := strings.Cut(runtimeTrace[i:], "\n\t")
+	location, _, _ = strings.Cut(location, "\n")
+	if !strings.Contains(got, "testing.tRunner(...)\n\t"+location+"\n") {
+		t.Errorf("ExceptionStacktrace() = %q, want the frame at %q", got, location)
+	}
+	if goroutineID() != s.GoroutineID() || !strings.HasPrefix(runtimeTrace, strings.SplitN(got, "\n", 2)[0]) {
+		t.Errorf("ExceptionStacktrace() header does not match debug.Stack() = %q", runtimeTrace)
+	}
+}
+
+// TestStack_ExceptionStacktrace_Decoded tests rendering a stack without
+// goroutine or program counter information, which keeps a header.
+func TestStack_ExceptionStacktrace_Decoded(t *testing.T) {
+	t.Parallel()
+
+	s := &Stack{frames: []*callerInfo{
+		{file: "/src/app/main.go", line: 12, fn: "example.com/app.run", dotIdx: 15},
+		{file: "/src/app/main.go", line: 5},
+	}}
+	want := "goroutine 0 [running]:\nexample.com/app.run(...)\n\t/src/app/main.go:12\n?(...)\n\t/src/app/main.go:5\n"
+	if got := s.ExceptionStacktrace(); got != want {
+		t.Errorf("ExceptionStacktrace() = %q, want %q", got, want)
+	}
+	if got := (*Stack)(nil).ExceptionStacktrace(); got != "" {
+		t.Errorf("nil ExceptionStacktrace() = %q, want empty", got)
+	}
+}
+
+// TestStack_Traceback tests that a rendered stack parses back to the
+// same frames.
+func TestStack_Traceback(t *testing.T) {
+	t.Parallel()
+
+	s := stackHelper(0, WithGoroutineID())
+	got := s.Traceback()
+	if got != s.ExceptionStacktrace() || !tracebackPattern.MatchString(got) {
+		t.Fatalf("Traceback() = %q, want runtime traceback format", got)
+	}
+	gs, err := ParseGoroutines([]byte(got), ParseLimits{})
+	if err != nil || len(gs) != 1 {
+		t.Fatalf("ParseGoroutines(Traceback()) = %v, %v, want one goroutine", gs, err)
+	}
+	parsed := gs[0].Stack
+	if parsed.GoroutineID() != s.GoroutineID() || parsed.Len() != s.Len() {
+		t.Fatalf("parsed stack = %v, want %v", parsed.Frames(), s.Frames())
+	}
+	for i := range s.Len() {
+		if !EqualCallers(parsed.Frame(i), s.Frame(i)) {
+			t.Errorf("parsed frame %d = %v, want %v", i, parsed.Frame(i), s.Frame(i))
+		}
+	}
+	if got := (*Stack)(nil).Traceback(); got != "" {
+		t.Errorf("nil Traceback() = %q, want empty", got)
+	}
+}
+
+// TestStack_Traceback_Defaults tests that a stack captured without
+// options has no goroutine header, and an offset for every frame.
+func TestStack_Traceback_Defaults(t *testing.T) {
+	t.Parallel()
+
+	s := stackHelper(0)
+	if s.GoroutineID() != 0 {
+		t.Errorf("GoroutineID() = %d, want 0 without WithGoroutineID", s.GoroutineID())
+	}
+	got := s.Traceback()
+	if strings.HasPrefix(got, "goroutine ") {
+		t.Errorf("Traceback() = %q, want no goroutine header", got)
+	}
+	if n := strings.Count(got, " +0x"); n != s.Len() {
+		t.Errorf("Traceback() = %q has %d offsets, want %d", got, n, s.Len())
+	}
+}
@@ -0,0 +1,28 @@
+package caller
+
+import "runtime"
+
+// WarmUp resolves pcs, return addresses as filled by runtime.Callers or
+// RawStack.PCs, once in advance, so that the first capture at each of
+// those call sites after a deploy does not pay for paging in the
+// executable's symbol tables. Latency-critical services can call it at
+// startup with program counters recorded from a previous run's hot call
+// sites, or with the stacks of their own hot paths:
+//
+//	var raw caller.RawStack
+//	raw.Capture(0)
+//	go caller.WarmUp(raw.PCs())
+//
+// Program counters from another build of the executable are harmless but
+// useless. WarmUp is safe for concurrent use.
+func WarmUp(pcs []uintptr) {
+	if len(pcs) == 0 {
+		return
+	}
+	// Resolving each frame reads the function, file and line tables the
+	// runtime consults for later captures at the same call sites
+	frames := runtime.CallersFrames(pcs)
+	for more := true; more; {
+		_, more = frames.Next()
+	}
+}
@@ -0,0 +1,27 @@
+package caller
+
+import "testing"
+
+// TestWarmUp tests that WarmUp accepts captured, empty and unknown
+// program counters.
+func TestWarmUp(t *testing.T) {
+	t.Parallel()
+
+	var r RawStack
+	r.Capture(0)
+
+	tests := []struct {
+		name string
+		pcs  []uintptr
+	}{
+		{"captured", r.PCs()},
+		{"nil", nil},
+		{"unknown", []uintptr{0, 1, ^uintptr(0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			WarmUp(tt.pcs)
+		})
+	}
+}
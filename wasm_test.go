@@ -0,0 +1,30 @@
+//go:build js || wasip1
+
+package caller
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestWasmCaptures tests that captures are complete on WebAssembly
+// targets.
+func TestWasmCaptures(t *testing.T) {
+	t.Parallel()
+
+	c := Immediate()
+	_, file, line, _ := runtime.Caller(0)
+	if c.File() != file || c.Line() != line-1 || c.Function() != "TestWasmCaptures" || c.Package() != "github.com/balinomad/go-caller/v2" {
+		t.Errorf("Immediate() = %+v, want TestWasmCaptures at %s:%d", c, file, line-1)
+	}
+	if s := stackHelper(0); s.Len() == 0 || s.Frame(0).Function() != "TestWasmCaptures" {
+		t.Errorf("NewStack(0) = %v, want frames starting at TestWasmCaptures", s.Frames())
+	}
+	if pcs := callers(0); len(pcs) == 0 || NewFromPC(pcs[0]-1).Function() != "TestWasmCaptures" {
+		t.Error("NewFromPC() did not resolve a program counter of TestWasmCaptures")
+	}
+	if got := stackHelper(0).ExceptionStacktrace(); !strings.HasPrefix(got, "goroutine ") {
+		t.Errorf("ExceptionStacktrace() = %q, want a goroutine header", got)
+	}
+}
@@ -0,0 +1,183 @@
+package caller
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// wireVersion is the first byte of the wire encoding of a Stack.
+const wireVersion = 1
+
+var (
+	// ErrNoPCs is returned when encoding a Stack that holds no program
+	// counters, such as one decoded from JSON.
+	ErrNoPCs = errors.New("stack has no program counters")
+
+	// ErrMalformedWire is returned when decoding data that is not a valid
+	// wire-encoded Stack.
+	ErrMalformedWire = errors.New("malformed wire stack")
+)
+
+// WireStack is a Stack decoded from its wire encoding: the call-site
+// program counters of its frames, innermost first, the build ID of the
+// executable they belong to, and the run-time address of an anchor
+// symbol in that executable, from which the address it was loaded at
+// follows. Symbolizing the program counters, for example with addr2line,
+// requires that exact executable.
+type WireStack struct {
+	BuildID string    // Build ID of the executable, or empty if unknown
+	Anchor  uintptr   // Run-time address of runtime.Callers in the capturing process
+	PCs     []uintptr // Call-site program counters, innermost first
+}
+
+// LinkPCs returns the program counters of ws as addresses in the
+// executable file rather than in the process that captured them, given
+// linkAnchor, the address of the runtime.Callers symbol in the file as
+// listed by go tool nm. Position-independent executables are loaded at
+// a different address in every process, so only these addresses resolve
+// against the file.
+func (ws *WireStack) LinkPCs(linkAnchor uintptr) []uintptr {
+	pcs := make([]uintptr, len(ws.PCs))
+	for i, pc := range ws.PCs {
+		pcs[i] = pc - ws.Anchor + linkAnchor
+	}
+	return pcs
+}
+
+// wireAnchor returns the run-time address of runtime.Callers, which the
+// wire encoding records so that a collector can tell the load address of
+// the executable by comparing it with the address of the symbol in the
+// file.
+func wireAnchor() uintptr {
+	return reflect.ValueOf(runtime.Callers).Pointer()
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface with a
+// compact wire encoding meant for shipping stacks to a collector that
+// symbolizes them out of process. It holds the build ID recorded with
+// WithBuildID, the run-time address of an anchor symbol, and the program
+// counters of the frames, delta-encoded as varints relative to the
+// anchor and then to each other, so that tens of frames fit in a few
+// dozen bytes plus the build ID. Decode it with ParseWireStack, and
+// relocate the program counters into the executable file with
+// WireStack.LinkPCs.
+//
+// Frames the compiler inlined into their callers share the call
+// instruction of the function they were inlined into, so the encoding
+// holds one program counter per such chain of frames, from which
+// symbolizers that expand inlining, such as addr2line -i, recover all of
+// them. It returns ErrNoPCs for a Stack that was not captured live, such
+// as one decoded from JSON.
+func (s *Stack) MarshalBinary() ([]byte, error) {
+	if s.Len() > 0 && len(s.pcs) != len(s.frames) {
+		return nil, ErrNoPCs
+	}
+	var pcs []uintptr
+	if s != nil {
+		pcs = make([]uintptr, 0, len(s.pcs))
+		for i, pc := range s.pcs {
+			// The frame an inlined one was inlined into has no call
+			// instruction of its own
+			if i == 0 || !s.frames[i-1].has(flagInlined) {
+				pcs = append(pcs, pc)
+			}
+		}
+	}
+
+	id, anchor := s.BuildID(), wireAnchor()
+	b := make([]byte, 0, 2+len(id)+binary.MaxVarintLen64+2+len(pcs)*3)
+	b = append(b, wireVersion)
+	b = binary.AppendUvarint(b, uint64(len(id)))
+	b = append(b, id...)
+	b = binary.AppendUvarint(b, uint64(anchor))
+	b = binary.AppendUvarint(b, uint64(len(pcs)))
+	prev := anchor
+	for _, pc := range pcs {
+		// Wrapping subtraction yields the signed delta on any pointer
+		// size, and wrapping addition undoes it when decoding
+		b = binary.AppendVarint(b, int64(pc-prev))
+		prev = pc
+	}
+	return b, nil
+}
+
+// ParseWireStack decodes a Stack wire encoding produced by
+// Stack.MarshalBinary. It returns an error wrapping ErrMalformedWire if
+// data is not a valid encoding.
+func ParseWireStack(data []byte) (*WireStack, error) {
+	if len(data) == 0 || data[0] != wireVersion {
+		return nil, fmt.Errorf("%w: unsupported version", ErrMalformedWire)
+	}
+	r := wireReader{data: data[1:]}
+
+	idLen := r.uvarint()
+	if r.err != nil || idLen > uint64(len(r.data)) {
+		return nil, fmt.Errorf("%w: bad build ID", ErrMalformedWire)
+	}
+	ws := &WireStack{BuildID: string(r.data[:idLen])}
+	r.data = r.data[idLen:]
+	ws.Anchor = uintptr(r.uvarint())
+	if r.err != nil {
+		return nil, fmt.Errorf("%w: bad anchor", ErrMalformedWire)
+	}
+
+	// Every program counter takes at least one byte
+	n := r.uvarint()
+	if r.err != nil || n > uint64(len(r.data)) {
+		return nil, fmt.Errorf("%w: bad frame count", ErrMalformedWire)
+	}
+	ws.PCs = make([]uintptr, n)
+	prev := ws.Anchor
+	for i := range ws.PCs {
+		ws.PCs[i] = prev + uintptr(r.varint())
+		prev = ws.PCs[i]
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedWire, r.err)
+	}
+	if len(r.data) > 0 {
+		return nil, fmt.Errorf("%w: trailing data", ErrMalformedWire)
+	}
+	return ws, nil
+}
+
+// errTruncated reports a varint cut short or overflowing 64 bits.
+var errTruncated = errors.New("truncated varint")
+
+// wireReader reads varints from the wire encoding, recording the first
+// error so that callers check it once.
+type wireReader struct {
+	data []byte
+	err  error
+}
+
+// uvarint reads an unsigned varint.
+func (r *wireReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = errTruncated
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+// varint reads a signed varint.
+func (r *wireReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.err = errTruncated
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
@@ -0,0 +1,146 @@
+package caller
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// TestStack_MarshalBinary tests that the wire encoding round-trips the
+// program counters and build ID, and that they resolve to the frames.
+func TestStack_MarshalBinary(t *testing.T) {
+	t.Parallel()
+
+	s := stackHelper(0, WithBuildID("abc/def"))
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	ws, err := ParseWireStack(data)
+	if err != nil {
+		t.Fatalf("ParseWireStack() error = %v", err)
+	}
+	if ws.BuildID != "abc/def" || ws.Anchor != wireAnchor() {
+		t.Errorf("BuildID, Anchor = %q, %x, want %q, %x", ws.BuildID, ws.Anchor, "abc/def", wireAnchor())
+	}
+	if !slices.Equal(ws.PCs, s.pcs) {
+		t.Errorf("PCs = %x, want %x", ws.PCs, s.pcs)
+	}
+	for i, pc := range ws.PCs {
+		if got, want := NewFromPC(pc), s.Frame(i); !got.Equal(want) {
+			t.Errorf("NewFromPC(PCs[%d]) = %v, want %v", i, got, want)
+		}
+	}
+	if frames := len(data) - 3 - len(ws.BuildID) - binary.MaxVarintLen64; frames > 8*s.Len() {
+		t.Errorf("frames took %d bytes for %d frames, want delta-encoded", frames, s.Len())
+	}
+
+	var empty *Stack
+	data, err = empty.MarshalBinary()
+	if err != nil {
+		t.Fatalf("nil MarshalBinary() error = %v", err)
+	}
+	if ws, err := ParseWireStack(data); err != nil || len(ws.PCs) != 0 {
+		t.Errorf("ParseWireStack(nil stack) = %v, %v, want no PCs", ws, err)
+	}
+
+	var decoded Stack
+	if err := json.Unmarshal([]byte(mustMarshal(t, s)), &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if _, err := decoded.MarshalBinary(); !errors.Is(err, ErrNoPCs) {
+		t.Errorf("MarshalBinary() of decoded stack error = %v, want %v", err, ErrNoPCs)
+	}
+}
+
+// TestParseWireStack tests decoding of hand-built and malformed input.
+func TestParseWireStack(t *testing.T) {
+	t.Parallel()
+
+	// Version 1, build ID "ab", anchor 0x1000, 3 PCs: +0x10, +0x10, -0x20
+	valid := []byte{1, 2, 'a', 'b', 0x80, 0x20, 3, 0x20, 0x20, 0x3f}
+
+	ws, err := ParseWireStack(valid)
+	if err != nil {
+		t.Fatalf("ParseWireStack() error = %v", err)
+	}
+	if want := []uintptr{0x1010, 0x1020, 0x1000}; ws.BuildID != "ab" || ws.Anchor != 0x1000 || !slices.Equal(ws.PCs, want) {
+		t.Errorf("ParseWireStack() = %q %x %x, want %q %x %x", ws.BuildID, ws.Anchor, ws.PCs, "ab", 0x1000, want)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"bad version", append([]byte{2}, valid[1:]...)},
+		{"build ID too long", []byte{1, 9, 'a'}},
+		{"missing anchor", []byte{1, 0}},
+		{"missing frame count", []byte{1, 0, 0}},
+		{"frame count too large", []byte{1, 0, 0, 5, 1}},
+		{"truncated PC", valid[:len(valid)-1]},
+		{"trailing data", append(slices.Clone(valid), 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := ParseWireStack(tt.data); !errors.Is(err, ErrMalformedWire) {
+				t.Errorf("ParseWireStack(%x) error = %v, want %v", tt.data, err, ErrMalformedWire)
+			}
+		})
+	}
+}
+
+// wireCapture captures a stack starting at its caller. It is not inlined,
+// so that its caller can be.
+//
+//go:noinline
+func wireCapture() *Stack {
+	return NewStack(0)
+}
+
+// wireInlined captures a stack from a function small enough to be
+// inlined into its caller.
+func wireInlined() *Stack {
+	return wireCapture()
+}
+
+// TestStack_MarshalBinaryInlined tests that a frame the compiler inlined
+// into the next one is encoded by a single program counter that resolves
+// to the inlined frame.
+func TestStack_MarshalBinaryInlined(t *testing.T) {
+	t.Parallel()
+
+	s := wireInlined()
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	ws, err := ParseWireStack(data)
+	if err != nil {
+		t.Fatalf("ParseWireStack() error = %v", err)
+	}
+	want := s.Len()
+	if s.frames[0].has(flagInlined) {
+		want--
+	}
+	if len(ws.PCs) != want {
+		t.Errorf("len(PCs) = %d, want %d for %d frames", len(ws.PCs), want, s.Len())
+	}
+	if got := NewFromPC(ws.PCs[0]); got.Function() != "wireInlined" {
+		t.Errorf("NewFromPC(PCs[0]).Function() = %q, want %q", got.Function(), "wireInlined")
+	}
+}
+
+// TestWireStack_LinkPCs tests relocating program counters by the
+// difference between the run-time and link-time anchors.
+func TestWireStack_LinkPCs(t *testing.T) {
+	t.Parallel()
+
+	ws := &WireStack{Anchor: 0x55000010, PCs: []uintptr{0x55000100, 0x55000020}}
+	if got, want := ws.LinkPCs(0x401010), []uintptr{0x401100, 0x401020}; !slices.Equal(got, want) {
+		t.Errorf("LinkPCs() = %x, want %x", got, want)
+	}
+}